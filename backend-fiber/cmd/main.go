@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"log" // utk mnmpilkan pesan error ke terminal
 	"os" 
 	"time" 
 
 	"pdf-backend-fiber/internal/config"
 	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/janitor"
+	"pdf-backend-fiber/internal/jobs"
 	"pdf-backend-fiber/internal/routes"
+	"pdf-backend-fiber/internal/storage"
+	"pdf-backend-fiber/internal/trash"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors" 
 	"github.com/gofiber/fiber/v2/middleware/logger" //untuk log request
+	"github.com/gofiber/fiber/v2/middleware/requestid" //X-Request-ID, dicatat di audit log
 )
 
 func main() {
@@ -26,6 +32,14 @@ func main() {
 	// Load config
 	cfg := config.Load()
 
+	// `main migrate status|up|down|to|force` cuma mengurus schema lalu keluar
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.RunMigrateCommand(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Ensure upload directory exists
 	_ = os.MkdirAll(cfg.UploadDir, os.ModePerm) //kalo misal gada folder upload, bakal dibuat, kalo ud ad ya gapapa krn ad _ =
 
@@ -35,21 +49,49 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Storage file PDF (folder lokal atau S3/MinIO)
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to init storage:", err)
+	}
+
+	// Start summary worker pool (job yang belum selesai sebelum restart dilanjutkan)
+	queue := jobs.NewQueue(db, cfg, store)
+	if err := queue.Start(); err != nil {
+		log.Fatal("Failed to start summary workers:", err)
+	}
+
+	// PDF lama (sebelum ada full-text search) diekstrak teksnya di background
+	go jobs.BackfillSearchText(context.Background(), db, store)
+
+	// Start janitor (hapus session chunk upload yang ditinggal)
+	j := janitor.New(cfg)
+	j.Start()
+
+	// Start purge trash (PDF yang dihapus lebih dari TRASH_RETENTION dihapus permanen)
+	trash.New(db, cfg, store).Start()
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		BodyLimit: int(cfg.MaxFileSize),
 	})
 
 	// Middleware
-	app.Use(logger.New()) //setiap request bs tmpil ke terminal
+	app.Use(requestid.New())
+	app.Use(logger.New(logger.Config{ //setiap request bs tmpil ke terminal
+		Format: "[${time}] ${locals:requestid} ${status} - ${latency} ${method} ${path}\n",
+	}))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Content-Type",
+		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS,HEAD,PATCH",
+		AllowHeaders: "Content-Type, Authorization, X-API-Key, X-Workspace-ID, X-Request-ID, X-Admin-Token, X-Chunk-SHA256, Tus-Resumable, Upload-Length, Upload-Metadata, Upload-Offset, Upload-Checksum, Upload-Defer-Length, Range, If-None-Match, If-Range",
+		ExposeHeaders: "Location, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Tus-Checksum-Algorithm, " +
+			"Upload-Offset, Upload-Length, Upload-Expires, X-PDF-ID, X-Job-ID, X-Duplicate, " +
+			"Accept-Ranges, Content-Range, Content-Disposition, ETag, X-Request-ID",
 	}))
 
 	// Setup routes
-	routes.Setup(app, db, cfg)
+	routes.Setup(app, db, cfg, queue, j, store)
 
 	// Start server
 	log.Println("🚀 Fiber server running on :8080")
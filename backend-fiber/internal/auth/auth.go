@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cara login yang dipakai request.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// APIKeyPrefix = awalan API key, biar gampang dibedakan dari JWT (dan gampang di-grep kalau bocor).
+const APIKeyPrefix = "pdfk_"
+
+const localsKey = "principal"
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Principal = user yang sedang login, dipasang middleware di fiber.Ctx.
+type Principal struct {
+	UserID   int    `json:"user_id"`
+	Email    string `json:"email"`
+	Method   string `json:"method"`               //jwt / api_key
+	APIKeyID int    `json:"api_key_id,omitempty"` //diisi kalau login pakai API key
+}
+
+func SetPrincipal(c *fiber.Ctx, p *Principal) {
+	c.Locals(localsKey, p)
+}
+
+// FromCtx mengambil principal dari request. Nil kalau route tidak lewat middleware auth.
+func FromCtx(c *fiber.Ctx) *Principal {
+	p, _ := c.Locals(localsKey).(*Principal)
+	return p
+}
+
+func HashPassword(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// IssueToken membuat JWT HS256 untuk user (sub = user id).
+func IssueToken(secret []byte, userID int, email string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := jwt.MapClaims{
+		"sub":   strconv.Itoa(userID),
+		"email": email,
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseToken memverifikasi JWT dan mengembalikan principal-nya.
+func ParseToken(secret []byte, tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	sub, _ := claims.GetSubject()
+	userID, err := strconv.Atoi(sub)
+	if err != nil || userID <= 0 {
+		return nil, ErrInvalidToken
+	}
+	email, _ := claims["email"].(string)
+	return &Principal{UserID: userID, Email: email, Method: MethodJWT}, nil
+}
+
+// NewAPIKey membuat API key baru. Yang disimpan di DB cuma hash + prefix untuk ditampilkan,
+// plain key hanya dikembalikan sekali waktu dibuat.
+func NewAPIKey() (plain, displayPrefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	plain = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return plain, plain[:len(APIKeyPrefix)+6], HashAPIKey(plain), nil
+}
+
+// HashAPIKey = SHA-256 hex. API key sudah random 256 bit, jadi tidak perlu bcrypt (lookup jadi cepat).
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// NewSecret dipakai kalau JWT_SECRET kosong (development).
+func NewSecret() []byte {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+//login user: password bcrypt -> JWT, script pakai API key; principal dibaca handler lewat FromCtx
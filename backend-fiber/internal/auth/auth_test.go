@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseToken(t *testing.T) {
+	secret := []byte("test-secret")
+	valid, _, err := IssueToken(secret, 7, "a@b.c", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, _ := IssueToken(secret, 7, "a@b.c", -time.Minute)
+	otherSecret, _, _ := IssueToken([]byte("other-secret"), 7, "a@b.c", time.Hour)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantID  int
+		wantErr bool
+	}{
+		{"valid", valid, 7, false},
+		{"expired", expired, 0, true},
+		{"secret lain", otherSecret, 0, true},
+		{"payload diubah", tamperPayload(valid), 0, true},
+		{"alg HS512", sign(jwt.SigningMethodHS512, secret, jwt.MapClaims{"sub": "7", "exp": exp}), 0, true},
+		{"alg none", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"sub": "7", "exp": exp}), 0, true},
+		{"tanpa exp", sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "7"}), 0, true},
+		{"sub bukan angka", sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "admin", "exp": exp}), 0, true},
+		{"sub nol", sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "0", "exp": exp}), 0, true},
+		{"bukan jwt", "abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseToken(secret, tt.token)
+			if tt.wantErr {
+				if err != ErrInvalidToken {
+					t.Fatalf("ParseToken err = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseToken: %v", err)
+			}
+			if p.UserID != tt.wantID || p.Email != "a@b.c" || p.Method != MethodJWT {
+				t.Fatalf("principal = %+v", p)
+			}
+		})
+	}
+}
+
+// tamperPayload mengganti sub di payload tanpa menandatangani ulang.
+func tamperPayload(token string) string {
+	parts := strings.Split(token, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","email":"a@b.c","exp":9999999999}`))
+	return strings.Join(parts, ".")
+}
+
+func TestHashAPIKey(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  string
+	}{
+		{"pdfk_abc", "6e5eb978c86cd1ad2e02a2f09113d8a13dfd75559dec71194c6cdc2802c28fd2"},
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, tt := range tests {
+		if got := HashAPIKey(tt.plain); got != tt.want {
+			t.Errorf("HashAPIKey(%q) = %s, want %s", tt.plain, got, tt.want)
+		}
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	plain, prefix, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIKey(plain) || !strings.HasPrefix(plain, prefix) || len(prefix) != len(APIKeyPrefix)+6 {
+		t.Fatalf("plain = %q, prefix = %q", plain, prefix)
+	}
+	if hash != HashAPIKey(plain) {
+		t.Fatalf("hash = %s, want HashAPIKey(plain)", hash)
+	}
+	if other, _, _, _ := NewAPIKey(); other == plain {
+		t.Fatal("NewAPIKey returned the same key twice")
+	}
+	if IsAPIKey("eyJhbGciOiJIUzI1NiJ9.e30.x") {
+		t.Fatal("JWT detected as API key")
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv" //string ke angka, cz always env itu string, jd ngubah ke int untuk max size
+	"strings"
+	"time"
 )
 
 type Config struct { //wadah configurasi
@@ -14,10 +16,156 @@ type Config struct { //wadah configurasi
 	DBUser      string `json:"db_user"`
 	DBPassword  string `json:"-"` //pake - biar ga di convert ke json
 	DBName      string `json:"db_name"`
+
+	DBAutoMigrate bool   `json:"db_auto_migrate"` //jalankan migration yang belum ada waktu start; false = cuma dicek, pakai `migrate up`
+	DBDriver      string `json:"db_driver"`       //postgres (default) atau sqlite (satu file, tanpa server DB)
+	SQLitePath    string `json:"sqlite_path"`     //lokasi file database kalau DB_DRIVER=sqlite
+
+	SummaryWorkers int `json:"summary_workers"` //jumlah worker yang ngerjain job summary di background
+	// lease job: worker memperpanjangnya selama job jalan, lease habis = worker dianggap mati dan job diambil worker lain
+	SummaryJobLease       time.Duration `json:"summary_job_lease"`
+	SummaryJobMaxAttempts int           `json:"summary_job_max_attempts"` //job yang sudah diambil sebanyak ini (terputus terus) ditandai failed
+
+	// panggilan ke python service: timeout per percobaan + total (termasuk retry), retry pakai backoff eksponensial + jitter
+	PythonTimeout      time.Duration `json:"python_timeout"`
+	PythonTotalTimeout time.Duration `json:"python_total_timeout"`
+	// batas waktu satu dokumen lewat python (semua chunk map-reduce + reduce), 0 = tanpa batas
+	PythonSummaryTimeout time.Duration `json:"python_summary_timeout"`
+	PythonMaxRetries     int           `json:"python_max_retries"`   //0 = tidak di-retry
+	PythonRetryBackoff   time.Duration `json:"python_retry_backoff"` //jeda retry pertama, berikutnya 2x lipat
+	// circuit breaker: setelah N kegagalan berturut-turut python service dianggap mati selama cooldown
+	BreakerThreshold int           `json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `json:"breaker_cooldown"`
+
+	Summarizer      string        `json:"summarizer"`       //default backend summary: python, openai, extractive
+	SummarizerChain []string      `json:"summarizer_chain"` //provider cadangan kalau default gagal, dicoba berurutan
+	OpenAIBaseURL   string        `json:"openai_base_url"`
+	OpenAIAPIKey    string        `json:"-"`
+	OpenAIModel     string        `json:"openai_model"`
+	OpenAITimeout   time.Duration `json:"openai_timeout"` //batas waktu satu ringkasan lewat openai (semua chunk)
+
+	ChunkChars        int `json:"chunk_chars"`         //ukuran chunk map-reduce (karakter)
+	ChunkOverlapChars int `json:"chunk_overlap_chars"` //overlap antar chunk (karakter)
+
+	UploadSessionTTL time.Duration `json:"upload_session_ttl"` //session chunk upload yang tidak aktif selama ini dihapus janitor
+	JanitorInterval  time.Duration `json:"janitor_interval"`   //seberapa sering janitor jalan
+	AdminToken       string        `json:"-"`                  //kalau diisi, endpoint /admin wajib header X-Admin-Token
+
+	StorageBackend string `json:"storage_backend"` //local (UPLOAD_DIR) atau s3 (S3 / MinIO)
+	S3Endpoint     string `json:"s3_endpoint"`
+	S3AccessKey    string `json:"-"`
+	S3SecretKey    string `json:"-"`
+	S3Bucket       string `json:"s3_bucket"`
+	S3Region       string `json:"s3_region"`
+	S3Prefix       string `json:"s3_prefix"`
+	S3UseSSL       bool   `json:"s3_use_ssl"`
+
+	ShareSecret     string        `json:"-"`                  //kunci HMAC share link; kosong = random tiap start (link lama jadi tidak valid)
+	ShareLinkTTL    time.Duration `json:"share_link_ttl"`     //masa berlaku default share link
+	ShareLinkMaxTTL time.Duration `json:"share_link_max_ttl"` //batas maksimal masa berlaku yang boleh diminta
+	PublicBaseURL   string        `json:"public_base_url"`    //base URL untuk link yang dibagikan, kosong = dari request
+
+	JWTSecret string        `json:"-"`       //kunci HS256 JWT login; kosong = random tiap start (semua user harus login ulang)
+	JWTTTL    time.Duration `json:"jwt_ttl"` //masa berlaku token login
+
+	TrashRetention     time.Duration `json:"trash_retention"`      //PDF di trash lebih lama dari ini dihapus permanen
+	TrashPurgeInterval time.Duration `json:"trash_purge_interval"` //seberapa sering purge trash jalan
 }
 
 func Load() Config {
 	maxFileSize, _ := strconv.ParseInt(getEnv("MAX_FILE_SIZE", "10485760"), 10, 64) // 10MB default
+	summaryWorkers, _ := strconv.Atoi(getEnv("SUMMARY_WORKERS", "2"))
+	if summaryWorkers <= 0 {
+		summaryWorkers = 1
+	}
+	jobLease, err := time.ParseDuration(getEnv("SUMMARY_JOB_LEASE", "2m"))
+	if err != nil || jobLease < 10*time.Second {
+		jobLease = 2 * time.Minute
+	}
+	jobMaxAttempts, err := strconv.Atoi(getEnv("SUMMARY_JOB_MAX_ATTEMPTS", "3"))
+	if err != nil || jobMaxAttempts <= 0 {
+		jobMaxAttempts = 3
+	}
+	chunkChars, _ := strconv.Atoi(getEnv("SUMMARY_CHUNK_CHARS", "4000"))
+	chunkOverlap, _ := strconv.Atoi(getEnv("SUMMARY_CHUNK_OVERLAP", "400"))
+	sessionTTL, err := time.ParseDuration(getEnv("UPLOAD_SESSION_TTL", "24h"))
+	if err != nil || sessionTTL <= 0 {
+		sessionTTL = 24 * time.Hour
+	}
+	janitorInterval, err := time.ParseDuration(getEnv("JANITOR_INTERVAL", "1h"))
+	if err != nil || janitorInterval <= 0 {
+		janitorInterval = time.Hour
+	}
+	shareTTL, err := time.ParseDuration(getEnv("SHARE_LINK_TTL", "168h"))
+	if err != nil || shareTTL <= 0 {
+		shareTTL = 7 * 24 * time.Hour
+	}
+	shareMaxTTL, err := time.ParseDuration(getEnv("SHARE_LINK_MAX_TTL", "720h"))
+	if err != nil || shareMaxTTL < shareTTL {
+		shareMaxTTL = 30 * 24 * time.Hour
+		if shareMaxTTL < shareTTL {
+			shareMaxTTL = shareTTL
+		}
+	}
+
+	jwtTTL, err := time.ParseDuration(getEnv("JWT_TTL", "24h"))
+	if err != nil || jwtTTL <= 0 {
+		jwtTTL = 24 * time.Hour
+	}
+
+	trashRetention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "720h"))
+	if err != nil || trashRetention <= 0 {
+		trashRetention = 30 * 24 * time.Hour
+	}
+	trashInterval, err := time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", "1h"))
+	if err != nil || trashInterval <= 0 {
+		trashInterval = time.Hour
+	}
+
+	pythonTimeout, err := time.ParseDuration(getEnv("PYTHON_TIMEOUT", "60s"))
+	if err != nil || pythonTimeout <= 0 {
+		pythonTimeout = 60 * time.Second
+	}
+	pythonTotalTimeout, err := time.ParseDuration(getEnv("PYTHON_TOTAL_TIMEOUT", "3m"))
+	if err != nil || pythonTotalTimeout < pythonTimeout {
+		pythonTotalTimeout = 3 * pythonTimeout
+	}
+	pythonSummaryTimeout, err := time.ParseDuration(getEnv("PYTHON_SUMMARY_TIMEOUT", "0"))
+	if err != nil || pythonSummaryTimeout < 0 {
+		pythonSummaryTimeout = 0
+	}
+	pythonRetries, err := strconv.Atoi(getEnv("PYTHON_MAX_RETRIES", "2"))
+	if err != nil || pythonRetries < 0 {
+		pythonRetries = 2
+	}
+	pythonBackoff, err := time.ParseDuration(getEnv("PYTHON_RETRY_BACKOFF", "500ms"))
+	if err != nil || pythonBackoff <= 0 {
+		pythonBackoff = 500 * time.Millisecond
+	}
+	breakerThreshold, err := strconv.Atoi(getEnv("BREAKER_THRESHOLD", "5"))
+	if err != nil || breakerThreshold <= 0 {
+		breakerThreshold = 5
+	}
+	breakerCooldown, err := time.ParseDuration(getEnv("BREAKER_COOLDOWN", "30s"))
+	if err != nil || breakerCooldown <= 0 {
+		breakerCooldown = 30 * time.Second
+	}
+
+	openAITimeout, err := time.ParseDuration(getEnv("OPENAI_TIMEOUT", "3m"))
+	if err != nil || openAITimeout <= 0 {
+		openAITimeout = 3 * time.Minute
+	}
+	var summarizerChain []string
+	for _, name := range strings.Split(getEnv("SUMMARIZER_CHAIN", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			summarizerChain = append(summarizerChain, name)
+		}
+	}
+
+	dbDriver := strings.ToLower(getEnv("DB_DRIVER", "postgres"))
+	if dbDriver != "sqlite" {
+		dbDriver = "postgres"
+	}
 
 	return Config{
 		MaxFileSize: maxFileSize,
@@ -28,6 +176,56 @@ func Load() Config {
 		DBUser:      getEnv("DB_USER", "postgres"),
 		DBPassword:  getEnv("DB_PASSWORD", "postgres123"),
 		DBName:      getEnv("DB_NAME", "pdf_summarizer"),
+
+		DBAutoMigrate: getEnv("DB_AUTO_MIGRATE", "true") != "false",
+		DBDriver:      dbDriver,
+		SQLitePath:    getEnv("SQLITE_PATH", "./data/pdf_summarizer.db"),
+
+		SummaryWorkers:        summaryWorkers,
+		SummaryJobLease:       jobLease,
+		SummaryJobMaxAttempts: jobMaxAttempts,
+
+		PythonTimeout:        pythonTimeout,
+		PythonTotalTimeout:   pythonTotalTimeout,
+		PythonSummaryTimeout: pythonSummaryTimeout,
+		PythonMaxRetries:     pythonRetries,
+		PythonRetryBackoff:   pythonBackoff,
+		BreakerThreshold:     breakerThreshold,
+		BreakerCooldown:      breakerCooldown,
+
+		Summarizer:      getEnv("SUMMARIZER", "python"),
+		SummarizerChain: summarizerChain,
+		OpenAIBaseURL:   getEnv("OPENAI_BASE_URL", "http://localhost:11434/v1"), //default ollama lokal
+		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:     getEnv("OPENAI_MODEL", "llama3.1"),
+		OpenAITimeout:   openAITimeout,
+
+		ChunkChars:        chunkChars,
+		ChunkOverlapChars: chunkOverlap,
+
+		UploadSessionTTL: sessionTTL,
+		JanitorInterval:  janitorInterval,
+		AdminToken:       getEnv("ADMIN_TOKEN", ""),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "local"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", "localhost:9000"), //default minio lokal
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", "minioadmin"),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", "minioadmin"),
+		S3Bucket:       getEnv("S3_BUCKET", "pdf-uploads"),
+		S3Region:       getEnv("S3_REGION", ""),
+		S3Prefix:       getEnv("S3_PREFIX", ""),
+		S3UseSSL:       getEnv("S3_USE_SSL", "false") == "true",
+
+		ShareSecret:     getEnv("SHARE_SECRET", ""),
+		ShareLinkTTL:    shareTTL,
+		ShareLinkMaxTTL: shareMaxTTL,
+		PublicBaseURL:   getEnv("PUBLIC_BASE_URL", ""),
+
+		JWTSecret: getEnv("JWT_SECRET", ""),
+		JWTTTL:    jwtTTL,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashInterval,
 	}
 } //
 
@@ -0,0 +1,75 @@
+// Package dbtest membuka database SQLite sementara yang sudah di-migrate, untuk test yang butuh SQL asli.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/database"
+)
+
+// Config = config minimal untuk DB test (driver sqlite, file di dir).
+func Config(dir string) config.Config {
+	return config.Config{DBDriver: "sqlite", SQLitePath: filepath.Join(dir, "test.db"), DBAutoMigrate: true}
+}
+
+// Open membuat file SQLite baru di t.TempDir() dan menjalankan semua migration. Ditutup otomatis di akhir test.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	cfg := Config(t.TempDir())
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := database.NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate test db: %v", err)
+	}
+	return db
+}
+
+// User membuat user + workspace pribadinya (role owner), mengembalikan id user dan workspace.
+func User(t testing.TB, db *sql.DB, email string) (userID, workspaceID int) {
+	t.Helper()
+	if err := db.QueryRow(`INSERT INTO users (email, password_hash) VALUES ($1, 'x') RETURNING id`, email).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := db.QueryRow(`INSERT INTO workspaces (name, personal, created_by) VALUES ($1, TRUE, $2) RETURNING id`, email, userID).Scan(&workspaceID); err != nil {
+		t.Fatalf("insert workspace: %v", err)
+	}
+	Member(t, db, workspaceID, userID, "owner")
+	return userID, workspaceID
+}
+
+// Member menambahkan user ke workspace dengan role itu.
+func Member(t testing.TB, db *sql.DB, workspaceID, userID int, role string) {
+	t.Helper()
+	if _, err := db.Exec(`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`, workspaceID, userID, role); err != nil {
+		t.Fatalf("insert member: %v", err)
+	}
+}
+
+// PDF membuat baris pdf_files (file-nya tidak ada di storage) milik userID di workspace itu.
+func PDF(t testing.TB, db *sql.DB, workspaceID, userID int, name string) int {
+	t.Helper()
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO pdf_files (filename, original_filename, filepath, filesize, content_sha256, user_id, workspace_id)
+		VALUES ($1, $1, $1, 100, $2, $3, $4) RETURNING id`,
+		name, "sha-"+name, userID, workspaceID,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert pdf: %v", err)
+	}
+	return id
+}
+
+//for learn, helper test: tiap test dapat database sendiri jadi bisa jalan paralel tanpa saling ganggu
@@ -0,0 +1,460 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// migrationDir = folder migration per driver. SQLite punya schema sendiri (tanpa tsvector / plpgsql).
+func migrationDir(driver string) string {
+	if driver == "sqlite" {
+		return "migrations/sqlite"
+	}
+	return "migrations"
+}
+
+// migrationLockKey = key pg_advisory_lock supaya beberapa replica yang start bersamaan tidak migrate barengan.
+const migrationLockKey int64 = 0x7064665f6d6967 //"pdf_mig"
+
+// Migration = satu file migrations/NNNN_nama.up.sql (+ .down.sql).
+// Checksum dihitung dari isi file up; migration yang sudah diapply tidak boleh diedit, buat migration baru.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus = Migration + catatan di schema_migrations (kalau sudah pernah dijalankan).
+type MigrationStatus struct {
+	Migration
+	Applied     bool
+	Dirty       bool
+	Error       string
+	AppliedAt   *time.Time
+	ExecutionMS int64
+	Modified    bool //checksum di DB beda dengan file
+}
+
+// LoadMigrations membaca semua migration yang di-embed untuk driver itu, urut berdasarkan versi.
+func LoadMigrations(driver string) ([]Migration, error) {
+	dir := migrationDir(driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			continue
+		}
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		num, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration filename %q (format: 0001_nama.up.sql)", name)
+		}
+		body, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %04d has two names: %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no .up.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator menjalankan migration di satu koneksi yang memegang advisory lock.
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	migrations, err := LoadMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+// Latest = versi migration terakhir yang ada di binary.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+type appliedRow struct {
+	checksum    string
+	dirty       bool
+	errText     string
+	appliedAt   time.Time
+	executionMS int64
+}
+
+func (m *Migrator) ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	timestampType := "TIMESTAMPTZ"
+	if m.driver == "sqlite" {
+		timestampType = "DATETIME" //supaya driver SQLite membacanya sebagai time.Time
+	}
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE,
+			error TEXT,
+			execution_ms BIGINT NOT NULL DEFAULT 0,
+			applied_at `+timestampType+` NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func loadApplied(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}) (map[int]appliedRow, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, checksum, dirty, COALESCE(error, ''), applied_at, execution_ms FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]appliedRow{}
+	for rows.Next() {
+		var version int
+		var r appliedRow
+		if err := rows.Scan(&version, &r.checksum, &r.dirty, &r.errText, &r.appliedAt, &r.executionMS); err != nil {
+			return nil, err
+		}
+		applied[version] = r
+	}
+	return applied, rows.Err()
+}
+
+// sqliteMigrationLock menggantikan advisory lock untuk SQLite (file-nya cuma dipakai satu proses).
+var sqliteMigrationLock sync.Mutex
+
+// withLock menjalankan fn di satu koneksi yang memegang advisory lock (session-level, dilepas di akhir).
+// Replica lain yang start bersamaan menunggu di sini lalu melihat migration sudah diapply.
+// SQLite tidak punya advisory lock, cukup mutex di proses ini.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	if m.driver == "sqlite" {
+		sqliteMigrationLock.Lock()
+		defer sqliteMigrationLock.Unlock()
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.driver != "sqlite" {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	}
+
+	if err := m.ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// Status = semua migration di binary + yang tercatat di DB tapi filenya sudah tidak ada.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var out []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		out = m.statuses(applied)
+		return nil
+	})
+	return out, err
+}
+
+func (m *Migrator) statuses(applied map[int]appliedRow) []MigrationStatus {
+	known := map[int]bool{}
+	var out []MigrationStatus
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		s := MigrationStatus{Migration: mig}
+		if r, ok := applied[mig.Version]; ok {
+			appliedAt := r.appliedAt
+			s.Applied = !r.dirty
+			s.Dirty = r.dirty
+			s.Error = r.errText
+			s.AppliedAt = &appliedAt
+			s.ExecutionMS = r.executionMS
+			s.Modified = r.checksum != mig.Checksum
+		}
+		out = append(out, s)
+	}
+	for version, r := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := r.appliedAt
+		out = append(out, MigrationStatus{
+			Migration: Migration{Version: version, Name: "(file tidak ada di binary ini)", Checksum: r.checksum},
+			Applied:   !r.dirty, Dirty: r.dirty, Error: r.errText, AppliedAt: &appliedAt, ExecutionMS: r.executionMS,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+// verify menolak lanjut kalau ada migration dirty (gagal / terputus di tengah) atau file yang sudah diapply diedit.
+func verify(statuses []MigrationStatus) error {
+	for _, s := range statuses {
+		if s.Dirty {
+			msg := fmt.Sprintf("migration %04d_%s is dirty", s.Version, s.Name)
+			if s.Error != "" {
+				msg += ": " + s.Error
+			}
+			return errors.New(msg + " (perbaiki schema manual, lalu `migrate force <versi>`)")
+		}
+		if s.Applied && s.Modified {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied (checksum mismatch)", s.Version, s.Name)
+		}
+	}
+	return nil
+}
+
+// Up menjalankan semua migration yang belum diapply.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// To migrate naik / turun sampai versi target (0 = kosongkan semua).
+func (m *Migrator) To(ctx context.Context, target int) ([]Migration, error) {
+	if target < 0 || (target > 0 && m.index(target) < 0) {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := verify(m.statuses(applied)); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok || mig.Version > target {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok || mig.Version <= target {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down me-rollback n migration terakhir yang sudah diapply.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	current, err := m.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := 0
+	if i := m.index(current) - n; i >= 0 {
+		target = m.migrations[i].Version
+	}
+	return m.To(ctx, target)
+}
+
+// Force menandai versi sebagai applied (bersih) tanpa menjalankan SQL-nya, dipakai setelah memperbaiki
+// migration dirty secara manual. Migration di atas versi itu dihapus dari catatan.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	i := m.index(version)
+	if version != 0 && i < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > $1`, version); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations[:i+1] {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO schema_migrations (version, name, checksum, dirty, error) VALUES ($1, $2, $3, FALSE, NULL)
+				ON CONFLICT (version) DO UPDATE SET name = EXCLUDED.name, checksum = EXCLUDED.checksum, dirty = FALSE, error = NULL
+			`, mig.Version, mig.Name, mig.Checksum); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+// Pending = migration yang belum diapply; error kalau ada yang dirty / diedit.
+// Dipakai waktu start dengan DB_AUTO_MIGRATE=false supaya server tidak jalan di atas schema yang ketinggalan.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(statuses); err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if !s.Applied && s.Up != "" {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) current(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for _, s := range statuses {
+		if s.Applied && s.Version > current {
+			current = s.Version
+		}
+	}
+	return current, nil
+}
+
+func (m *Migrator) index(version int) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply menjalankan satu migration (up / down). Baris schema_migrations ditandai dirty dulu di luar transaksi,
+// jadi kalau SQL-nya gagal (atau proses mati di tengah) statusnya tetap dirty + pesan error, dan start berikutnya berhenti.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	body := mig.Up
+	if !up {
+		if strings.TrimSpace(mig.Down) == "" {
+			return fmt.Errorf("migration %04d_%s has no .down.sql", mig.Version, mig.Name)
+		}
+		body = mig.Down
+	}
+
+	if _, err := conn.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum, dirty, error) VALUES ($1, $2, $3, TRUE, NULL)
+		ON CONFLICT (version) DO UPDATE SET dirty = TRUE, error = NULL
+	`, mig.Version, mig.Name, mig.Checksum); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := runInTx(ctx, conn, body)
+	if err != nil {
+		_, _ = conn.ExecContext(context.Background(), `UPDATE schema_migrations SET error = $2 WHERE version = $1`, mig.Version, err.Error())
+		direction := "up"
+		if !up {
+			direction = "down"
+		}
+		return fmt.Errorf("migration %04d_%s (%s) failed: %w", mig.Version, mig.Name, direction, err)
+	}
+
+	if up {
+		_, err = conn.ExecContext(ctx, `
+			UPDATE schema_migrations SET dirty = FALSE, error = NULL, applied_at = CURRENT_TIMESTAMP, execution_ms = $2 WHERE version = $1
+		`, mig.Version, time.Since(start).Milliseconds())
+	} else {
+		_, err = conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	return err
+}
+
+// runInTx: isi file dikirim sekali jalan (lib/pq tanpa argumen = simple query, boleh banyak statement).
+func runInTx(ctx context.Context, conn *sql.Conn, body string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+//for learn, schema_migrations = buku catatan versi schema; advisory lock = "kunci" Postgres yang bukan milik tabel mana pun
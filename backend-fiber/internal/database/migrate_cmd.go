@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"pdf-backend-fiber/internal/config"
+)
+
+const migrateUsage = `usage: go run cmd/main.go migrate <command>
+
+  status       daftar migration + status (applied / pending / dirty)
+  up           jalankan semua migration yang belum diapply
+  down [n]     rollback n migration terakhir (default 1)
+  to <versi>   naik / turun sampai versi itu (0 = rollback semua)
+  force <versi> tandai versi sebagai bersih tanpa menjalankan SQL (setelah perbaiki migration dirty manual)
+  assign-legacy <email>  PDF & ringkasan dari sebelum ada login jadi milik user ini (masuk workspace pribadinya)`
+
+// RunMigrateCommand = `go run cmd/main.go migrate ...`, dipanggil dari main kalau argumen pertama "migrate".
+func RunMigrateCommand(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	versionArg := func() (int, error) {
+		if len(args) < 2 {
+			return 0, fmt.Errorf("%s: versi wajib diisi\n\n%s", args[0], migrateUsage)
+		}
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid version %q", args[1])
+		}
+		return v, nil
+	}
+	report := func(done []Migration, err error) error {
+		for _, mig := range done {
+			fmt.Printf("%s %04d_%s\n", args[0], mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("nothing to do, schema is up to date")
+		}
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT\tMS")
+		for _, s := range statuses {
+			status := "pending"
+			switch {
+			case s.Dirty:
+				status = "DIRTY"
+			case s.Applied && s.Modified:
+				status = "MODIFIED"
+			case s.Applied:
+				status = "applied"
+			}
+			appliedAt := "-"
+			if s.AppliedAt != nil && s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\t%d\n", s.Version, s.Name, status, appliedAt, s.ExecutionMS)
+			if s.Error != "" {
+				fmt.Fprintf(w, "\t  error: %s\t\t\t\n", s.Error)
+			}
+		}
+		return w.Flush()
+	case "up":
+		return report(m.Up(ctx))
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n <= 0 {
+				return fmt.Errorf("invalid count %q", args[1])
+			}
+		}
+		return report(m.Down(ctx, n))
+	case "to":
+		v, err := versionArg()
+		if err != nil {
+			return err
+		}
+		return report(m.To(ctx, v))
+	case "force":
+		v, err := versionArg()
+		if err != nil {
+			return err
+		}
+		if err := m.Force(ctx, v); err != nil {
+			return err
+		}
+		fmt.Printf("schema_migrations forced to version %04d\n", v)
+		return nil
+	case "assign-legacy":
+		if len(args) < 2 {
+			return fmt.Errorf("assign-legacy: email wajib diisi\n\n%s", migrateUsage)
+		}
+		pdfs, summaries, err := AssignLegacyOwner(ctx, db, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("assigned %d legacy PDFs and %d summaries to %s\n", pdfs, summaries, args[1])
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+}
+
+// AssignLegacyOwner memberi pemilik ke PDF dari sebelum ada login (user_id dan workspace_id masih NULL)
+// beserta ringkasannya. Sengaja langkah manual: dulu otomatis ke user pertama yang register, padahal itu bisa siapa saja.
+func AssignLegacyOwner(ctx context.Context, db *sql.DB, email string) (int64, int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var userID, workspaceID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.id, w.id FROM users u
+		JOIN workspaces w ON w.created_by = u.id AND w.personal
+		WHERE u.email = $1
+		ORDER BY w.id LIMIT 1
+	`, strings.ToLower(strings.TrimSpace(email))).Scan(&userID, &workspaceID)
+	if err == sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("user %q (dengan workspace pribadi) tidak ditemukan", email)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE summaries SET user_id = $1
+		WHERE user_id IS NULL AND pdf_id IN (SELECT id FROM pdf_files WHERE user_id IS NULL AND workspace_id IS NULL)
+	`, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	summaries, _ := res.RowsAffected()
+	res, err = tx.ExecContext(ctx, `UPDATE pdf_files SET user_id = $1, workspace_id = $2 WHERE user_id IS NULL AND workspace_id IS NULL`,
+		userID, workspaceID)
+	if err != nil {
+		return 0, 0, err
+	}
+	pdfs, _ := res.RowsAffected()
+	return pdfs, summaries, tx.Commit()
+}
+
+//for learn, subcommand migrate biar schema bisa diubah sebelum deploy, tanpa harus start server
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"pdf-backend-fiber/internal/config"
+)
+
+// openTestDB membuka file SQLite kosong (belum di-migrate) di t.TempDir().
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(config.Config{DBDriver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateDrift(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		tamper  string //SQL yang mengubah schema_migrations setelah Up, $1 = versi terakhir
+		wantErr string //"" = Up / Pending tetap jalan
+	}{
+		{"bersih", ``, ""},
+		{"checksum beda dengan file", `UPDATE schema_migrations SET checksum = 'x' WHERE version = 1`, "checksum mismatch"},
+		{"dirty dengan pesan error", `UPDATE schema_migrations SET dirty = TRUE, error = 'boom' WHERE version = $1`, "is dirty: boom"},
+		{"versi yang filenya tidak ada", `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1 + 1000, 'hilang', 'x')`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			m, err := NewMigrator(db, "sqlite")
+			if err != nil {
+				t.Fatal(err)
+			}
+			done, err := m.Up(ctx)
+			if err != nil || len(done) != len(m.migrations) {
+				t.Fatalf("first Up = %d migrations, %v", len(done), err)
+			}
+			if tt.tamper != "" {
+				if _, err := db.Exec(tt.tamper, m.Latest()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, upErr := m.Up(ctx)
+			_, pendingErr := m.Pending(ctx)
+			for _, err := range []error{upErr, pendingErr} {
+				if tt.wantErr == "" && err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			}
+			if tt.wantErr == "" {
+				return
+			}
+
+			// force ke versi terakhir = schema sudah diperbaiki manual, checksum ikut diperbarui
+			if err := m.Force(ctx, m.Latest()); err != nil {
+				t.Fatal(err)
+			}
+			if pending, err := m.Pending(ctx); err != nil || len(pending) != 0 {
+				t.Fatalf("after force: pending = %v, %v", pending, err)
+			}
+		})
+	}
+}
+
+func TestMigrateStatusModified(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m, err := NewMigrator(db, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE schema_migrations SET checksum = 'x' WHERE version = 1`); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.Modified != (s.Version == 1) {
+			t.Errorf("%04d_%s: applied=%v modified=%v", s.Version, s.Name, s.Applied, s.Modified)
+		}
+	}
+}
+
+// beberapa migrator yang start bersamaan: tiap migration cuma diapply sekali, sisanya menunggu lock.
+func TestMigrateLock(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	const n = 4
+	var wg sync.WaitGroup
+	applied := make([]int, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m, err := NewMigrator(db, "sqlite")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			done, err := m.Up(ctx)
+			applied[i], errs[i] = len(done), err
+		}(i)
+	}
+	wg.Wait()
+
+	migrations, err := LoadMigrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("migrator %d: %v", i, errs[i])
+		}
+		total += applied[i]
+	}
+	var rows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE NOT dirty`).Scan(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if total != len(migrations) || rows != len(migrations) {
+		t.Fatalf("applied %d times, %d rows, want %d", total, rows, len(migrations))
+	}
+}
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trigger_update_latest_summary ON summaries;
+DROP FUNCTION IF EXISTS update_latest_summary();
+DROP TABLE IF EXISTS summaries;
+DROP TABLE IF EXISTS pdf_files;
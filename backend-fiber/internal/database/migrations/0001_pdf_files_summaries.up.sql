@@ -0,0 +1,75 @@
+-- Tabel inti: PDF yang diupload + ringkasannya.
+-- Semua migration ditulis idempotent (IF NOT EXISTS) supaya database lama hasil autoMigrate ikut tercatat tanpa error.
+CREATE TABLE IF NOT EXISTS pdf_files (
+	id SERIAL PRIMARY KEY,
+	filename VARCHAR(255) NOT NULL,
+	original_filename VARCHAR(255) NOT NULL,
+	filepath TEXT NOT NULL,
+	filesize BIGINT NOT NULL,
+	upload_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id SERIAL PRIMARY KEY,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	summary_text TEXT NOT NULL,
+	summary_style VARCHAR(50) NOT NULL DEFAULT 'standard',
+	process_time_ms BIGINT NOT NULL,
+	language_detected VARCHAR(10),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS original_filename VARCHAR(255);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS upload_time TIMESTAMPTZ DEFAULT NOW();
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS summary_style VARCHAR(50) DEFAULT 'standard';
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS language_detected VARCHAR(10);
+
+-- kolom TIMESTAMP lama (tanpa timezone, isinya jam Jakarta) -> TIMESTAMPTZ.
+-- Cuma untuk kolom yang masih TIMESTAMP, kalau sudah TIMESTAMPTZ jangan diubah lagi (jamnya bisa bergeser)
+DO $$
+DECLARE
+	col RECORD;
+BEGIN
+	FOR col IN
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		  AND data_type = 'timestamp without time zone'
+		  AND (table_name, column_name) IN (('pdf_files', 'upload_time'), ('pdf_files', 'created_at'), ('summaries', 'created_at'))
+	LOOP
+		EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE %L',
+			col.table_name, col.column_name, col.column_name, 'Asia/Jakarta');
+	END LOOP;
+END $$;
+
+-- latest_summary = ringkasan terbaru, diisi trigger setiap ada ringkasan baru
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS latest_summary TEXT;
+DROP TRIGGER IF EXISTS trigger_sync_latest_summary_insert ON summaries;
+DROP TRIGGER IF EXISTS trigger_sync_latest_summary_update ON summaries;
+DROP TRIGGER IF EXISTS trigger_sync_latest_summary_delete ON summaries;
+DROP FUNCTION IF EXISTS sync_latest_summary();
+
+CREATE OR REPLACE FUNCTION update_latest_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text
+	WHERE id = NEW.pdf_id;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_update_latest_summary ON summaries;
+CREATE TRIGGER trigger_update_latest_summary
+AFTER INSERT ON summaries
+FOR EACH ROW
+EXECUTE FUNCTION update_latest_summary();
+
+-- metadata hasil inspeksi PDF di Go (internal/pdfinfo)
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS page_count INT;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS pdf_version VARCHAR(10);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS pdf_title TEXT;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS pdf_author TEXT;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS pdf_subject TEXT;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS pdf_created_at TIMESTAMPTZ;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS is_encrypted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS summary_chunks;
+ALTER TABLE summaries DROP COLUMN IF EXISTS chunks_count;
+ALTER TABLE summaries DROP COLUMN IF EXISTS chars_covered;
+ALTER TABLE summaries DROP COLUMN IF EXISTS total_chars;
+DROP TABLE IF EXISTS summary_job_events;
+DROP TABLE IF EXISTS summary_jobs;
@@ -0,0 +1,41 @@
+-- Antrian summarization di background + riwayat tahapan job (dibaca stream SSE)
+CREATE TABLE IF NOT EXISTS summary_jobs (
+	id SERIAL PRIMARY KEY,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	style VARCHAR(50) NOT NULL DEFAULT 'standard',
+	status VARCHAR(20) NOT NULL DEFAULT 'queued',
+	attempts INT NOT NULL DEFAULT 0,
+	error TEXT,
+	summary_id INT REFERENCES summaries(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	started_at TIMESTAMPTZ,
+	finished_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_status ON summary_jobs (status, id);
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS stage VARCHAR(50);
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS provider VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS summary_job_events (
+	id SERIAL PRIMARY KEY,
+	job_id INT NOT NULL REFERENCES summary_jobs(id) ON DELETE CASCADE,
+	stage VARCHAR(50) NOT NULL,
+	message TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_summary_job_events_job ON summary_job_events (job_id, id);
+
+-- coverage ringkasan map-reduce (dokumen panjang) + ringkasan parsial per chunk
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS chunks_count INT;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS chars_covered INT;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS total_chars INT;
+
+CREATE TABLE IF NOT EXISTS summary_chunks (
+	id SERIAL PRIMARY KEY,
+	summary_id INT NOT NULL REFERENCES summaries(id) ON DELETE CASCADE,
+	chunk_index INT NOT NULL,
+	start_offset INT NOT NULL,
+	end_offset INT NOT NULL,
+	summary_text TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_summary_chunks_summary ON summary_chunks (summary_id, chunk_index);
@@ -0,0 +1,5 @@
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS no_cache;
+ALTER TABLE summaries DROP COLUMN IF EXISTS cache_hit;
+DROP TABLE IF EXISTS summary_cache;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS allow_duplicate;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS content_sha256;
@@ -0,0 +1,29 @@
+-- dedup isi file (SHA-256; unique index-nya per workspace, dibuat di migration trash)
+-- allow_duplicate = salinan yang sengaja diupload ulang (?force=true)
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS content_sha256 CHAR(64);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS allow_duplicate BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- hasil ringkasan per isi file + style + backend/model + versi prompt
+CREATE TABLE IF NOT EXISTS summary_cache (
+	id SERIAL PRIMARY KEY,
+	content_sha256 CHAR(64) NOT NULL,
+	style VARCHAR(50) NOT NULL,
+	provider VARCHAR(50) NOT NULL,
+	model VARCHAR(100) NOT NULL DEFAULT '',
+	prompt_version VARCHAR(20) NOT NULL,
+	summary_text TEXT NOT NULL,
+	language_detected VARCHAR(10),
+	result_model VARCHAR(100),
+	chunks_count INT,
+	chars_covered INT,
+	total_chars INT,
+	hits INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_hit_at TIMESTAMPTZ,
+	UNIQUE (content_sha256, style, provider, model, prompt_version)
+);
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS cache_hit BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS no_cache BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- filepath sekarang berisi key storage (nama file), bukan path lokal. Data lama "uploads/123_a.pdf" -> "123_a.pdf"
+UPDATE pdf_files SET filepath = regexp_replace(filepath, '^.*[\\/]', '') WHERE filepath ~ '[\\/]';
@@ -0,0 +1,13 @@
+-- link publik bertanda tangan; revoked_at = revocation list
+CREATE TABLE IF NOT EXISTS share_links (
+	id SERIAL PRIMARY KEY,
+	link_id VARCHAR(32) NOT NULL UNIQUE,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	scope VARCHAR(20) NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ,
+	view_count INT NOT NULL DEFAULT 0,
+	last_viewed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_share_links_pdf ON share_links (pdf_id, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE summaries DROP COLUMN IF EXISTS user_id;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS users;
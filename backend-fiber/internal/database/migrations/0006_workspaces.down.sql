@@ -0,0 +1,3 @@
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,34 @@
+-- akses PDF per workspace, role owner / editor / viewer
+CREATE TABLE IF NOT EXISTS workspaces (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	personal BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS workspace_members (
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role VARCHAR(20) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members (user_id);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS workspace_id INT REFERENCES workspaces(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_workspace ON pdf_files (workspace_id, created_at);
+
+-- user lama (sebelum ada workspace) dapat workspace pribadi, PDF miliknya dipindah ke sana
+INSERT INTO workspaces (name, personal, created_by)
+SELECT COALESCE(NULLIF(u.name, ''), u.email), TRUE, u.id FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM workspaces w WHERE w.personal AND w.created_by = u.id);
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, created_by, 'owner' FROM workspaces WHERE personal AND created_by IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE pdf_files p SET workspace_id = w.id FROM workspaces w
+WHERE p.workspace_id IS NULL AND w.personal AND w.created_by = p.user_id;
+
+-- dedup per workspace: isi file yang sama di workspace lain tidak boleh dianggap duplikat (bocor).
+-- Unique index-nya dibuat di migration trash setelah kolom deleted_at ada.
+DROP INDEX IF EXISTS idx_pdf_files_user_content_sha256;
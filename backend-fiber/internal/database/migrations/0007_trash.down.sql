@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pdf_files_workspace_content_sha256_active;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+-- Soft delete: DELETE /pdf/:id cuma mengisi deleted_at (trash), purge permanen setelah TRASH_RETENTION
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS deleted_by INT REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_deleted_at ON pdf_files (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- PDF di trash tidak ikut dedup (upload ulang isi yang sama boleh); restore yang bentrok dijawab 409
+DROP INDEX IF EXISTS idx_pdf_files_workspace_content_sha256;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pdf_files_workspace_content_sha256_active
+ON pdf_files (workspace_id, content_sha256) WHERE NOT allow_duplicate AND deleted_at IS NULL;
@@ -0,0 +1,33 @@
+-- append-only: siapa melakukan apa ke dokumen mana, kapan.
+-- Sengaja tanpa foreign key, event harus tetap ada walaupun PDF / workspace-nya sudah dihapus.
+CREATE TABLE IF NOT EXISTS audit_events (
+	id BIGSERIAL PRIMARY KEY,
+	actor_user_id INT,
+	actor_email VARCHAR(255) NOT NULL DEFAULT '',
+	auth_method VARCHAR(20) NOT NULL DEFAULT '',
+	action VARCHAR(50) NOT NULL,
+	target_type VARCHAR(30) NOT NULL,
+	target_id VARCHAR(64) NOT NULL DEFAULT '',
+	workspace_id INT,
+	request_id VARCHAR(64) NOT NULL DEFAULT '',
+	ip VARCHAR(64) NOT NULL DEFAULT '',
+	before JSONB,
+	after JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_workspace ON audit_events (workspace_id, created_at);
+
+-- UPDATE / DELETE di audit_events ditolak di level DB, bukan cuma di aplikasi
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events;
+CREATE TRIGGER trg_audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only();
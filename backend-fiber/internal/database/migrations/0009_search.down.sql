@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_summaries_search;
+DROP INDEX IF EXISTS idx_pdf_files_search;
+ALTER TABLE summaries DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS text_language;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS extracted_text;
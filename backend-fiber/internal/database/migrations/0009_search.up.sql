@@ -0,0 +1,28 @@
+-- Full-text search (GET /search): teks hasil ekstraksi PDF disimpan waktu upload,
+-- tsvector-nya generated column dengan config sesuai bahasa (id / en) + GIN index.
+-- Ekspresi config harus sama dengan database.TSConfigExpr yang dipakai handler search.
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS extracted_text TEXT;
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS text_language VARCHAR(10);
+
+DO $$
+DECLARE
+	id_config TEXT := 'simple'; -- Postgres < 12 belum punya config "indonesian"
+BEGIN
+	IF EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'indonesian') THEN
+		id_config := 'indonesian';
+	END IF;
+	EXECUTE format($f$
+		ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', COALESCE(original_filename, filename, '')), 'A') ||
+			setweight(to_tsvector((CASE WHEN text_language IN ('id', 'ms') THEN %1$L::regconfig WHEN text_language = 'en' THEN 'english'::regconfig ELSE 'simple'::regconfig END), COALESCE(extracted_text, '')), 'C')
+		) STORED
+	$f$, id_config);
+	EXECUTE format($f$
+		ALTER TABLE summaries ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			to_tsvector((CASE WHEN language_detected IN ('id', 'ms') THEN %1$L::regconfig WHEN language_detected = 'en' THEN 'english'::regconfig ELSE 'simple'::regconfig END), COALESCE(summary_text, ''))
+		) STORED
+	$f$, id_config);
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_pdf_files_search ON pdf_files USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_summaries_search ON summaries USING GIN (search_vector);
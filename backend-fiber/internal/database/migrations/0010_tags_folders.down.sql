@@ -0,0 +1,4 @@
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
+DROP TABLE IF EXISTS pdf_tags;
+DROP TABLE IF EXISTS tags;
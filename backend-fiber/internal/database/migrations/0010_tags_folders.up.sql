@@ -0,0 +1,30 @@
+-- label per workspace, many-to-many dengan PDF
+CREATE TABLE IF NOT EXISTS tags (
+	id SERIAL PRIMARY KEY,
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	name VARCHAR(64) NOT NULL,
+	color VARCHAR(7) NOT NULL DEFAULT '',
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_workspace_name ON tags (workspace_id, LOWER(name));
+CREATE TABLE IF NOT EXISTS pdf_tags (
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (pdf_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_pdf_tags_tag ON pdf_tags (tag_id);
+
+-- folder bisa bersarang lewat parent_id, NULL = di root workspace
+CREATE TABLE IF NOT EXISTS folders (
+	id SERIAL PRIMARY KEY,
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	parent_id INT REFERENCES folders(id) ON DELETE CASCADE,
+	name VARCHAR(255) NOT NULL,
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_sibling_name ON folders (workspace_id, COALESCE(parent_id, 0), LOWER(name));
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS folder_id INT REFERENCES folders(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_folder ON pdf_files (folder_id);
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_pdf_files_list_status;
+DROP INDEX IF EXISTS idx_pdf_files_list_size;
+DROP TRIGGER IF EXISTS trigger_update_summary_status ON summary_jobs;
+DROP FUNCTION IF EXISTS update_summary_status();
+
+CREATE OR REPLACE FUNCTION update_latest_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text
+	WHERE id = NEW.pdf_id;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS summary_status;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS latest_summary_language;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS latest_summary_style;
@@ -0,0 +1,47 @@
+-- Listing GET /pdfs: style / bahasa ringkasan terakhir dan status job terakhir disimpan di pdf_files
+-- supaya bisa difilter + diurutkan tanpa JOIN. Dua-duanya diisi trigger.
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS latest_summary_style VARCHAR(50);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS latest_summary_language VARCHAR(10);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS summary_status VARCHAR(20);
+
+CREATE OR REPLACE FUNCTION update_latest_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_summary_status()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files SET summary_status = NEW.status
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summary_jobs WHERE pdf_id = NEW.pdf_id AND id > NEW.id);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_update_summary_status ON summary_jobs;
+CREATE TRIGGER trigger_update_summary_status
+AFTER INSERT OR UPDATE OF status ON summary_jobs
+FOR EACH ROW
+EXECUTE FUNCTION update_summary_status();
+
+-- isi untuk data lama
+UPDATE pdf_files p SET latest_summary_style = s.summary_style, latest_summary_language = s.language_detected
+FROM (SELECT DISTINCT ON (pdf_id) pdf_id, summary_style, language_detected FROM summaries ORDER BY pdf_id, created_at DESC, id DESC) s
+WHERE s.pdf_id = p.id AND p.latest_summary_style IS NULL;
+
+UPDATE pdf_files p SET summary_status = j.status
+FROM (SELECT DISTINCT ON (pdf_id) pdf_id, status FROM summary_jobs ORDER BY pdf_id, id DESC) j
+WHERE j.pdf_id = p.id AND p.summary_status IS NULL;
+
+UPDATE pdf_files SET summary_status = 'succeeded' WHERE summary_status IS NULL AND latest_summary IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pdf_files_list_size ON pdf_files (filesize, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_list_status ON pdf_files (summary_status, id) WHERE deleted_at IS NULL;
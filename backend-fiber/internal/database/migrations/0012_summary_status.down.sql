@@ -0,0 +1,27 @@
+DROP INDEX IF EXISTS idx_summaries_failed;
+
+DROP TRIGGER IF EXISTS trigger_update_latest_summary ON summaries;
+CREATE OR REPLACE FUNCTION update_latest_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER trigger_update_latest_summary
+AFTER INSERT ON summaries
+FOR EACH ROW
+EXECUTE FUNCTION update_latest_summary();
+
+-- tanpa kolom status, ringkasan gagal / pending kembali jadi teks placeholder
+UPDATE summaries SET summary_text = 'Ringkasan tidak tersedia - Python service sedang maintenance'
+WHERE status <> 'succeeded' AND summary_text = '';
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS attempts;
+ALTER TABLE summaries DROP COLUMN IF EXISTS error_message;
+ALTER TABLE summaries DROP COLUMN IF EXISTS error_code;
+ALTER TABLE summaries DROP COLUMN IF EXISTS status;
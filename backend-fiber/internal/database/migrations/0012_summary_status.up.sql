@@ -0,0 +1,46 @@
+-- Status ringkasan: pending (job belum selesai) / succeeded / failed + kode & pesan error + jumlah percobaan.
+-- Ringkasan gagal tidak lagi disimpan sebagai teks placeholder dan tidak pernah jadi latest_summary.
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'succeeded';
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS error_code VARCHAR(50);
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS error_message TEXT;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 1;
+
+-- teks placeholder lama = ringkasan yang sebenarnya gagal
+UPDATE summaries
+SET status = 'failed', error_code = 'provider_error', error_message = summary_text, summary_text = ''
+WHERE summary_text IN ('Ringkasan tidak tersedia - Python service sedang maintenance', 'Re-summarization failed - Python service error');
+
+UPDATE pdf_files p
+SET latest_summary = s.summary_text, latest_summary_style = s.summary_style, latest_summary_language = s.language_detected
+FROM pdf_files p2
+LEFT JOIN LATERAL (
+	SELECT summary_text, summary_style, language_detected FROM summaries
+	WHERE pdf_id = p2.id AND status = 'succeeded'
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+) s ON TRUE
+WHERE p.id = p2.id
+  AND p.latest_summary IN ('Ringkasan tidak tersedia - Python service sedang maintenance', 'Re-summarization failed - Python service error');
+
+-- latest_summary cuma dari ringkasan yang berhasil; retry ringkasan lama yang sukses tidak menimpa ringkasan yang lebih baru
+CREATE OR REPLACE FUNCTION update_latest_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summaries WHERE pdf_id = NEW.pdf_id AND status = 'succeeded' AND id > NEW.id);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_update_latest_summary ON summaries;
+CREATE TRIGGER trigger_update_latest_summary
+AFTER INSERT OR UPDATE OF status ON summaries
+FOR EACH ROW
+WHEN (NEW.status = 'succeeded')
+EXECUTE FUNCTION update_latest_summary();
+
+CREATE INDEX IF NOT EXISTS idx_summaries_failed ON summaries (pdf_id, id) WHERE status = 'failed';
@@ -0,0 +1,2 @@
+ALTER TABLE summaries DROP COLUMN IF EXISTS model;
+ALTER TABLE summaries DROP COLUMN IF EXISTS provider;
@@ -0,0 +1,4 @@
+-- Provider + model yang benar-benar menghasilkan ringkasan (bisa beda dari yang diminta kalau kena fallback chain).
+-- Ringkasan lama tidak diketahui asalnya, dibiarkan NULL.
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS provider VARCHAR(50);
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS model VARCHAR(100);
@@ -0,0 +1,4 @@
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_user_id_fkey;
+ALTER TABLE summaries ADD CONSTRAINT summaries_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE pdf_files DROP CONSTRAINT IF EXISTS pdf_files_user_id_fkey;
+ALTER TABLE pdf_files ADD CONSTRAINT pdf_files_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS worker_id;
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,4 @@
+-- Lease job: worker yang sedang jalan memperpanjang locked_until secara berkala (heartbeat).
+-- Job running yang lease-nya habis (worker / server mati) diambil lagi worker lain; job running lain dibiarkan.
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS worker_id VARCHAR(100);
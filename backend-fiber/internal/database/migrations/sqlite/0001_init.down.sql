@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS pdf_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS share_links;
+DROP TABLE IF EXISTS summary_cache;
+DROP TABLE IF EXISTS summary_chunks;
+DROP TABLE IF EXISTS summary_job_events;
+DROP TABLE IF EXISTS summary_jobs;
+DROP TABLE IF EXISTS summaries;
+DROP TABLE IF EXISTS pdf_files;
+DROP TABLE IF EXISTS folders;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,252 @@
+-- Schema SQLite (DB_DRIVER=sqlite) = hasil akhir migration Postgres 0001-0011, ditulis ulang untuk SQLite:
+-- SERIAL -> INTEGER PRIMARY KEY, TIMESTAMPTZ -> DATETIME (dibaca driver sebagai time.Time), JSONB -> TEXT,
+-- trigger plpgsql -> trigger SQLite. Full-text search (tsvector) tidak ada, GET /search cuma jalan di Postgres.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	password_hash TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (LOWER(email));
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL DEFAULT '',
+	prefix VARCHAR(20) NOT NULL,
+	key_hash CHAR(64) NOT NULL UNIQUE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at DATETIME,
+	expires_at DATETIME,
+	revoked_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS workspaces (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	personal BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS workspace_members (
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role VARCHAR(20) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members (user_id);
+
+CREATE TABLE IF NOT EXISTS folders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	parent_id INT REFERENCES folders(id) ON DELETE CASCADE,
+	name VARCHAR(255) NOT NULL,
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_sibling_name ON folders (workspace_id, COALESCE(parent_id, 0), LOWER(name));
+
+CREATE TABLE IF NOT EXISTS pdf_files (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	filename VARCHAR(255) NOT NULL,
+	original_filename VARCHAR(255) NOT NULL,
+	filepath TEXT NOT NULL,
+	filesize BIGINT NOT NULL,
+	upload_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	latest_summary TEXT,
+	latest_summary_style VARCHAR(50),
+	latest_summary_language VARCHAR(10),
+	summary_status VARCHAR(20),
+	page_count INT,
+	pdf_version VARCHAR(10),
+	pdf_title TEXT,
+	pdf_author TEXT,
+	pdf_subject TEXT,
+	pdf_created_at DATETIME,
+	is_encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+	content_sha256 CHAR(64),
+	allow_duplicate BOOLEAN NOT NULL DEFAULT FALSE,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	workspace_id INT REFERENCES workspaces(id) ON DELETE RESTRICT,
+	folder_id INT REFERENCES folders(id) ON DELETE SET NULL,
+	deleted_at DATETIME,
+	deleted_by INT REFERENCES users(id) ON DELETE SET NULL,
+	extracted_text TEXT,
+	text_language VARCHAR(10)
+);
+CREATE INDEX IF NOT EXISTS idx_pdf_files_user ON pdf_files (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_pdf_files_workspace ON pdf_files (workspace_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_pdf_files_folder ON pdf_files (folder_id);
+CREATE INDEX IF NOT EXISTS idx_pdf_files_deleted_at ON pdf_files (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pdf_files_workspace_content_sha256_active
+ON pdf_files (workspace_id, content_sha256) WHERE NOT allow_duplicate AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_list_size ON pdf_files (filesize, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pdf_files_list_status ON pdf_files (summary_status, id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	summary_text TEXT NOT NULL,
+	summary_style VARCHAR(50) NOT NULL DEFAULT 'standard',
+	process_time_ms BIGINT NOT NULL,
+	language_detected VARCHAR(10),
+	chunks_count INT,
+	chars_covered INT,
+	total_chars INT,
+	cache_hit BOOLEAN NOT NULL DEFAULT FALSE,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_summaries_pdf ON summaries (pdf_id, created_at);
+
+-- latest_summary + style / bahasa-nya, sama dengan update_latest_summary() di Postgres
+CREATE TRIGGER IF NOT EXISTS trigger_update_latest_summary
+AFTER INSERT ON summaries
+FOR EACH ROW
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id;
+END;
+
+CREATE TABLE IF NOT EXISTS summary_jobs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	style VARCHAR(50) NOT NULL DEFAULT 'standard',
+	status VARCHAR(20) NOT NULL DEFAULT 'queued',
+	attempts INT NOT NULL DEFAULT 0,
+	error TEXT,
+	summary_id INT REFERENCES summaries(id) ON DELETE SET NULL,
+	stage VARCHAR(50),
+	provider VARCHAR(50),
+	no_cache BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	started_at DATETIME,
+	finished_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_status ON summary_jobs (status, id);
+
+-- status job terakhir per PDF, sama dengan update_summary_status() di Postgres
+CREATE TRIGGER IF NOT EXISTS trigger_update_summary_status_insert
+AFTER INSERT ON summary_jobs
+FOR EACH ROW
+BEGIN
+	UPDATE pdf_files SET summary_status = NEW.status
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summary_jobs WHERE pdf_id = NEW.pdf_id AND id > NEW.id);
+END;
+CREATE TRIGGER IF NOT EXISTS trigger_update_summary_status_update
+AFTER UPDATE OF status ON summary_jobs
+FOR EACH ROW
+BEGIN
+	UPDATE pdf_files SET summary_status = NEW.status
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summary_jobs WHERE pdf_id = NEW.pdf_id AND id > NEW.id);
+END;
+
+CREATE TABLE IF NOT EXISTS summary_job_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id INT NOT NULL REFERENCES summary_jobs(id) ON DELETE CASCADE,
+	stage VARCHAR(50) NOT NULL,
+	message TEXT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_summary_job_events_job ON summary_job_events (job_id, id);
+
+CREATE TABLE IF NOT EXISTS summary_chunks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	summary_id INT NOT NULL REFERENCES summaries(id) ON DELETE CASCADE,
+	chunk_index INT NOT NULL,
+	start_offset INT NOT NULL,
+	end_offset INT NOT NULL,
+	summary_text TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_summary_chunks_summary ON summary_chunks (summary_id, chunk_index);
+
+CREATE TABLE IF NOT EXISTS summary_cache (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	content_sha256 CHAR(64) NOT NULL,
+	style VARCHAR(50) NOT NULL,
+	provider VARCHAR(50) NOT NULL,
+	model VARCHAR(100) NOT NULL DEFAULT '',
+	prompt_version VARCHAR(20) NOT NULL,
+	summary_text TEXT NOT NULL,
+	language_detected VARCHAR(10),
+	result_model VARCHAR(100),
+	chunks_count INT,
+	chars_covered INT,
+	total_chars INT,
+	hits INT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_hit_at DATETIME,
+	UNIQUE (content_sha256, style, provider, model, prompt_version)
+);
+
+CREATE TABLE IF NOT EXISTS share_links (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	link_id VARCHAR(32) NOT NULL UNIQUE,
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	scope VARCHAR(20) NOT NULL,
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME,
+	view_count INT NOT NULL DEFAULT 0,
+	last_viewed_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_share_links_pdf ON share_links (pdf_id, created_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	name VARCHAR(64) NOT NULL,
+	color VARCHAR(7) NOT NULL DEFAULT '',
+	created_by INT REFERENCES users(id) ON DELETE SET NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_workspace_name ON tags (workspace_id, LOWER(name));
+CREATE TABLE IF NOT EXISTS pdf_tags (
+	pdf_id INT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+	tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (pdf_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_pdf_tags_tag ON pdf_tags (tag_id);
+
+-- sengaja tanpa foreign key, event harus tetap ada walaupun PDF / workspace-nya sudah dihapus
+CREATE TABLE IF NOT EXISTS audit_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	actor_user_id INT,
+	actor_email VARCHAR(255) NOT NULL DEFAULT '',
+	auth_method VARCHAR(20) NOT NULL DEFAULT '',
+	action VARCHAR(50) NOT NULL,
+	target_type VARCHAR(30) NOT NULL,
+	target_id VARCHAR(64) NOT NULL DEFAULT '',
+	workspace_id INT,
+	request_id VARCHAR(64) NOT NULL DEFAULT '',
+	ip VARCHAR(64) NOT NULL DEFAULT '',
+	before TEXT,
+	after TEXT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_workspace ON audit_events (workspace_id, created_at);
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_events_no_update
+BEFORE UPDATE ON audit_events
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS trg_audit_events_no_delete
+BEFORE DELETE ON audit_events
+BEGIN
+	SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
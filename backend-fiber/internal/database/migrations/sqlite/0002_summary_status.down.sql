@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_summaries_failed;
+DROP TRIGGER IF EXISTS trigger_update_latest_summary_status;
+DROP TRIGGER IF EXISTS trigger_update_latest_summary;
+CREATE TRIGGER IF NOT EXISTS trigger_update_latest_summary
+AFTER INSERT ON summaries
+FOR EACH ROW
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id;
+END;
+
+UPDATE summaries SET summary_text = 'Ringkasan tidak tersedia - Python service sedang maintenance'
+WHERE status <> 'succeeded' AND summary_text = '';
+
+ALTER TABLE summaries DROP COLUMN attempts;
+ALTER TABLE summaries DROP COLUMN error_message;
+ALTER TABLE summaries DROP COLUMN error_code;
+ALTER TABLE summaries DROP COLUMN status;
@@ -0,0 +1,41 @@
+-- Status ringkasan (pending / succeeded / failed), sama dengan migrations/0012_summary_status.up.sql
+ALTER TABLE summaries ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'succeeded';
+ALTER TABLE summaries ADD COLUMN error_code VARCHAR(50);
+ALTER TABLE summaries ADD COLUMN error_message TEXT;
+ALTER TABLE summaries ADD COLUMN attempts INT NOT NULL DEFAULT 1;
+
+UPDATE summaries
+SET status = 'failed', error_code = 'provider_error', error_message = summary_text, summary_text = ''
+WHERE summary_text IN ('Ringkasan tidak tersedia - Python service sedang maintenance', 'Re-summarization failed - Python service error');
+
+UPDATE pdf_files
+SET latest_summary = (SELECT summary_text FROM summaries s WHERE s.pdf_id = pdf_files.id AND s.status = 'succeeded' ORDER BY created_at DESC, id DESC LIMIT 1),
+    latest_summary_style = (SELECT summary_style FROM summaries s WHERE s.pdf_id = pdf_files.id AND s.status = 'succeeded' ORDER BY created_at DESC, id DESC LIMIT 1),
+    latest_summary_language = (SELECT language_detected FROM summaries s WHERE s.pdf_id = pdf_files.id AND s.status = 'succeeded' ORDER BY created_at DESC, id DESC LIMIT 1)
+WHERE latest_summary IN ('Ringkasan tidak tersedia - Python service sedang maintenance', 'Re-summarization failed - Python service error');
+
+DROP TRIGGER IF EXISTS trigger_update_latest_summary;
+CREATE TRIGGER IF NOT EXISTS trigger_update_latest_summary
+AFTER INSERT ON summaries
+FOR EACH ROW WHEN NEW.status = 'succeeded'
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summaries WHERE pdf_id = NEW.pdf_id AND status = 'succeeded' AND id > NEW.id);
+END;
+CREATE TRIGGER IF NOT EXISTS trigger_update_latest_summary_status
+AFTER UPDATE OF status ON summaries
+FOR EACH ROW WHEN NEW.status = 'succeeded'
+BEGIN
+	UPDATE pdf_files
+	SET latest_summary = NEW.summary_text,
+	    latest_summary_style = NEW.summary_style,
+	    latest_summary_language = NEW.language_detected
+	WHERE id = NEW.pdf_id
+	  AND NOT EXISTS (SELECT 1 FROM summaries WHERE pdf_id = NEW.pdf_id AND status = 'succeeded' AND id > NEW.id);
+END;
+
+CREATE INDEX IF NOT EXISTS idx_summaries_failed ON summaries (pdf_id, id) WHERE status = 'failed';
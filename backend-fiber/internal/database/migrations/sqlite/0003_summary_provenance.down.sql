@@ -0,0 +1,2 @@
+ALTER TABLE summaries DROP COLUMN model;
+ALTER TABLE summaries DROP COLUMN provider;
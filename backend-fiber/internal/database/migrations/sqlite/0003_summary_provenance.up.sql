@@ -0,0 +1,3 @@
+-- Provider + model penghasil ringkasan, sama dengan migrations/0013_summary_provenance.up.sql
+ALTER TABLE summaries ADD COLUMN provider VARCHAR(50);
+ALTER TABLE summaries ADD COLUMN model VARCHAR(100);
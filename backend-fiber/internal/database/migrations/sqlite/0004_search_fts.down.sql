@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_summaries_fts_update;
+DROP TRIGGER IF EXISTS trg_summaries_fts_delete;
+DROP TRIGGER IF EXISTS trg_summaries_fts_insert;
+DROP TABLE IF EXISTS summaries_fts;
+DROP TRIGGER IF EXISTS trg_pdf_files_fts_update;
+DROP TRIGGER IF EXISTS trg_pdf_files_fts_delete;
+DROP TRIGGER IF EXISTS trg_pdf_files_fts_insert;
+DROP TABLE IF EXISTS pdf_files_fts;
@@ -0,0 +1,58 @@
+-- Full-text search versi SQLite (padanan search_vector di migrations/0009_search.up.sql).
+-- FTS5 external content: teksnya tetap di pdf_files / summaries, index diisi lewat trigger.
+-- unicode61 tanpa stemming, jadi "laporan" tidak cocok dengan "melaporkan" seperti di Postgres.
+CREATE VIRTUAL TABLE IF NOT EXISTS pdf_files_fts USING fts5(
+	original_filename, extracted_text,
+	content='pdf_files', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_pdf_files_fts_insert
+AFTER INSERT ON pdf_files
+BEGIN
+	INSERT INTO pdf_files_fts (rowid, original_filename, extracted_text)
+	VALUES (NEW.id, NEW.original_filename, NEW.extracted_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_pdf_files_fts_delete
+AFTER DELETE ON pdf_files
+BEGIN
+	INSERT INTO pdf_files_fts (pdf_files_fts, rowid, original_filename, extracted_text)
+	VALUES ('delete', OLD.id, OLD.original_filename, OLD.extracted_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_pdf_files_fts_update
+AFTER UPDATE OF original_filename, extracted_text ON pdf_files
+BEGIN
+	INSERT INTO pdf_files_fts (pdf_files_fts, rowid, original_filename, extracted_text)
+	VALUES ('delete', OLD.id, OLD.original_filename, OLD.extracted_text);
+	INSERT INTO pdf_files_fts (rowid, original_filename, extracted_text)
+	VALUES (NEW.id, NEW.original_filename, NEW.extracted_text);
+END;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS summaries_fts USING fts5(
+	summary_text,
+	content='summaries', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_summaries_fts_insert
+AFTER INSERT ON summaries
+BEGIN
+	INSERT INTO summaries_fts (rowid, summary_text) VALUES (NEW.id, NEW.summary_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_summaries_fts_delete
+AFTER DELETE ON summaries
+BEGIN
+	INSERT INTO summaries_fts (summaries_fts, rowid, summary_text) VALUES ('delete', OLD.id, OLD.summary_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_summaries_fts_update
+AFTER UPDATE OF summary_text ON summaries
+BEGIN
+	INSERT INTO summaries_fts (summaries_fts, rowid, summary_text) VALUES ('delete', OLD.id, OLD.summary_text);
+	INSERT INTO summaries_fts (rowid, summary_text) VALUES (NEW.id, NEW.summary_text);
+END;
+
+-- isi index untuk data yang sudah ada
+INSERT INTO pdf_files_fts (pdf_files_fts) VALUES ('rebuild');
+INSERT INTO summaries_fts (summaries_fts) VALUES ('rebuild');
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS trg_users_owner_set_null;
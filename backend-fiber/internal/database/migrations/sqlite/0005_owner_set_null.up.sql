@@ -0,0 +1,10 @@
+-- Padanan migrations/0014_owner_set_null.up.sql. SQLite tidak bisa ALTER constraint, dan rebuild tabel
+-- di dalam transaksi migration malah memicu ON DELETE CASCADE (PRAGMA foreign_keys tidak berlaku di transaksi).
+-- Jadi user_id dikosongkan trigger sebelum user dihapus; cascade-nya tidak menemukan baris lagi = efeknya SET NULL.
+CREATE TRIGGER IF NOT EXISTS trg_users_owner_set_null
+BEFORE DELETE ON users
+FOR EACH ROW
+BEGIN
+	UPDATE summaries SET user_id = NULL WHERE user_id = OLD.id;
+	UPDATE pdf_files SET user_id = NULL WHERE user_id = OLD.id;
+END;
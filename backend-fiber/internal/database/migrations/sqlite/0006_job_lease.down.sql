@@ -0,0 +1,2 @@
+ALTER TABLE summary_jobs DROP COLUMN worker_id;
+ALTER TABLE summary_jobs DROP COLUMN locked_until;
@@ -0,0 +1,3 @@
+-- Lease job, sama dengan migrations/0015_job_lease.up.sql
+ALTER TABLE summary_jobs ADD COLUMN locked_until DATETIME;
+ALTER TABLE summary_jobs ADD COLUMN worker_id VARCHAR(100);
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// IndonesianTSConfig = text search config untuk teks bahasa Indonesia.
+// Postgres >= 12 punya config "indonesian" (stemmer snowball), versi lama fallback ke "simple" (tanpa stemming).
+func IndonesianTSConfig(ctx context.Context, db *sql.DB) string {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pg_ts_config WHERE cfgname = 'indonesian')`).Scan(&exists); err != nil || !exists {
+		return "simple"
+	}
+	return "indonesian"
+}
+
+// TSConfigExpr = ekspresi regconfig dari kolom kode bahasa (language_detected: "id" / "en" / lainnya).
+// Harus sama dengan ekspresi generated column search_vector di migrations/0009_search.up.sql.
+// "ms" ikut Indonesia karena langdetect sering salah tebak Melayu untuk teks Indonesia.
+func TSConfigExpr(langColumn, idConfig string) string {
+	return fmt.Sprintf(`(CASE WHEN %[1]s IN ('id', 'ms') THEN '%[2]s'::regconfig WHEN %[1]s = 'en' THEN 'english'::regconfig ELSE 'simple'::regconfig END)`,
+		langColumn, idConfig)
+}
+
+//for learn, config bahasa full-text search: kata "laporan"/"reports" di-stem sesuai bahasa dokumennya
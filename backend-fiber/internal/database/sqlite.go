@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeFormat = format waktu yang ditulis ke kolom DATETIME, sama dengan _time_format=sqlite
+// supaya driver bisa membacanya balik jadi time.Time.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// sqliteDriverName = driver "sqlite" yang dibungkus sqliteUTCConn.
+const sqliteDriverName = "sqlite-utc"
+
+var registerSQLite sync.Once
+
+// sqliteConn = method koneksi modernc yang dipakai database/sql.
+type sqliteConn interface {
+	driver.Conn
+	driver.Pinger
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+}
+
+// sqliteUTCConn menyimpan semua parameter time.Time dalam UTC. DATETIME di SQLite cuma teks,
+// jadi "expires_at < $1" baru benar kalau semua nilainya pakai offset yang sama.
+type sqliteUTCConn struct {
+	sqliteConn
+}
+
+func (sqliteUTCConn) CheckNamedValue(nv *driver.NamedValue) error {
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := v.(time.Time); ok {
+		v = t.UTC()
+	}
+	nv.Value = v
+	return nil
+}
+
+type sqliteUTCDriver struct {
+	driver.Driver
+}
+
+func (d sqliteUTCDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := c.(sqliteConn)
+	if !ok {
+		c.Close()
+		return nil, fmt.Errorf("unexpected sqlite connection type %T", c)
+	}
+	return sqliteUTCConn{sc}, nil
+}
+
+// openSQLite membuka file SQLite (driver pure Go, tanpa cgo). WAL + busy_timeout supaya worker summary
+// dan request HTTP bisa menulis bergantian tanpa langsung gagal "database is locked".
+func openSQLite(path string) (*sql.DB, error) {
+	registerSQLite.Do(func() {
+		// query yang ditulis untuk Postgres masih banyak memakai NOW()
+		sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			return time.Now().UTC().Format(sqliteTimeFormat), nil
+		})
+		// fungsi di atas terdaftar di driver bawaan "sqlite", jadi driver itu yang dibungkus
+		base, _ := sql.Open("sqlite", "")
+		sql.Register(sqliteDriverName, sqliteUTCDriver{base.Driver()})
+		base.Close()
+	})
+
+	if dir := filepath.Dir(path); dir != "" {
+		_ = os.MkdirAll(dir, os.ModePerm)
+	}
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(10000)&_time_format=sqlite&_txlock=immediate", path)
+	db, err := sql.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(8)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// IsUniqueViolation = insert / update bentrok dengan unique index, di Postgres maupun SQLite.
+func IsUniqueViolation(err error) bool {
+	switch e := err.(type) {
+	case *pq.Error:
+		return e.Code == "23505"
+	case *sqlite.Error:
+		return e.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || e.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+//for learn, SQLite = database dalam satu file, cocok untuk kantor cabang yang tidak punya server Postgres
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/janitor"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	Config  config.Config
+	Janitor *janitor.Janitor
+}
+
+func NewAdminHandler(cfg config.Config, j *janitor.Janitor) *AdminHandler {
+	return &AdminHandler{
+		Config:  cfg,
+		Janitor: j,
+	}
+}
+
+// RequireToken = middleware untuk route /admin. Kalau ADMIN_TOKEN kosong, endpoint admin dimatikan (404),
+// jangan sampai lupa set token malah membuka akses hapus session upload ke semua orang.
+func (h *AdminHandler) RequireToken(c *fiber.Ctx) error {
+	if h.Config.AdminToken == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "Not found"})
+	}
+	token := c.Get("X-Admin-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.Config.AdminToken)) != 1 {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	return c.Next()
+}
+
+// ListUploadSessions menampilkan semua session chunk upload yang masih ada + metrik janitor.
+func (h *AdminHandler) ListUploadSessions(c *fiber.Ctx) error {
+	sessions, err := h.Janitor.Sessions()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read upload sessions"})
+	}
+
+	var totalBytes int64
+	for _, s := range sessions {
+		totalBytes += s.Bytes
+	}
+
+	return c.JSON(fiber.Map{
+		"sessions":    sessions,
+		"count":       len(sessions),
+		"total_bytes": totalBytes,
+		"ttl_seconds": int64(h.Janitor.TTL.Seconds()),
+		"janitor":     h.Janitor.Stats(),
+	})
+}
+
+// PurgeUploadSession menghapus satu session upload (chunk yang sudah masuk ikut hilang).
+func (h *AdminHandler) PurgeUploadSession(c *fiber.Ctx) error {
+	uploadID := strings.TrimSpace(c.Params("id"))
+	session, err := h.Janitor.Purge(uploadID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.Status(404).JSON(fiber.Map{"error": "Upload session not found"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "purged": session})
+}
+
+// SweepUploadSessions menjalankan janitor sekarang juga (tidak perlu nunggu interval).
+func (h *AdminHandler) SweepUploadSessions(c *fiber.Ctx) error {
+	result := h.Janitor.Sweep()
+	return c.JSON(fiber.Map{"success": true, "result": result, "janitor": h.Janitor.Stats()})
+}
+
+// JanitorMetrics = metrik kumulatif janitor sejak server jalan.
+func (h *AdminHandler) JanitorMetrics(c *fiber.Ctx) error {
+	return c.JSON(h.Janitor.Stats())
+}
+
+//for learn, endpoint admin buat ngintip & bersihin upload yang nyangkut
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/auth"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditEntry = isi event yang diisi handler; actor, request id dan IP diambil dari request.
+type auditEntry struct {
+	Action      string
+	TargetType  string
+	TargetID    interface{}
+	WorkspaceID int         //0 = tidak terkait workspace
+	Before      interface{} //nilai sebelum berubah (nil = tidak ada)
+	After       interface{} //nilai sesudah berubah (nil = tidak ada)
+	Actor       *auth.Principal
+}
+
+// requestID = X-Request-ID dari middleware requestid (dipakai ulang kalau client sudah kirim).
+func requestID(c *fiber.Ctx) string {
+	id := c.GetRespHeader(fiber.HeaderXRequestID)
+	if id == "" {
+		id = c.Get(fiber.HeaderXRequestID)
+	}
+	if len(id) > 64 { //kolom request_id VARCHAR(64), id dari client bisa sembarang panjang
+		id = id[:64]
+	}
+	return id
+}
+
+func auditJSON(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+// recordAudit menulis satu event ke audit_events. Dipanggil setelah perubahan berhasil;
+// kalau gagal cuma di-log, request user tidak ikut gagal.
+func recordAudit(c *fiber.Ctx, db *sql.DB, e auditEntry) {
+	actor := e.Actor
+	if actor == nil {
+		actor = auth.FromCtx(c)
+	}
+	var actorID interface{}
+	var email, method string
+	if actor != nil {
+		actorID, email, method = actor.UserID, actor.Email, actor.Method
+	}
+	var workspaceID interface{}
+	if e.WorkspaceID > 0 {
+		workspaceID = e.WorkspaceID
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO audit_events (actor_user_id, actor_email, auth_method, action, target_type, target_id,
+		                          workspace_id, request_id, ip, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, actorID, email, method, e.Action, e.TargetType, fmt.Sprint(e.TargetID),
+		workspaceID, requestID(c), c.IP(), auditJSON(e.Before), auditJSON(e.After))
+	if err != nil {
+		log.Printf("Failed to write audit event %s %s/%v: %v", e.Action, e.TargetType, e.TargetID, err)
+	}
+}
+
+type AuditHandler struct {
+	DB *sql.DB
+}
+
+func NewAuditHandler(db *sql.DB) *AuditHandler {
+	return &AuditHandler{DB: db}
+}
+
+// parseTimeQuery menerima RFC3339 atau tanggal saja (YYYY-MM-DD, jam Jakarta). Dipakai filter from / to.
+func parseTimeQuery(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", raw, getJakartaLocation())
+}
+
+// timeRangeQuery membaca ?from= & ?to= (nil = tidak dibatasi). to berupa tanggal saja = sampai akhir hari itu.
+func timeRangeQuery(c *fiber.Ctx) (from, to *time.Time, err error) {
+	if v := strings.TrimSpace(c.Query("from")); v != "" {
+		t, err := parseTimeQuery(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid from (RFC3339 atau YYYY-MM-DD)")
+		}
+		from = &t
+	}
+	if v := strings.TrimSpace(c.Query("to")); v != "" {
+		t, err := parseTimeQuery(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid to (RFC3339 atau YYYY-MM-DD)")
+		}
+		if len(v) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	return from, to, nil
+}
+
+// auditFilter menyusun WHERE dari query string. User cuma bisa lihat event yang dia lakukan sendiri
+// dan event di workspace tempat dia jadi owner.
+func auditFilter(c *fiber.Ctx) (string, []interface{}, error) {
+	args := []interface{}{currentUserID(c)}
+	conds := []string{`(actor_user_id = $1 OR workspace_id IN (
+		SELECT workspace_id FROM workspace_members WHERE user_id = $1 AND role = 'owner'))`}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if v := strings.TrimSpace(c.Query("action")); v != "" {
+		var actions []interface{}
+		for _, action := range strings.Split(v, ",") {
+			actions = append(actions, strings.TrimSpace(action))
+		}
+		conds = append(conds, "action IN ("+repository.Placeholders(len(args)+1, len(actions))+")")
+		args = append(args, actions...)
+	}
+	for _, key := range []string{"actor_user_id", "workspace_id"} {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid %s", key)
+			}
+			add(key+" = ?", id)
+		}
+	}
+	if v := strings.TrimSpace(c.Query("target_type")); v != "" {
+		add("target_type = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("target_id")); v != "" {
+		add("target_id = ?", v)
+	}
+	if v := strings.TrimSpace(c.Query("request_id")); v != "" {
+		add("request_id = ?", v)
+	}
+	from, to, err := timeRangeQuery(c)
+	if err != nil {
+		return "", nil, err
+	}
+	if from != nil {
+		add("created_at >= ?", *from)
+	}
+	if to != nil {
+		add("created_at < ?", *to)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+func (h *AuditHandler) queryEvents(where string, args []interface{}, limit, offset int) ([]models.AuditEvent, error) {
+	args = append(args, limit, offset)
+	rows, err := h.DB.Query(`
+		SELECT id, actor_user_id, actor_email, auth_method, action, target_type, target_id, workspace_id,
+		       request_id, ip, before, after, created_at
+		FROM audit_events`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jakartaLoc := getJakartaLocation()
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		var actorID, workspaceID sql.NullInt64
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &actorID, &e.ActorEmail, &e.AuthMethod, &e.Action, &e.TargetType, &e.TargetID, &workspaceID,
+			&e.RequestID, &e.IP, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if actorID.Valid {
+			id := int(actorID.Int64)
+			e.ActorUserID = &id
+		}
+		if workspaceID.Valid {
+			id := int(workspaceID.Int64)
+			e.WorkspaceID = &id
+		}
+		if before != nil {
+			e.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			e.After = json.RawMessage(after)
+		}
+		e.CreatedAt = e.CreatedAt.In(jakartaLoc)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ListAudit = GET /audit. Filter: action (boleh dipisah koma), actor_user_id, target_type, target_id,
+// workspace_id, request_id, from, to. Paginasi limit/offset seperti /history.
+func (h *AuditHandler) ListAudit(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	where, args, err := auditFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var total int
+	if err := h.DB.QueryRow(`SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&total); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	events, err := h.queryEvents(where, args, limit, offset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	return c.JSON(fiber.Map{"events": events, "count": len(events), "total": total, "limit": limit, "offset": offset})
+}
+
+// maxAuditExportRows = batas baris satu kali export CSV (persempit pakai from/to kalau lebih).
+const maxAuditExportRows = 50000
+
+// ExportAuditCSV = GET /audit/export, filter sama dengan /audit.
+func (h *AuditHandler) ExportAuditCSV(c *fiber.Ctx) error {
+	where, args, err := auditFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	events, err := h.queryEvents(where, args, maxAuditExportRows, 0)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"id", "created_at", "actor_user_id", "actor_email", "auth_method", "action",
+		"target_type", "target_id", "workspace_id", "request_id", "ip", "before", "after"})
+	for _, e := range events {
+		writer.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339),
+			optionalInt(e.ActorUserID),
+			e.ActorEmail,
+			e.AuthMethod,
+			e.Action,
+			e.TargetType,
+			e.TargetID,
+			optionalInt(e.WorkspaceID),
+			e.RequestID,
+			e.IP,
+			string(e.Before),
+			string(e.After),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate CSV"})
+	}
+
+	filename := fmt.Sprintf("audit_%s.csv", time.Now().Format("20060102_150405"))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(buf.Bytes())
+}
+
+func optionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+//for learn, audit log: setiap handler yang mengubah data memanggil recordAudit, GET /audit cuma baca
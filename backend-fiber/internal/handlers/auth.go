@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/auth"
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const minPasswordLength = 8
+
+type AuthHandler struct {
+	DB        *sql.DB
+	Config    config.Config
+	JWTSecret []byte
+	APIKeys   repository.APIKeyRepository
+	Users     repository.UserRepository
+}
+
+func NewAuthHandler(db *sql.DB, cfg config.Config) *AuthHandler {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		log.Println("Warning: JWT_SECRET kosong, token login tidak berlaku lagi setelah server restart")
+		secret = auth.NewSecret()
+	}
+	repos := repository.New(db, cfg.DBDriver)
+	return &AuthHandler{
+		DB:        db,
+		Config:    cfg,
+		JWTSecret: secret,
+		APIKeys:   repos.APIKeys,
+		Users:     repos.Users,
+	}
+}
+
+// currentUserID = id user yang login (dipasang RequireAuth).
+func currentUserID(c *fiber.Ctx) int {
+	if p := auth.FromCtx(c); p != nil {
+		return p.UserID
+	}
+	return 0
+}
+
+// queryTokenPaths = route yang boleh login lewat ?access_token= (GET/HEAD saja): EventSource dan link file PDF
+// di browser tidak bisa kirim header. Route lain tidak, supaya token tidak tercecer di access log / history browser.
+var queryTokenPaths = regexp.MustCompile(`^/(jobs/\d+/events|pdf/\d+/file)/?$`)
+
+// bearerToken mengambil token dari Authorization: Bearer atau X-API-Key.
+func bearerToken(c *fiber.Ctx) string {
+	if h := strings.TrimSpace(c.Get(fiber.HeaderAuthorization)); h != "" {
+		if scheme, token, ok := strings.Cut(h, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(c.Get("X-API-Key"))
+}
+
+// queryToken = ?access_token= kalau route ini mengizinkannya, selain itu "".
+func queryToken(c *fiber.Ctx) string {
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return ""
+	}
+	if !queryTokenPaths.MatchString(c.Path()) {
+		return ""
+	}
+	return strings.TrimSpace(c.Query("access_token"))
+}
+
+// RequireAuth = middleware untuk semua route selain /health, /auth/*, /share/:token dan /admin.
+// Principal (user yang login) dipasang di fiber.Ctx, dibaca handler lewat currentUserID / auth.FromCtx.
+func (h *AuthHandler) RequireAuth(c *fiber.Ctx) error {
+	if c.Method() == fiber.MethodOptions {
+		return c.Next() //preflight CORS & discovery tus tidak bawa token
+	}
+
+	// API key berlaku lama, jangan sampai tersimpan di URL (log proxy, history): tolak di route mana pun
+	if auth.IsAPIKey(strings.TrimSpace(c.Query("access_token"))) {
+		return c.Status(401).JSON(fiber.Map{"error": "API key tidak boleh dikirim lewat URL, pakai header X-API-Key", "code": "API_KEY_IN_QUERY"})
+	}
+
+	token := bearerToken(c)
+	if token == "" {
+		token = queryToken(c)
+	}
+	if token == "" {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized", "code": "AUTH_REQUIRED"})
+	}
+
+	var principal *auth.Principal
+	if auth.IsAPIKey(token) {
+		p, err := h.lookupAPIKey(c, token)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return c.Status(401).JSON(fiber.Map{"error": "Invalid API key", "code": "INVALID_API_KEY"})
+			}
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+		principal = p
+	} else {
+		p, err := auth.ParseToken(h.JWTSecret, token)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired token", "code": "INVALID_TOKEN"})
+		}
+		principal = p
+	}
+
+	auth.SetPrincipal(c, principal)
+	return c.Next()
+}
+
+// lookupAPIKey mencari key aktif berdasarkan hash-nya sekaligus mencatat last_used_at.
+func (h *AuthHandler) lookupAPIKey(c *fiber.Ctx, plain string) (*auth.Principal, error) {
+	o, err := h.APIKeys.Touch(c.UserContext(), auth.HashAPIKey(plain))
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{Method: auth.MethodAPIKey, APIKeyID: o.KeyID, UserID: o.UserID, Email: o.Email}, nil
+}
+
+type credentialsRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+func (h *AuthHandler) issueLogin(c *fiber.Ctx, status int, user models.User) error {
+	token, expiresAt, err := auth.IssueToken(h.JWTSecret, user.ID, user.Email, h.Config.JWTTTL)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to issue token"})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success":    true,
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expiresAt.In(getJakartaLocation()),
+		"user":       user,
+	})
+}
+
+// Register membuat akun baru lalu langsung login.
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
+	var req credentialsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(req.Email); err != nil || req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email tidak valid"})
+	}
+	if len(req.Password) < minPasswordLength {
+		return c.Status(400).JSON(fiber.Map{"error": "Password minimal 8 karakter"})
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create user"})
+	}
+
+	// user + workspace pribadinya dibuat sekaligus
+	user := models.User{Email: req.Email, Name: strings.TrimSpace(req.Name)}
+	workspaceID, err := h.Users.Create(c.UserContext(), &user, hash)
+	if err != nil {
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{"error": "Email sudah terdaftar"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create user"})
+	}
+	user.CreatedAt = user.CreatedAt.In(getJakartaLocation())
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditUserRegister,
+		TargetType:  models.TargetUser,
+		TargetID:    user.ID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"email": user.Email, "name": user.Name},
+		Actor:       &auth.Principal{UserID: user.ID, Email: user.Email}, //belum login, actor = user baru itu sendiri
+	})
+
+	return h.issueLogin(c, 201, user)
+}
+
+// Login memeriksa email + password dan mengembalikan JWT.
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req credentialsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+
+	user, err := h.Users.GetByEmail(c.UserContext(), strings.TrimSpace(req.Email))
+	if err != nil && err != repository.ErrNotFound {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	// pesan sama untuk email tidak ada / password salah
+	if err == repository.ErrNotFound || !auth.CheckPassword(user.PasswordHash, req.Password) {
+		return c.Status(401).JSON(fiber.Map{"error": "Email atau password salah"})
+	}
+	user.CreatedAt = user.CreatedAt.In(getJakartaLocation())
+
+	return h.issueLogin(c, 200, *user)
+}
+
+// Me = info user yang sedang login.
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	p := auth.FromCtx(c)
+
+	user, err := h.Users.Get(c.UserContext(), p.UserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(401).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	user.CreatedAt = user.CreatedAt.In(getJakartaLocation())
+
+	return c.JSON(fiber.Map{"user": user, "auth_method": p.Method})
+}
+
+// CreateAPIKey membuat API key untuk script. Plain key cuma ditampilkan sekali di respons ini.
+func (h *AuthHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req struct {
+		Name      string `json:"name"`
+		ExpiresIn string `json:"expires_in"` //opsional, durasi Go ("720h"); kosong = tidak kedaluwarsa
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid expires_in (contoh: 720h)"})
+		}
+		t := time.Now().Add(d)
+		expiresAt = &t
+	}
+
+	plain, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create API key"})
+	}
+
+	key := models.APIKey{UserID: currentUserID(c), Name: strings.TrimSpace(req.Name), Prefix: prefix, ExpiresAt: expiresAt}
+	if err := h.APIKeys.Create(c.UserContext(), &key, hash); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save API key"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:     models.AuditAPIKeyCreate,
+		TargetType: models.TargetAPIKey,
+		TargetID:   key.ID,
+		After:      fiber.Map{"name": key.Name, "prefix": key.Prefix, "expires_at": key.ExpiresAt},
+	})
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"api_key": key,
+		"key":     plain,
+		"message": "Simpan key ini sekarang, tidak akan ditampilkan lagi",
+	})
+}
+
+// ListAPIKeys menampilkan API key milik user (tanpa plain key).
+func (h *AuthHandler) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.APIKeys.ListByUser(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	return c.JSON(fiber.Map{"api_keys": keys, "count": len(keys)})
+}
+
+// RevokeAPIKey mencabut API key milik user.
+func (h *AuthHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	keyID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid API key ID"})
+	}
+
+	if err := h.APIKeys.Revoke(c.UserContext(), keyID, currentUserID(c)); err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "API key not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	recordAudit(c, h.DB, auditEntry{Action: models.AuditAPIKeyRevoke, TargetType: models.TargetAPIKey, TargetID: keyID})
+
+	return c.JSON(fiber.Map{"success": true, "id": keyID})
+}
+
+//for learn, login & API key. Semua route data PDF lewat RequireAuth dan cuma lihat data milik user sendiri
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/auth"
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/database/dbtest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequireAuth(t *testing.T) {
+	db := dbtest.Open(t)
+	userID, _ := dbtest.User(t, db, "a@b.c")
+	h := NewAuthHandler(db, config.Config{DBDriver: "sqlite", JWTSecret: "test-secret"})
+
+	jwt, _, _ := auth.IssueToken(h.JWTSecret, userID, "a@b.c", time.Hour)
+	expiredJWT, _, _ := auth.IssueToken(h.JWTSecret, userID, "a@b.c", -time.Minute)
+
+	// key aktif, dicabut, dan kedaluwarsa
+	newKey := func(revokedAt, expiresAt interface{}) string {
+		plain, prefix, hash, err := auth.NewAPIKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`INSERT INTO api_keys (user_id, name, prefix, key_hash, revoked_at, expires_at) VALUES ($1, 'test', $2, $3, $4, $5)`,
+			userID, prefix, hash, revokedAt, expiresAt); err != nil {
+			t.Fatal(err)
+		}
+		return plain
+	}
+	key := newKey(nil, time.Now().Add(time.Hour))
+	revokedKey := newKey(time.Now().Add(-time.Minute), nil)
+	expiredKey := newKey(nil, time.Now().Add(-time.Minute))
+
+	app := fiber.New()
+	app.Use(h.RequireAuth)
+	app.All("/*", func(c *fiber.Ctx) error {
+		p := auth.FromCtx(c)
+		if p == nil { //preflight
+			return c.SendString("")
+		}
+		return c.SendString(strconv.Itoa(p.UserID) + " " + p.Method)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		header   string //Authorization
+		apiKey   string //X-API-Key
+		wantCode int
+		wantBody string //status 200: "<user_id> <method>", selain itu kode error
+	}{
+		{"tanpa token", "GET", "/pdfs", "", "", 401, "AUTH_REQUIRED"},
+		{"preflight lolos", "OPTIONS", "/pdfs", "", "", 200, ""},
+		{"jwt header", "GET", "/pdfs", "Bearer " + jwt, "", 200, strconv.Itoa(userID) + " jwt"},
+		{"jwt kedaluwarsa", "GET", "/pdfs", "Bearer " + expiredJWT, "", 401, "INVALID_TOKEN"},
+		{"jwt rusak", "GET", "/pdfs", "Bearer " + jwt + "x", "", 401, "INVALID_TOKEN"},
+		{"skema bukan bearer", "GET", "/pdfs", "Basic " + jwt, "", 401, "AUTH_REQUIRED"},
+		{"api key header", "GET", "/pdfs", "", key, 200, strconv.Itoa(userID) + " api_key"},
+		{"api key lewat bearer", "POST", "/export/csv", "Bearer " + key, "", 200, strconv.Itoa(userID) + " api_key"},
+		{"api key dicabut", "GET", "/pdfs", "", revokedKey, 401, "INVALID_API_KEY"},
+		{"api key kedaluwarsa", "GET", "/pdfs", "", expiredKey, 401, "INVALID_API_KEY"},
+		{"api key tidak dikenal", "GET", "/pdfs", "", auth.APIKeyPrefix + "nope", 401, "INVALID_API_KEY"},
+
+		{"query jwt di SSE", "GET", "/jobs/12/events?access_token=" + jwt, "", "", 200, strconv.Itoa(userID) + " jwt"},
+		{"query jwt di file", "GET", "/pdf/3/file?access_token=" + jwt + "&download=true", "", "", 200, strconv.Itoa(userID) + " jwt"},
+		{"query jwt HEAD file", "HEAD", "/pdf/3/file?access_token=" + jwt, "", "", 200, ""},
+		{"query jwt di route lain diabaikan", "GET", "/pdfs?access_token=" + jwt, "", "", 401, "AUTH_REQUIRED"},
+		{"query jwt di detail job diabaikan", "GET", "/jobs/12?access_token=" + jwt, "", "", 401, "AUTH_REQUIRED"},
+		{"query jwt POST diabaikan", "POST", "/jobs/12/events?access_token=" + jwt, "", "", 401, "AUTH_REQUIRED"},
+		{"query api key di file ditolak", "GET", "/pdf/3/file?access_token=" + key, "", "", 401, "API_KEY_IN_QUERY"},
+		{"query api key di route lain ditolak", "GET", "/pdfs?access_token=" + key, "", "", 401, "API_KEY_IN_QUERY"},
+		{"query api key walau ada header", "GET", "/pdfs?access_token=" + key, "Bearer " + jwt, "", 401, "API_KEY_IN_QUERY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, tt.wantCode, body)
+			}
+			switch {
+			case tt.wantBody == "" || tt.method == "HEAD":
+			case resp.StatusCode == 200 && string(body) != tt.wantBody:
+				t.Fatalf("body = %q, want %q", body, tt.wantBody)
+			case resp.StatusCode != 200 && !strings.Contains(string(body), `"code":"`+tt.wantBody+`"`):
+				t.Fatalf("body = %s, want code %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// byteRange = satu rentang byte hasil parsing header Range (start..end inklusif).
+type byteRange struct {
+	Start int64
+	End   int64
+}
+
+// parseRange membaca header "Range: bytes=a-b" (juga "a-" dan "-n") untuk file berukuran size.
+// Cuma satu rentang yang didukung; multi-range dianggap tidak ada Range (balas 200 full file, boleh menurut RFC 9110).
+// ok=false artinya rentang tidak bisa dipenuhi (416).
+func parseRange(header string, size int64) (r *byteRange, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return nil, true
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return nil, true
+	}
+	startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+	if startStr == "" {
+		// suffix range: n byte terakhir
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 {
+			return nil, true
+		}
+		if n == 0 || size == 0 {
+			return nil, false
+		}
+		if n > size {
+			n = size
+		}
+		return &byteRange{Start: size - n, End: size - 1}, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return nil, true
+	}
+	if start >= size {
+		return nil, false
+	}
+	end := size - 1
+	if endStr != "" {
+		e, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || e < start {
+			return nil, true
+		}
+		if e < end {
+			end = e
+		}
+	}
+	return &byteRange{Start: start, End: end}, true
+}
+
+// etagMatch mengecek header If-None-Match / If-Range (bisa berisi beberapa etag, atau "*").
+func etagMatch(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser = reader terbatas yang tetap menutup file/objek aslinya setelah selesai dikirim.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// GetPDFFile mengirim file PDF asli dari storage.
+// Mendukung Range (viewer PDF di browser), ETag/If-None-Match dari content_sha256,
+// dan ?download=true untuk Content-Disposition attachment (default inline).
+func (h *PdfHandler) GetPDFFile(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	if _, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleViewer); !ok {
+		return err
+	}
+	return h.sendPDFFile(c, pdfID)
+}
+
+// sendPDFFile dipakai juga oleh share link dengan scope file (tanpa login, jadi cek pemilik ada di pemanggil).
+func (h *PdfHandler) sendPDFFile(c *fiber.Ctx, pdfID int) error {
+	var key, originalFilename string
+	var contentHash sql.NullString
+	err := h.DB.QueryRow(`
+		SELECT filepath, COALESCE(original_filename, filename), content_sha256
+		FROM pdf_files WHERE id = $1
+	`, pdfID).Scan(&key, &originalFilename, &contentHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	ctx := c.UserContext()
+	info, err := h.Storage.Stat(ctx, key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF file not found in storage"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read PDF from storage"})
+	}
+
+	// etag dari hash isi file; file lama yang belum punya hash pakai etag dari storage
+	etag := info.ETag
+	if contentHash.Valid && contentHash.String != "" {
+		etag = contentHash.String
+	}
+	etag = `"` + strings.Trim(etag, `"`) + `"`
+
+	disposition := "inline"
+	if c.QueryBool("download", false) || c.Query("disposition") == "attachment" {
+		disposition = "attachment"
+	}
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": originalFilename}))
+
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" && etagMatch(inm, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	// Range diabaikan kalau If-Range tidak cocok (file sudah berubah), kirim full file
+	var rng *byteRange
+	if rh := c.Get(fiber.HeaderRange); rh != "" {
+		if ir := c.Get(fiber.HeaderIfRange); ir == "" || etagMatch(ir, etag) {
+			var ok bool
+			rng, ok = parseRange(rh, info.Size)
+			if !ok {
+				c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", info.Size))
+				return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(fiber.Map{"error": "Range not satisfiable"})
+			}
+		}
+	}
+
+	f, err := h.Storage.Open(ctx, key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF file not found in storage"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read PDF from storage"})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	if rng == nil {
+		return c.Status(fiber.StatusOK).SendStream(f, int(info.Size))
+	}
+
+	if _, err := f.Seek(rng.Start, io.SeekStart); err != nil {
+		f.Close()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read PDF from storage"})
+	}
+	length := rng.End - rng.Start + 1
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", rng.Start, rng.End, info.Size))
+	return c.Status(fiber.StatusPartialContent).SendStream(readCloser{io.LimitReader(f, length), f}, int(length))
+}
+
+//for learn, download PDF asli. Range dipakai viewer PDF browser biar bisa loncat halaman tanpa download semua
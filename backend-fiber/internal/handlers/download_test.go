@@ -0,0 +1,50 @@
+package handlers
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	const size = 1000
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		want   *byteRange //nil = kirim full file (200)
+		ok     bool       //false = 416
+	}{
+		{"start-end", "bytes=0-99", size, &byteRange{0, 99}, true},
+		{"open end", "bytes=900-", size, &byteRange{900, 999}, true},
+		{"end lewat ukuran file dipotong", "bytes=990-5000", size, &byteRange{990, 999}, true},
+		{"spasi", " bytes= 10 - 20 ", size, &byteRange{10, 20}, true},
+
+		{"suffix", "bytes=-100", size, &byteRange{900, 999}, true},
+		{"suffix lebih besar dari file", "bytes=-5000", size, &byteRange{0, 999}, true},
+		{"suffix nol", "bytes=-0", size, nil, false},
+		{"suffix file kosong", "bytes=-10", 0, nil, false},
+
+		{"start sama dengan size", "bytes=1000-", size, nil, false},
+		{"start lewat size", "bytes=2000-2100", size, nil, false},
+		{"file kosong", "bytes=0-", 0, nil, false},
+
+		{"multi-range", "bytes=0-9,20-29", size, nil, true},
+		{"multi-range suffix", "bytes=-10, -20", size, nil, true},
+
+		{"unit lain", "items=0-9", size, nil, true},
+		{"tanpa strip", "bytes=10", size, nil, true},
+		{"end sebelum start", "bytes=50-10", size, nil, true},
+		{"bukan angka", "bytes=a-b", size, nil, true},
+		{"kosong", "", size, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRange(tt.header, tt.size)
+			if ok != tt.ok {
+				t.Fatalf("parseRange(%q, %d) ok = %v, want %v", tt.header, tt.size, ok, tt.ok)
+			}
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Fatalf("parseRange(%q, %d) = %+v, want %+v", tt.header, tt.size, got, tt.want)
+			}
+		})
+	}
+}
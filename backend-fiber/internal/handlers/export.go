@@ -2,25 +2,66 @@ package handlers
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/csv"
 	"encoding/json"
 	"regexp" //hapus markdown bold italic
+	"strconv"
 	"strings"
 	"time"
 
+	"pdf-backend-fiber/internal/models"
+
 	"github.com/gofiber/fiber/v2"
 )
 
-type ExportHandler struct{}
+type ExportHandler struct {
+	DB *sql.DB //untuk cek akses pdf_id + audit log
+}
 
-func NewExportHandler() *ExportHandler {
-	return &ExportHandler{}
+func NewExportHandler(db *sql.DB) *ExportHandler {
+	return &ExportHandler{DB: db}
 }
 
 type ExportRequest struct {
 	Summary  string `json:"summary"`
 	Filename string `json:"filename,omitempty"`
 	Title    string `json:"title,omitempty"`
+	PdfID    int    `json:"pdf_id,omitempty"` //opsional, PDF asal ringkasan (dicatat di audit log, tag-nya ikut diexport)
+}
+
+// exportTags = nama tag PDF yang diexport (kosong kalau pdf_id tidak dikirim).
+func (h *ExportHandler) exportTags(req ExportRequest) ([]string, error) {
+	if req.PdfID == 0 {
+		return []string{}, nil
+	}
+	tags, err := loadPDFTags(h.DB, []int{req.PdfID})
+	if err != nil {
+		return nil, err
+	}
+	return tagNames(tags[req.PdfID]), nil
+}
+
+// exportWorkspace mengecek akses ke pdf_id (kalau dikirim). ok == false = respons error sudah dikirim.
+func (h *ExportHandler) exportWorkspace(c *fiber.Ctx, req ExportRequest) (int, bool, error) {
+	if req.PdfID == 0 {
+		return 0, true, nil
+	}
+	return requirePDFRole(c, h.DB, req.PdfID, models.RoleViewer)
+}
+
+func (h *ExportHandler) auditExport(c *fiber.Ctx, req ExportRequest, workspaceID int, format, filename string) {
+	targetID := ""
+	if req.PdfID > 0 {
+		targetID = strconv.Itoa(req.PdfID)
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFExport,
+		TargetType:  models.TargetPDF,
+		TargetID:    targetID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"format": format, "filename": filename, "chars": len(req.Summary)},
+	})
 }
 
 // helper
@@ -48,6 +89,15 @@ func (h *ExportHandler) ExportCSV(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Summary is required"})
 	}
 
+	workspaceID, ok, err := h.exportWorkspace(c, req)
+	if !ok {
+		return err
+	}
+	tags, err := h.exportTags(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load tags"})
+	}
+
 	// Clean markdown formatting
 	cleanSummary := cleanMarkdown(req.Summary)
 
@@ -57,6 +107,9 @@ func (h *ExportHandler) ExportCSV(c *fiber.Ctx) error {
 
 	// Write header with more structured columns
 	writer.Write([]string{"No", "Type", "Content"}) //kolom csv
+	if len(tags) > 0 {
+		writer.Write([]string{"Tags", "Tags", strings.Join(tags, "; ")})
+	}
 
 	// mecah teks perbaris
 	lines := strings.Split(cleanSummary, "\n")
@@ -107,6 +160,7 @@ func (h *ExportHandler) ExportCSV(c *fiber.Ctx) error {
 
 	c.Set("Content-Type", "text/csv; charset=utf-8")
 	c.Set("Content-Disposition", "attachment; filename="+filename)
+	h.auditExport(c, req, workspaceID, "csv", filename)
 
 	return c.Send(buf.Bytes())
 }
@@ -122,6 +176,15 @@ func (h *ExportHandler) ExportJSON(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Summary is required"})
 	}
 
+	workspaceID, ok, err := h.exportWorkspace(c, req)
+	if !ok {
+		return err
+	}
+	tags, err := h.exportTags(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load tags"})
+	}
+
 	// Clean markdown formatting
 	cleanSummary := cleanMarkdown(req.Summary)
 
@@ -156,6 +219,7 @@ func (h *ExportHandler) ExportJSON(c *fiber.Ctx) error {
 	exportData := map[string]interface{}{
 		"title":       title,
 		"exported_at": time.Now().Format(time.RFC3339),
+		"tags":        tags,
 		"content": map[string]interface{}{
 			"full_text":  cleanSummary,
 			"paragraphs": paragraphs,
@@ -184,6 +248,7 @@ func (h *ExportHandler) ExportJSON(c *fiber.Ctx) error {
 
 	c.Set("Content-Type", "application/json; charset=utf-8")
 	c.Set("Content-Disposition", "attachment; filename="+filename)
+	h.auditExport(c, req, workspaceID, "json", filename)
 
 	return c.Send(jsonBytes)
 }
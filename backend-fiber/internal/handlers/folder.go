@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FolderHandler struct {
+	DB      *sql.DB //cek akses + audit log
+	Folders repository.FolderRepository
+}
+
+func NewFolderHandler(db *sql.DB, driver string) *FolderHandler {
+	return &FolderHandler{DB: db, Folders: repository.NewFolderRepository(db, driver)}
+}
+
+func folderResponseError(c *fiber.Ctx, err error, fallback string) error {
+	if err == repository.ErrDuplicate {
+		return c.Status(409).JSON(fiber.Map{"error": "Folder dengan nama ini sudah ada di lokasi yang sama"})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": fallback})
+}
+
+// folderWorkspace = workspace tempat folder berada, repository.ErrNotFound kalau folder tidak ada.
+func (h *FolderHandler) folderWorkspace(c *fiber.Ctx, folderID int) (int, error) {
+	f, err := h.Folders.Get(c.UserContext(), folderID)
+	if err != nil {
+		return 0, err
+	}
+	return f.WorkspaceID, nil
+}
+
+// sameFolder membandingkan dua folder_id, nil = root.
+func sameFolder(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// ListFolders = semua folder (flat, parent_id untuk menyusun pohonnya) + jumlah PDF aktif langsung di dalamnya.
+func (h *FolderHandler) ListFolders(c *fiber.Ctx) error {
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+	folders, err := h.Folders.ListForUser(c.UserContext(), currentUserID(c), workspaceID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	jakartaLoc := getJakartaLocation()
+	for i := range folders {
+		folders[i].CreatedAt = folders[i].CreatedAt.In(jakartaLoc)
+	}
+	return c.JSON(fiber.Map{"folders": folders, "count": len(folders)})
+}
+
+// CreateFolder (minimal editor). Dengan parent_id, folder dibuat di workspace parent-nya;
+// tanpa parent_id di root workspace_id (default workspace pribadi).
+func (h *FolderHandler) CreateFolder(c *fiber.Ctx) error {
+	var req struct {
+		WorkspaceID int    `json:"workspace_id"`
+		ParentID    int    `json:"parent_id"`
+		Name        string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+
+	var workspaceID int
+	if req.ParentID > 0 {
+		wsID, ok, err := requireItemRole(c, h.DB, "folders", req.ParentID, models.RoleEditor, "Parent folder not found")
+		if !ok {
+			return err
+		}
+		if req.WorkspaceID > 0 && req.WorkspaceID != wsID {
+			return c.Status(400).JSON(fiber.Map{"error": "Parent folder ada di workspace lain"})
+		}
+		workspaceID = wsID
+	} else {
+		wsID, status, body := resolveUploadWorkspace(h.DB, req.WorkspaceID, currentUserID(c))
+		if status != 0 {
+			return c.Status(status).JSON(body)
+		}
+		workspaceID = wsID
+	}
+
+	f := models.Folder{WorkspaceID: workspaceID, Name: req.Name}
+	if req.ParentID > 0 {
+		f.ParentID = &req.ParentID
+	}
+	if err := h.Folders.Create(c.UserContext(), &f, currentUserID(c)); err != nil {
+		return folderResponseError(c, err, "Failed to create folder")
+	}
+	f.CreatedAt = f.CreatedAt.In(getJakartaLocation())
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditFolderCreate,
+		TargetType:  models.TargetFolder,
+		TargetID:    f.ID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"name": f.Name, "parent_id": f.ParentID},
+	})
+	return c.Status(201).JSON(fiber.Map{"success": true, "folder": f})
+}
+
+// UpdateFolder mengganti nama dan/atau memindah folder (minimal editor).
+// parent_id: tidak dikirim = tetap, 0 = ke root, lainnya = folder di workspace yang sama (bukan dirinya / turunannya).
+func (h *FolderHandler) UpdateFolder(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid folder ID"})
+	}
+	workspaceID, ok, err := requireItemRole(c, h.DB, "folders", folderID, models.RoleEditor, "Folder not found")
+	if !ok {
+		return err
+	}
+	var req struct {
+		Name     *string `json:"name"`
+		ParentID *int    `json:"parent_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+
+	old, err := h.Folders.Get(c.UserContext(), folderID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	oldName, oldParent := old.Name, old.ParentID
+	name := oldName
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+		}
+	}
+	parent := oldParent
+	if req.ParentID != nil {
+		parent = nil
+		if *req.ParentID > 0 {
+			parent = req.ParentID
+		}
+	}
+
+	if parent != nil && !sameFolder(parent, oldParent) {
+		parentWS, err := h.folderWorkspace(c, *parent)
+		if err == repository.ErrNotFound || (err == nil && parentWS != workspaceID) {
+			return c.Status(400).JSON(fiber.Map{"error": "Parent folder not found in this workspace"})
+		} else if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+		// folder tidak boleh dipindah ke dalam dirinya sendiri / subfoldernya (bikin siklus)
+		cycle, err := h.Folders.IsWithin(c.UserContext(), folderID, *parent)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+		if cycle {
+			return c.Status(400).JSON(fiber.Map{"error": "Folder tidak bisa dipindah ke dalam dirinya sendiri atau subfoldernya"})
+		}
+	}
+
+	if err := h.Folders.Update(c.UserContext(), folderID, name, parent); err != nil {
+		return folderResponseError(c, err, "Update failed")
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditFolderUpdate,
+		TargetType:  models.TargetFolder,
+		TargetID:    folderID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": oldName, "parent_id": oldParent},
+		After:       fiber.Map{"name": name, "parent_id": parent},
+	})
+	return c.JSON(fiber.Map{"success": true, "folder_id": folderID, "name": name, "parent_id": parent})
+}
+
+// DeleteFolder (minimal editor). Folder harus kosong (tanpa subfolder dan PDF aktif);
+// PDF di trash yang masih menunjuk folder ini nanti di-restore ke root.
+func (h *FolderHandler) DeleteFolder(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid folder ID"})
+	}
+	workspaceID, ok, err := requireItemRole(c, h.DB, "folders", folderID, models.RoleEditor, "Folder not found")
+	if !ok {
+		return err
+	}
+
+	folder, err := h.Folders.Get(c.UserContext(), folderID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	subfolders, pdfCount, err := h.Folders.Contents(c.UserContext(), folderID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if subfolders > 0 || pdfCount > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":      "Folder masih berisi subfolder atau PDF, pindahkan dulu",
+			"subfolders": subfolders,
+			"pdf_count":  pdfCount,
+		})
+	}
+
+	if err := h.Folders.Delete(c.UserContext(), folderID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete folder"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditFolderDelete,
+		TargetType:  models.TargetFolder,
+		TargetID:    folderID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": folder.Name},
+	})
+	return c.JSON(fiber.Map{"success": true, "folder_id": folderID})
+}
+
+// SetPDFFolder memindah PDF ke folder di workspace yang sama (minimal editor). Body {"folder_id": n}, 0 / null = root.
+func (h *FolderHandler) SetPDFFolder(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	var req struct {
+		FolderID *int `json:"folder_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+
+	var folder *int
+	if req.FolderID != nil && *req.FolderID > 0 {
+		folderWS, err := h.folderWorkspace(c, *req.FolderID)
+		if err == repository.ErrNotFound || (err == nil && folderWS != workspaceID) {
+			return c.Status(400).JSON(fiber.Map{"error": "Folder not found in this PDF's workspace"})
+		} else if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+		folder = req.FolderID
+	}
+
+	oldFolder, err := h.Folders.SetPDFFolder(c.UserContext(), pdfID, folder)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Update failed"})
+	}
+	if !sameFolder(oldFolder, folder) {
+		recordAudit(c, h.DB, auditEntry{
+			Action:      models.AuditPDFFolder,
+			TargetType:  models.TargetPDF,
+			TargetID:    pdfID,
+			WorkspaceID: workspaceID,
+			Before:      fiber.Map{"folder_id": oldFolder},
+			After:       fiber.Map{"folder_id": folder},
+		})
+	}
+	return c.JSON(fiber.Map{"success": true, "pdf_id": pdfID, "folder_id": folder})
+}
+
+//for learn, folder bersarang: parent_id menunjuk folder lain, isi subfolder diambil pakai WITH RECURSIVE
@@ -4,25 +4,40 @@ import (
 	"database/sql"
 	"fmt"
 
+	"pdf-backend-fiber/internal/services"
+
 	"github.com/gofiber/fiber/v2"
 )
 
 type HealthHandler struct { // menyimpan dependecy db
-	DB *sql.DB //biar db bs dipakai banyak fungsi
+	DB          *sql.DB //biar db bs dipakai banyak fungsi
+	Summarizers *services.Registry
 }
 
-func NewHealthHandler(db *sql.DB) *HealthHandler { //untuk inisialisasi
+func NewHealthHandler(db *sql.DB, summarizers *services.Registry) *HealthHandler { //untuk inisialisasi
 	return &HealthHandler{
-		DB: db,
+		DB:          db,
+		Summarizers: summarizers,
 	}
 } //Dependency Injection
 
 func (h *HealthHandler) Health(c *fiber.Ctx) error { //method healthhandler, fiber ctx request dn response
+	status := "healthy"
+	breakers := []services.BreakerStatus{}
+	if h.Summarizers != nil {
+		breakers = h.Summarizers.Breakers()
+	}
+	for _, b := range breakers {
+		if b.State != services.BreakerClosed {
+			status = "degraded" //backend tetap jalan, tapi ringkasan lewat service ini langsung gagal dulu
+		}
+	}
 	return c.JSON(fiber.Map{
-		"status":   "healthy",
-		"service":  "fiber-backend",
-		"version":  "1.0.0",
-		"database": "connected",
+		"status":      status,
+		"service":     "fiber-backend",
+		"version":     "1.0.0",
+		"database":    "connected",
+		"ai_services": breakers,
 	})
 }
 
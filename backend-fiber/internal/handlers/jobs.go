@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"pdf-backend-fiber/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	jobEventsPollInterval = time.Second      //seberapa sering stream cek event baru di DB
+	jobEventsHeartbeat    = 15 * time.Second //komentar ": ping" biar proxy tidak menutup koneksi idle
+	jobEventsMaxDuration  = 30 * time.Minute //batas atas umur satu stream
+)
+
+type JobHandler struct {
+	DB *sql.DB
+}
+
+func NewJobHandler(db *sql.DB) *JobHandler {
+	return &JobHandler{DB: db}
+}
+
+func isTerminalJobStatus(status string) bool {
+	return status == models.JobSucceeded || status == models.JobFailed
+}
+
+// loadJob ambil 1 job dari workspace yang bisa diakses user ini + teks summary-nya (kalau sudah ada).
+func (h *JobHandler) loadJob(jobID, userID int) (*models.SummaryJob, string, error) {
+	var job models.SummaryJob
+	var stage, jobErr, summaryText sql.NullString
+	var summaryID sql.NullInt64
+	var startedAt, finishedAt sql.NullTime
+
+	err := h.DB.QueryRow(`
+		SELECT j.id, j.pdf_id, j.style, COALESCE(j.provider, ''), j.status, j.stage, j.attempts, j.error, j.summary_id,
+		       j.created_at, j.started_at, j.finished_at, s.summary_text
+		FROM summary_jobs j
+		JOIN pdf_files p ON p.id = j.pdf_id
+		LEFT JOIN summaries s ON s.id = j.summary_id
+		WHERE j.id = $1 AND p.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2)
+	`, jobID, userID).Scan(&job.ID, &job.PdfID, &job.Style, &job.Provider, &job.Status, &stage, &job.Attempts, &jobErr, &summaryID,
+		&job.CreatedAt, &startedAt, &finishedAt, &summaryText)
+	if err != nil {
+		return nil, "", err
+	}
+
+	jakartaLoc := getJakartaLocation()
+	job.Stage = stage.String
+	job.Error = jobErr.String
+	job.CreatedAt = job.CreatedAt.In(jakartaLoc)
+	if summaryID.Valid {
+		id := int(summaryID.Int64)
+		job.SummaryID = &id
+	}
+	if startedAt.Valid {
+		t := startedAt.Time.In(jakartaLoc)
+		job.StartedAt = &t
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time.In(jakartaLoc)
+		job.FinishedAt = &t
+	}
+	return &job, summaryText.String, nil
+}
+
+// loadJobEvents ambil event job dengan id > afterID, urut dari yang paling lama.
+func (h *JobHandler) loadJobEvents(jobID, afterID int) ([]models.JobEvent, error) {
+	rows, err := h.DB.Query(`
+		SELECT id, job_id, stage, COALESCE(message, ''), created_at
+		FROM summary_job_events
+		WHERE job_id = $1 AND id > $2
+		ORDER BY id
+	`, jobID, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jakartaLoc := getJakartaLocation()
+	var events []models.JobEvent
+	for rows.Next() {
+		var e models.JobEvent
+		if err := rows.Scan(&e.ID, &e.JobID, &e.Stage, &e.Message, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = e.CreatedAt.In(jakartaLoc)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetJob mengembalikan status job summary, dipakai client yang tidak pakai SSE.
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	jobID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid job ID"})
+	}
+
+	job, summaryText, err := h.loadJob(jobID, currentUserID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(404).JSON(fiber.Map{"error": "Job not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	events, err := h.loadJobEvents(jobID, 0)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get job events"})
+	}
+
+	return c.JSON(fiber.Map{
+		"job":     job,
+		"summary": summaryText,
+		"events":  events,
+	})
+}
+
+// JobEvents stream tahapan job pakai Server-Sent Events.
+// - event "stage": setiap tahapan baru (chunks_assembled, pdf_validated, sent_to_summarizer, summary_stored)
+// - event "done": job selesai (succeeded/failed) beserta summary-nya, lalu stream ditutup
+// Header Last-Event-ID dihormati, jadi EventSource yang reconnect tidak dapat event dobel.
+func (h *JobHandler) JobEvents(c *fiber.Ctx) error {
+	jobID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid job ID"})
+	}
+
+	userID := currentUserID(c)
+	if _, _, err := h.loadJob(jobID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(404).JSON(fiber.Map{"error": "Job not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	lastEventID, _ := strconv.Atoi(c.Get("Last-Event-ID"))
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") //nginx jangan buffer stream
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		deadline := time.Now().Add(jobEventsMaxDuration)
+		lastWrite := time.Now()
+
+		for time.Now().Before(deadline) {
+			events, err := h.loadJobEvents(jobID, lastEventID)
+			if err != nil {
+				writeSSE(w, 0, "error", fiber.Map{"error": "Failed to get job events"})
+				_ = w.Flush()
+				return
+			}
+			for _, e := range events {
+				writeSSE(w, e.ID, "stage", e)
+				lastEventID = e.ID
+			}
+
+			job, summaryText, err := h.loadJob(jobID, userID)
+			if err != nil {
+				writeSSE(w, 0, "error", fiber.Map{"error": "Job not found"})
+				_ = w.Flush()
+				return
+			}
+			if isTerminalJobStatus(job.Status) {
+				writeSSE(w, 0, "done", fiber.Map{"job": job, "summary": summaryText})
+				_ = w.Flush()
+				return
+			}
+
+			if len(events) == 0 && time.Since(lastWrite) >= jobEventsHeartbeat {
+				_, _ = w.WriteString(": ping\n\n")
+			}
+			if len(events) > 0 || time.Since(lastWrite) >= jobEventsHeartbeat {
+				if err := w.Flush(); err != nil {
+					return //client sudah disconnect
+				}
+				lastWrite = time.Now()
+			}
+
+			time.Sleep(jobEventsPollInterval)
+		}
+	}))
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, id int, event string, data interface{}) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id > 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+}
+
+//status job summary + live progress pakai SSE
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultListLimit  = 20
+	maxListLimit      = 100
+	summaryPreviewLen = 300
+)
+
+// listSort = kolom yang bisa dipakai ?sort= di GET /pdfs. expr dibuat NOT NULL supaya perbandingan keyset-nya jelas,
+// cast = tipe nilai cursor waktu dibandingkan lagi di query berikutnya.
+type listSort struct {
+	expr string
+	cast string
+}
+
+// sqliteCasts = padanan tipe cast Postgres di SQLite (waktu disimpan sebagai teks UTC yang urutannya sama).
+var sqliteCasts = map[string]string{"timestamptz": "TEXT", "bigint": "INTEGER", "int": "INTEGER", "text": "TEXT"}
+
+func (s listSort) castFor(driver string) string {
+	if driver == "sqlite" {
+		return sqliteCasts[s.cast]
+	}
+	return s.cast
+}
+
+var listSorts = map[string]listSort{
+	"created_at": {"p.created_at", "timestamptz"},
+	"filesize":   {"p.filesize", "bigint"},
+	"filename":   {"LOWER(COALESCE(p.original_filename, p.filename))", "text"},
+	"page_count": {"COALESCE(p.page_count, 0)", "int"},
+	"style":      {"COALESCE(p.latest_summary_style, '')", "text"},
+	"language":   {"COALESCE(p.latest_summary_language, '')", "text"},
+	"status":     {"COALESCE(p.summary_status, '')", "text"},
+}
+
+// listCursor = posisi terakhir halaman sebelumnya (nilai kolom sort + id sebagai pemecah seri).
+// Dikirim ke client sebagai base64 JSON, client cukup mengembalikannya apa adanya.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Key   string `json:"k"`
+	ID    int    `json:"id"`
+}
+
+func encodeListCursor(cur listCursor) string {
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeListCursor(raw string) (listCursor, error) {
+	var cur listCursor
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(b, &cur)
+	return cur, err
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// listFilter menyusun WHERE untuk GET /pdfs dari query string.
+func listFilter(c *fiber.Ctx) (string, []interface{}, error) {
+	args := []interface{}{currentUserID(c)}
+	conds := []string{
+		"p.deleted_at IS NULL",
+		"p.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)",
+	}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	// IN ($n, $n+1, ..) bukan ANY(array) supaya jalan juga di SQLite
+	addIn := func(col string, values []string) {
+		conds = append(conds, col+" IN ("+repository.Placeholders(len(args)+1, len(values))+")")
+		for _, v := range values {
+			args = append(args, v)
+		}
+	}
+
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid workspace_id")
+	}
+	if workspaceID > 0 {
+		add("p.workspace_id = ?", workspaceID)
+	}
+
+	from, to, err := timeRangeQuery(c)
+	if err != nil {
+		return "", nil, err
+	}
+	if from != nil {
+		add("p.created_at >= ?", *from)
+	}
+	if to != nil {
+		add("p.created_at < ?", *to)
+	}
+
+	for _, size := range []struct{ key, cond string }{{"min_size", "p.filesize >= ?"}, {"max_size", "p.filesize <= ?"}} {
+		key, cond := size.key, size.cond
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return "", nil, fmt.Errorf("invalid %s (byte)", key)
+			}
+			add(cond, n)
+		}
+	}
+
+	if styles := splitList(c.Query("style")); len(styles) > 0 {
+		addIn("p.latest_summary_style", styles)
+	}
+	if langs := splitList(c.Query("language")); len(langs) > 0 {
+		addIn("p.latest_summary_language", langs)
+	}
+	if statuses := splitList(c.Query("status")); len(statuses) > 0 {
+		addIn("p.summary_status", statuses)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Query("failed"))) {
+	case "":
+	case "true", "1":
+		conds = append(conds, "p.summary_status = '"+models.JobFailed+"'")
+	case "false", "0":
+		conds = append(conds, "(p.summary_status IS NULL OR p.summary_status <> '"+models.JobFailed+"')")
+	default:
+		return "", nil, fmt.Errorf("invalid failed (true / false)")
+	}
+
+	filter, err := parsePDFFilter(c)
+	if err != nil {
+		return "", nil, err
+	}
+	filterSQL, filterArgs := filter.Where("p.", len(args)+1)
+	args = append(args, filterArgs...)
+
+	return " WHERE " + strings.Join(conds, " AND ") + filterSQL, args, nil
+}
+
+// ListPDFs = GET /pdfs, listing PDF dengan filter + sort + keyset pagination.
+// Halaman berikutnya diambil dengan ?cursor=<next_cursor>; posisinya tidak bergeser walaupun ada upload baru,
+// dan tidak ada COUNT(*) di setiap request. next_cursor null = sudah halaman terakhir.
+func (h *PdfHandler) ListPDFs(c *fiber.Ctx) error {
+	sortName := strings.ToLower(strings.TrimSpace(c.Query("sort", "created_at")))
+	sort, ok := listSorts[sortName]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid sort (available: created_at, filesize, filename, page_count, style, language, status)"})
+	}
+	order := strings.ToLower(strings.TrimSpace(c.Query("order", "desc")))
+	if order != "asc" && order != "desc" {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid order (asc / desc)"})
+	}
+	limit := c.QueryInt("limit", defaultListLimit)
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	where, args, err := listFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		cur, err := decodeListCursor(raw)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+		if cur.Sort != sortName || cur.Order != order {
+			return c.Status(400).JSON(fiber.Map{"error": "Cursor dibuat untuk sort / order lain, mulai lagi tanpa cursor"})
+		}
+		op := "<"
+		if order == "asc" {
+			op = ">"
+		}
+		args = append(args, cur.Key, cur.ID)
+		where += fmt.Sprintf(" AND (%s, p.id) %s (CAST($%d AS %s), $%d)", sort.expr, op, len(args)-1, sort.castFor(h.Config.DBDriver), len(args))
+	}
+
+	args = append(args, limit+1) //+1 untuk tahu masih ada halaman berikutnya
+	rows, err := h.DB.Query(fmt.Sprintf(`
+		SELECT p.id, p.filename, COALESCE(p.original_filename, p.filename), p.filesize, p.created_at,
+		       p.workspace_id, p.folder_id, p.page_count, SUBSTR(COALESCE(p.latest_summary, ''), 1, %d),
+		       COALESCE(p.latest_summary_style, ''), COALESCE(p.latest_summary_language, ''),
+		       COALESCE(p.summary_status, ''), CAST(%s AS TEXT)
+		FROM pdf_files p%s
+		ORDER BY %s %s, p.id %s
+		LIMIT $%d`,
+		summaryPreviewLen, sort.expr, where, sort.expr, order, order, len(args)), args...)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	defer rows.Close()
+
+	jakartaLoc := getJakartaLocation()
+	pdfs := []fiber.Map{}
+	var ids []int
+	var lastKey string
+	hasMore := false
+	for rows.Next() {
+		if len(pdfs) == limit {
+			hasMore = true //baris ke limit+1 cuma penanda masih ada halaman berikutnya
+			break
+		}
+		var id, workspaceID int
+		var filename, originalFilename, preview, style, language, status, key string
+		var filesize int64
+		var createdAt time.Time
+		var folderID, pageCount sql.NullInt64
+		if err := rows.Scan(&id, &filename, &originalFilename, &filesize, &createdAt, &workspaceID, &folderID, &pageCount,
+			&preview, &style, &language, &status, &key); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+		pdfs = append(pdfs, fiber.Map{
+			"id":                id,
+			"filename":          filename,
+			"original_filename": originalFilename,
+			"filesize":          filesize,
+			"created_at":        createdAt.In(jakartaLoc),
+			"workspace_id":      workspaceID,
+			"folder_id":         nullInt(folderID),
+			"page_count":        nullInt(pageCount),
+			"summary_preview":   preview,
+			"summary_style":     style,
+			"summary_language":  language,
+			"summary_status":    status,
+		})
+		ids = append(ids, id)
+		lastKey = key
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	rows.Close()
+
+	tags, err := loadPDFTags(h.DB, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load tags"})
+	}
+	for i, id := range ids {
+		pdfs[i]["tags"] = append([]models.Tag{}, tags[id]...)
+	}
+
+	var nextCursor interface{}
+	if hasMore {
+		nextCursor = encodeListCursor(listCursor{Sort: sortName, Order: order, Key: lastKey, ID: ids[len(ids)-1]})
+	}
+
+	return c.JSON(fiber.Map{
+		"pdfs":        pdfs,
+		"count":       len(pdfs),
+		"has_more":    hasMore,
+		"next_cursor": nextCursor,
+		"sort":        sortName,
+		"order":       order,
+		"limit":       limit,
+	})
+}
+
+//for learn, keyset pagination: "ambil yang setelah (nilai, id) terakhir", bukan OFFSET yang bergeser kalau ada data baru
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+)
+
+func TestListCursorEncoding(t *testing.T) {
+	cur := listCursor{Sort: "filename", Order: "asc", Key: "laporan \"q1\"/2024.pdf", ID: 42}
+	raw := encodeListCursor(cur)
+	got, err := decodeListCursor(raw)
+	if err != nil || got != cur {
+		t.Fatalf("round trip = %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"bukan base64", "%%%"},
+		{"base64 standar dengan padding", "eyJzIjoiYSJ9=="},
+		{"bukan JSON", "bm90LWpzb24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeListCursor(tt.raw); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+// semua halaman ?limit=2 digabung harus sama dengan satu halaman penuh, walaupun nilai kolom sort-nya kembar.
+func TestListPDFsCursor(t *testing.T) {
+	env := newHandlerEnv(t)
+	sameTime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	names := []string{"b.pdf", "A.pdf", "a.pdf", "c.pdf", "B.pdf", "d.pdf", "e.pdf"}
+	for i, name := range names {
+		id := dbtest.PDF(t, env.db, env.workspaceID, env.userID, name)
+		// tiga PDF pertama waktu & ukurannya kembar, sisanya beda
+		created, size := sameTime, 100
+		if i >= 3 {
+			created, size = sameTime.Add(time.Duration(i)*time.Minute), 100*i
+		}
+		if _, err := env.db.Exec(`UPDATE pdf_files SET created_at = $1, filesize = $2 WHERE id = $3`, created, size, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages := func(query string) ([]int, error) {
+		var ids []int
+		cursor := ""
+		for i := 0; i < 10; i++ {
+			code, body := env.sendJSON(t, "GET", "/pdfs?limit=2&"+query+cursor, nil)
+			if code != 200 {
+				return nil, fmt.Errorf("status %d: %v", code, body)
+			}
+			ids = append(ids, listIDs(body, "pdfs", "id")...)
+			next, _ := body["next_cursor"].(string)
+			if next == "" {
+				if body["has_more"] != false {
+					return nil, fmt.Errorf("next_cursor kosong tapi has_more = %v", body["has_more"])
+				}
+				return ids, nil
+			}
+			cursor = "&cursor=" + next
+		}
+		return nil, fmt.Errorf("tidak selesai setelah 10 halaman")
+	}
+
+	for _, sort := range []string{"created_at", "filesize", "filename", "page_count", "status"} {
+		for _, order := range []string{"asc", "desc"} {
+			query := "sort=" + sort + "&order=" + order
+			t.Run(query, func(t *testing.T) {
+				_, body := env.sendJSON(t, "GET", "/pdfs?limit=100&"+query, nil)
+				want := listIDs(body, "pdfs", "id")
+				if len(want) != len(names) {
+					t.Fatalf("full listing = %v", want)
+				}
+				got, err := pages(query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("paged = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+
+	// upload baru di tengah pagination tidak menggeser halaman berikutnya
+	_, body := env.sendJSON(t, "GET", "/pdfs?limit=3", nil)
+	first := listIDs(body, "pdfs", "id")
+	newID := dbtest.PDF(t, env.db, env.workspaceID, env.userID, "baru.pdf")
+	_, body = env.sendJSON(t, "GET", "/pdfs?limit=100&cursor="+body["next_cursor"].(string), nil)
+	rest := listIDs(body, "pdfs", "id")
+	if containsID(rest, newID) || len(first)+len(rest) != len(names) {
+		t.Fatalf("first=%v rest=%v new=%d", first, rest, newID)
+	}
+	for _, id := range first {
+		if containsID(rest, id) {
+			t.Fatalf("id %d appears on both pages", id)
+		}
+	}
+}
+
+func TestListPDFsCursorErrors(t *testing.T) {
+	env := newHandlerEnv(t)
+	for i := 0; i < 3; i++ {
+		dbtest.PDF(t, env.db, env.workspaceID, env.userID, strconv.Itoa(i)+".pdf")
+	}
+	_, body := env.sendJSON(t, "GET", "/pdfs?limit=1&sort=filename&order=asc", nil)
+	cursor, _ := body["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatalf("no next_cursor: %v", body)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{"cursor valid", "sort=filename&order=asc&cursor=" + cursor, 200},
+		{"sort beda", "sort=filesize&order=asc&cursor=" + cursor, 400},
+		{"order beda", "sort=filename&order=desc&cursor=" + cursor, 400},
+		{"cursor rusak", "sort=filename&order=asc&cursor=xyz", 400},
+		{"sort tidak dikenal", "sort=owner", 400},
+		{"order tidak dikenal", "order=random", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, body := env.sendJSON(t, "GET", "/pdfs?limit=1&"+tt.query, nil); code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %v)", code, tt.wantCode, body)
+			}
+		})
+	}
+}
@@ -2,23 +2,51 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json" //untuk parsing response pyhton
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
 	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/jobs"
 	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
 	"pdf-backend-fiber/internal/services"
+	"pdf-backend-fiber/internal/storage"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type PdfHandler struct { //menyimpan semua kebutuhan handlerpdf
-	DB     *sql.DB
-	Config config.Config
-	Python *services.PythonClient
+	DB          *sql.DB
+	Config      config.Config
+	Summarizers *services.Registry
+	Storage     storage.Storage
+	Queue       *jobs.Queue
+	PDFs        repository.PdfRepository
+	Summaries   repository.SummaryRepository
+}
+
+// nullInt mengubah kolom INT nullable jadi nil di JSON
+func nullInt(v sql.NullInt64) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Int64
+}
+
+// historyStatus = status ringkasan PDF untuk client: pending / succeeded / failed.
+// summary_status diisi trigger dari job terakhir, PDF lama tanpa job dianggap selesai kalau sudah punya ringkasan.
+func historyStatus(pdf *models.PdfFile) string {
+	switch pdf.SummaryStatus {
+	case models.JobSucceeded, models.JobFailed:
+		return pdf.SummaryStatus
+	case models.JobQueued, models.JobRunning:
+		return models.SummaryPending
+	}
+	if pdf.LatestSummary != "" {
+		return models.SummarySucceeded
+	}
+	return models.SummaryPending
 }
 
 func getJakartaLocation() *time.Location {
@@ -29,13 +57,18 @@ func getJakartaLocation() *time.Location {
 	return loc
 }
 
-func NewPdfHandler(db *sql.DB, cfg config.Config) *PdfHandler {
+func NewPdfHandler(db *sql.DB, cfg config.Config, queue *jobs.Queue, store storage.Storage) *PdfHandler {
+	repos := repository.New(db, cfg.DBDriver)
 	return &PdfHandler{
-		DB:     db,
-		Config: cfg,
-		Python: services.NewPythonClient(cfg.PythonAPI),
+		DB:          db,
+		Config:      cfg,
+		Summarizers: queue.Summarizers, //registry yang sama dengan worker, circuit breaker-nya juga sama
+		Storage:     store,
+		Queue:       queue,
+		PDFs:        repos.PDFs,
+		Summaries:   repos.Summaries,
 	}
-} //inisialisasi piton client, dipanggilnya di routes
+} //inisialisasi summarizer (python/openai/extractive), dipanggilnya di routes
 
 func (h *PdfHandler) GetPDF(c *fiber.Ctx) error {
 	pdfID, err := strconv.Atoi(c.Params("id"))
@@ -43,63 +76,80 @@ func (h *PdfHandler) GetPDF(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
 	}
 
-	jakartaLoc := getJakartaLocation()
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleViewer)
+	if !ok {
+		return err
+	}
 
-	var id int
-	var filename, originalFilename, fp string
-	var filesize int64
-	var uploadTime, createdAt time.Time
+	jakartaLoc := getJakartaLocation()
 
-	err = h.DB.QueryRow(`
-		SELECT id, filename, original_filename, filepath, filesize, upload_time, created_at
-		FROM pdf_files WHERE id = $1
-	`, pdfID).Scan(&id, &filename, &originalFilename, &fp, &filesize, &uploadTime, &createdAt)
+	pdf, err := h.PDFs.Get(c.UserContext(), pdfID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == repository.ErrNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
 	}
 
-	uploadTime = uploadTime.In(jakartaLoc)
-	createdAt = createdAt.In(jakartaLoc)
-
 	// Get summaries
-	summaryRows, err := h.DB.Query(`
-		SELECT summary_text, summary_style, process_time_ms, language_detected, created_at
-		FROM summaries WHERE pdf_id = $1 ORDER BY created_at DESC
-	`, pdfID)
+	rows, err := h.Summaries.ListByPDF(c.UserContext(), pdfID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to get summaries"})
 	}
-	defer summaryRows.Close()
 
 	var summaries []map[string]interface{}
-	for summaryRows.Next() {
-		var summaryText, summaryStyle, languageDetected string
-		var processTimeMs int64
-		var summaryCreatedAt time.Time
-
-		if err := summaryRows.Scan(&summaryText, &summaryStyle, &processTimeMs, &languageDetected, &summaryCreatedAt); err != nil {
-			continue
-		}
-
+	for _, s := range rows {
 		summaries = append(summaries, map[string]interface{}{
-			"text":              summaryText,
-			"style":             summaryStyle,
-			"process_time_ms":   processTimeMs,
-			"language_detected": languageDetected,
-			"created_at":        summaryCreatedAt.In(jakartaLoc),
+			"id":                s.ID,
+			"text":              s.SummaryText,
+			"style":             s.SummaryStyle,
+			"process_time_ms":   s.ProcessTimeMs,
+			"language_detected": s.LanguageDetected,
+			"created_at":        s.CreatedAt.In(jakartaLoc),
+			"cache_hit":         s.CacheHit,
+			"chunks_count":      s.ChunksCount,
+			"chars_covered":     s.CharsCovered,
+			"total_chars":       s.TotalChars,
+			"status":            s.Status,
+			"error_code":        s.ErrorCode,
+			"error_message":     s.ErrorMessage,
+			"attempts":          s.Attempts,
+			"provider":          s.Provider,
+			"model":             s.Model,
 		})
 	}
 
+	tags, err := loadPDFTags(h.DB, []int{pdfID})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get tags"})
+	}
+
+	// metadata PDF (NULL untuk file yang diupload sebelum inspeksi ada)
+	var pdfCreated interface{}
+	if pdf.PdfCreatedAt != nil {
+		pdfCreated = pdf.PdfCreatedAt.In(jakartaLoc)
+	}
+
 	return c.JSON(fiber.Map{
-		"id":                id,
-		"filename":          filename,
-		"original_filename": originalFilename,
-		"filesize":          filesize,
-		"upload_time":       uploadTime,
-		"created_at":        createdAt,
+		"id":                pdf.ID,
+		"filename":          pdf.Filename,
+		"original_filename": pdf.OriginalFilename,
+		"filesize":          pdf.Filesize,
+		"upload_time":       pdf.UploadTime.In(jakartaLoc),
+		"created_at":        pdf.CreatedAt.In(jakartaLoc),
+		"page_count":        pdf.PageCount,
+		"pdf_version":       pdf.PdfVersion,
+		"pdf_title":         pdf.PdfTitle,
+		"pdf_author":        pdf.PdfAuthor,
+		"pdf_subject":       pdf.PdfSubject,
+		"pdf_created_at":    pdfCreated,
+		"is_encrypted":      pdf.IsEncrypted,
+		"content_sha256":    pdf.ContentSHA256,
+		"allow_duplicate":   pdf.AllowDuplicate,
+		"summary_status":    historyStatus(pdf),
+		"workspace_id":      workspaceID,
+		"folder_id":         pdf.FolderID,
+		"tags":              append([]models.Tag{}, tags[pdfID]...),
 		"summaries":         summaries,
 	})
 }
@@ -110,27 +160,39 @@ func (h *PdfHandler) DeletePDF(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
 	}
 
-	var fp string
-	err = h.DB.QueryRow("SELECT filepath FROM pdf_files WHERE id = $1", pdfID).Scan(&fp)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
-		}
-		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	// hapus PDF cuma boleh owner workspace
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleOwner)
+	if !ok {
+		return err
 	}
 
-	_, err = h.DB.Exec("DELETE FROM pdf_files WHERE id = $1", pdfID)
+	// soft delete: PDF masuk trash, ringkasan & file tetap ada sampai di-purge
+	deleted, err := h.PDFs.SoftDelete(c.UserContext(), pdfID, currentUserID(c))
 	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete from database"})
 	}
+	originalFilename, deletedAt := deleted.OriginalFilename, *deleted.DeletedAt
+	purgeAt := deletedAt.Add(h.Config.TrashRetention)
 
-	if err := os.Remove(fp); err != nil {
-		fmt.Printf("Warning: Failed to delete file %s: %v\n", fp, err)
-	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFDelete,
+		TargetType:  models.TargetPDF,
+		TargetID:    pdfID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"original_filename": originalFilename, "deleted_at": nil},
+		After:       fiber.Map{"deleted_at": deletedAt, "purge_at": purgeAt},
+	})
 
+	jakartaLoc := getJakartaLocation()
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "PDF deleted successfully",
+		"success":    true,
+		"message":    "PDF dipindah ke trash",
+		"pdf_id":     pdfID,
+		"deleted_at": deletedAt.In(jakartaLoc),
+		"purge_at":   purgeAt.In(jakartaLoc),
 	})
 }
 
@@ -139,45 +201,57 @@ func (h *PdfHandler) GetHistory(c *fiber.Ctx) error {
 	offset := c.QueryInt("offset", 0) //mulai dr data ke brp
 
 	jakartaLoc := getJakartaLocation()
+	userID := currentUserID(c)
+	workspaceID, ok := requestedWorkspace(c) //0 = semua workspace user
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+	filter, err := parsePDFFilter(c) //?tag_id= & ?folder_id=
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	// Get total count buat paginasi
-	var totalCount int
-	err := h.DB.QueryRow(`SELECT COUNT(*) FROM pdf_files`).Scan(&totalCount)
+	query := repository.PDFQuery{UserID: userID, WorkspaceID: workspaceID, Filter: filter, Limit: limit, Offset: offset}
+	totalCount, err := h.PDFs.Count(c.UserContext(), query)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung total data"})
 	} //frontend perlu ta totalnya buat pagination
 
 	// Single query with latest_summary - NO MORE JOIN!
-	rows, err := h.DB.Query(`
-		SELECT id, filename, filesize, created_at, COALESCE(latest_summary, '') as latest_summary
-		FROM pdf_files
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+	pdfs, err := h.PDFs.List(c.UserContext(), query)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil data"})
 	}
-	defer rows.Close()
 
 	var history []models.HistoryItem
-	for rows.Next() { //1 baris 1 pdf
-		var item models.HistoryItem
-		var latestSummary string
-
-		if err := rows.Scan(&item.ID, &item.Filename, &item.Filesize, &item.UploadedAt, &latestSummary); err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Gagal parsing data"})
+	var ids []int
+	for _, pdf := range pdfs { //1 baris 1 pdf
+		item := models.HistoryItem{
+			ID:         pdf.ID,
+			Filename:   pdf.Filename,
+			Filesize:   pdf.Filesize,
+			Status:     historyStatus(&pdf),
+			UploadedAt: pdf.CreatedAt.In(jakartaLoc),
+			Summary:    pdf.LatestSummary,
+			FolderID:   pdf.FolderID,
 		}
 
-		item.Status = "completed"
-		item.Summary = latestSummary
-		item.UploadedAt = item.UploadedAt.In(jakartaLoc)
-
 		// Set ProcessedAt if summary exists
-		if latestSummary != "" {
+		if item.Status == models.SummarySucceeded {
 			item.ProcessedAt = time.Now().In(jakartaLoc)
 		}
 
 		history = append(history, item)
+		ids = append(ids, item.ID)
+	}
+
+	tags, err := loadPDFTags(h.DB, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil tag"})
+	}
+	for i := range history {
+		history[i].Tags = append([]models.Tag{}, tags[history[i].ID]...)
 	}
 
 	response := models.HistoryResponse{
@@ -192,40 +266,44 @@ func (h *PdfHandler) GetHistory(c *fiber.Ctx) error {
 
 func (h *PdfHandler) SimplePDFs(c *fiber.Ctx) error {
 	jakartaLoc := getJakartaLocation()
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+	filter, err := parsePDFFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	rows, err := h.DB.Query(`
-		SELECT id, filename, COALESCE(original_filename, filename) as original_filename, 
-		       filesize, upload_time, COALESCE(latest_summary, '') as latest_summary
-		FROM pdf_files
-		ORDER BY id DESC
-		LIMIT 20
-	`)
+	rows, err := h.PDFs.List(c.UserContext(), repository.PDFQuery{
+		UserID: currentUserID(c), WorkspaceID: workspaceID, Filter: filter, OrderByID: true, Limit: 20,
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Query Error: %v", err)})
 	}
-	defer rows.Close()
 
 	var pdfs []map[string]interface{}
-	for rows.Next() {
-		var id int
-		var filename, originalFilename, latestSummary string
-		var filesize int64
-		var uploadTime time.Time
-
-		if err := rows.Scan(&id, &filename, &originalFilename, &filesize, &uploadTime, &latestSummary); err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Scan Error: %v", err)})
-		}
-
-		uploadTime = uploadTime.In(jakartaLoc)
-
+	var ids []int
+	for _, pdf := range rows {
 		pdfs = append(pdfs, map[string]interface{}{
-			"id":                id,
-			"filename":          filename,
-			"original_filename": originalFilename,
-			"filesize":          filesize,
-			"upload_time":       uploadTime,
-			"latest_summary":    latestSummary,
+			"id":                pdf.ID,
+			"filename":          pdf.Filename,
+			"original_filename": pdf.OriginalFilename,
+			"filesize":          pdf.Filesize,
+			"upload_time":       pdf.UploadTime.In(jakartaLoc),
+			"latest_summary":    pdf.LatestSummary,
+			"workspace_id":      pdf.WorkspaceID,
+			"folder_id":         pdf.FolderID,
 		})
+		ids = append(ids, pdf.ID)
+	}
+
+	tags, err := loadPDFTags(h.DB, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load tags"})
+	}
+	for _, pdf := range pdfs {
+		pdf["tags"] = append([]models.Tag{}, tags[pdf["id"].(int)]...)
 	}
 
 	return c.JSON(fiber.Map{
@@ -240,35 +318,30 @@ func (h *PdfHandler) SimplePDFByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
 	}
 
-	jakartaLoc := getJakartaLocation()
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleViewer)
+	if !ok {
+		return err
+	}
 
-	var id int
-	var filename, originalFilename, fp, latestSummary string
-	var filesize int64
-	var uploadTime time.Time
+	jakartaLoc := getJakartaLocation()
 
-	err = h.DB.QueryRow(`
-		SELECT id, filename, COALESCE(original_filename, filename) as original_filename, 
-		       filepath, filesize, upload_time, COALESCE(latest_summary, '') as latest_summary
-		FROM pdf_files WHERE id = $1
-	`, pdfID).Scan(&id, &filename, &originalFilename, &fp, &filesize, &uploadTime, &latestSummary)
+	pdf, err := h.PDFs.Get(c.UserContext(), pdfID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == repository.ErrNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Database error: %v", err)})
 	}
 
-	uploadTime = uploadTime.In(jakartaLoc)
-
 	return c.JSON(fiber.Map{
-		"id":                id,
-		"filename":          filename,
-		"original_filename": originalFilename,
-		"filepath":          fp,
-		"filesize":          filesize,
-		"upload_time":       uploadTime,
-		"latest_summary":    latestSummary,
+		"id":                pdf.ID,
+		"filename":          pdf.Filename,
+		"original_filename": pdf.OriginalFilename,
+		"file_url":          fmt.Sprintf("/pdf/%d/file", pdf.ID), //path storage tidak dibocorkan ke client
+		"filesize":          pdf.Filesize,
+		"upload_time":       pdf.UploadTime.In(jakartaLoc),
+		"latest_summary":    pdf.LatestSummary,
+		"workspace_id":      workspaceID,
 	})
 }
 
@@ -287,18 +360,25 @@ func (h *PdfHandler) UpdatePDF(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
 	}
 
-	var exists bool
-	err = h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pdf_files WHERE id = $1)", pdfID).Scan(&exists)
-	if err != nil || !exists {
-		return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
 	}
 
 	if updateData.OriginalFilename != "" {
-		_, err = h.DB.Exec("UPDATE pdf_files SET original_filename = $1 WHERE id = $2",
-			updateData.OriginalFilename, pdfID)
+		oldName, err := h.PDFs.Rename(c.UserContext(), pdfID, updateData.OriginalFilename)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Update failed: %v", err)})
 		}
+
+		recordAudit(c, h.DB, auditEntry{
+			Action:      models.AuditPDFRename,
+			TargetType:  models.TargetPDF,
+			TargetID:    pdfID,
+			WorkspaceID: workspaceID,
+			Before:      fiber.Map{"original_filename": oldName},
+			After:       fiber.Map{"original_filename": updateData.OriginalFilename},
+		})
 	}
 
 	return c.JSON(fiber.Map{
@@ -308,6 +388,8 @@ func (h *PdfHandler) UpdatePDF(c *fiber.Ctx) error {
 	})
 }
 
+// Resummarize membuat ringkasan baru dengan style / provider lain lewat queue (sama seperti /upload/complete),
+// jadi rantai fallback summarizer tidak menahan request. Client cek progres pakai job_id.
 func (h *PdfHandler) Resummarize(c *fiber.Ctx) error {
 	pdfID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
@@ -315,7 +397,9 @@ func (h *PdfHandler) Resummarize(c *fiber.Ctx) error {
 	}
 
 	var requestData struct {
-		Style string `json:"style"`
+		Style    string `json:"style"`
+		Provider string `json:"provider"` //kosong = summarizer default dari config
+		NoCache  bool   `json:"no_cache"` //true = paksa generate ulang walaupun ada di summary_cache
 	}
 	if err := c.BodyParser(&requestData); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
@@ -324,57 +408,125 @@ func (h *PdfHandler) Resummarize(c *fiber.Ctx) error {
 		requestData.Style = "standard"
 	}
 
-	var fp string
-	err = h.DB.QueryRow("SELECT filepath FROM pdf_files WHERE id = $1", pdfID).Scan(&fp)
+	// provider dicek di sini supaya typo langsung 400, bukan job yang gagal di worker
+	summarizer, err := h.Summarizers.Get(requestData.Provider)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+
+	if _, err := h.PDFs.Get(c.UserContext(), pdfID); err != nil {
+		if err == repository.ErrNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
 	}
 
-	summaryResult, duration, err := h.Python.Summarize(fp, requestData.Style)
-	summaryText := ""
-	language := "unknown"
+	jobID, summaryID, err := h.Queue.Enqueue(pdfID, requestData.Style, requestData.Provider, requestData.NoCache)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat job summary"})
+	}
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFResummarize,
+		TargetType:  models.TargetPDF,
+		TargetID:    pdfID,
+		WorkspaceID: workspaceID,
+		After: fiber.Map{
+			"summary_id": summaryID,
+			"job_id":     jobID,
+			"style":      requestData.Style,
+			"provider":   summarizer.Name(),
+			"no_cache":   requestData.NoCache,
+		},
+	})
+
+	return c.Status(202).JSON(fiber.Map{
+		"success":    true,
+		"pdf_id":     pdfID,
+		"summary_id": summaryID,
+		"job_id":     jobID,
+		"status":     models.JobQueued,
+		"style":      requestData.Style,
+		"provider":   summarizer.Name(),
+	})
+}
+
+// RetrySummary menjalankan ulang ringkasan yang gagal (default yang terbaru, atau {"summary_id": n}) lewat queue.
+// Ringkasan lain yang sudah berhasil tidak disentuh.
+func (h *PdfHandler) RetrySummary(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+
+	var req struct {
+		SummaryID int `json:"summary_id"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+		}
+	}
+
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
 
+	summaries, err := h.Summaries.ListByPDF(c.UserContext(), pdfID)
 	if err != nil {
-		summaryText = "Re-summarization failed - Python service error"
-		duration = 0
-	} else {
-		var response struct {
-			Summary  string `json:"summary"`
-			Language string `json:"detected_language"`
-			Style    string `json:"style"`
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get summaries"})
+	}
+	var failed *models.Summary
+	for i := range summaries { //terbaru dulu
+		s := &summaries[i]
+		if req.SummaryID != 0 && s.ID != req.SummaryID {
+			continue
+		}
+		if s.Status == models.SummaryFailed {
+			failed = s
+			break
 		}
-		if err := json.Unmarshal([]byte(summaryResult), &response); err == nil {
-			summaryText = response.Summary
-			language = response.Language
-		} else {
-			summaryText = summaryResult
+		if req.SummaryID != 0 {
+			return c.Status(409).JSON(fiber.Map{"error": "Ringkasan ini tidak berstatus failed", "code": "NOT_FAILED", "status": s.Status})
 		}
 	}
+	if failed == nil {
+		if req.SummaryID != 0 {
+			return c.Status(404).JSON(fiber.Map{"error": "Summary not found"})
+		}
+		return c.Status(409).JSON(fiber.Map{"error": "Tidak ada ringkasan gagal untuk PDF ini", "code": "NOTHING_TO_RETRY"})
+	}
 
-	_, err = h.DB.Exec(
-		`INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, language_detected)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		pdfID,
-		summaryText,
-		requestData.Style,
-		duration,
-		language,
-	)
+	jobID, err := h.Queue.Retry(failed.ID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to save new summary"})
+		if err == jobs.ErrNotRetryable { //keburu di-retry request lain
+			return c.Status(409).JSON(fiber.Map{"error": "Ringkasan ini sedang di-retry", "code": "NOT_FAILED"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat job summary"})
 	}
 
-	return c.JSON(fiber.Map{
-		"success":         true,
-		"message":         "PDF re-summarized successfully",
-		"pdf_id":          pdfID,
-		"new_summary":     summaryText,
-		"style":           requestData.Style,
-		"language":        language,
-		"process_time_ms": duration,
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFRetry,
+		TargetType:  models.TargetPDF,
+		TargetID:    pdfID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"summary_id": failed.ID, "status": failed.Status, "error_code": failed.ErrorCode, "attempts": failed.Attempts},
+		After:       fiber.Map{"summary_id": failed.ID, "status": models.SummaryPending, "job_id": jobID},
+	})
+
+	return c.Status(202).JSON(fiber.Map{
+		"success":    true,
+		"pdf_id":     pdfID,
+		"summary_id": failed.ID,
+		"job_id":     jobID,
+		"status":     models.SummaryPending,
+		"attempts":   failed.Attempts,
 	})
 }
 
@@ -386,35 +538,34 @@ func (h *PdfHandler) GetSummaries(c *fiber.Ctx) error {
 
 	jakartaLoc := getJakartaLocation()
 
-	rows, err := h.DB.Query(`
-		SELECT id, summary_text, summary_style, process_time_ms, language_detected, created_at
-		FROM summaries
-		WHERE pdf_id = $1
-		ORDER BY created_at DESC
-	`, pdfID)
+	if _, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleViewer); !ok {
+		return err
+	}
+
+	rows, err := h.Summaries.ListByPDF(c.UserContext(), pdfID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Query error: %v", err)})
 	}
-	defer rows.Close()
 
 	var summaries []map[string]interface{}
-	for rows.Next() {
-		var id int
-		var summaryText, summaryStyle, languageDetected string
-		var processTimeMs int64
-		var createdAt time.Time
-
-		if err := rows.Scan(&id, &summaryText, &summaryStyle, &processTimeMs, &languageDetected, &createdAt); err != nil {
-			continue
-		}
-
+	for _, s := range rows {
 		summaries = append(summaries, map[string]interface{}{
-			"id":                id,
-			"summary_text":      summaryText,
-			"summary_style":     summaryStyle,
-			"process_time_ms":   processTimeMs,
-			"language_detected": languageDetected,
-			"created_at":        createdAt.In(jakartaLoc),
+			"id":                s.ID,
+			"summary_text":      s.SummaryText,
+			"summary_style":     s.SummaryStyle,
+			"process_time_ms":   s.ProcessTimeMs,
+			"language_detected": s.LanguageDetected,
+			"created_at":        s.CreatedAt.In(jakartaLoc),
+			"cache_hit":         s.CacheHit,
+			"chunks_count":      s.ChunksCount,
+			"chars_covered":     s.CharsCovered,
+			"total_chars":       s.TotalChars,
+			"status":            s.Status,
+			"error_code":        s.ErrorCode,
+			"error_message":     s.ErrorMessage,
+			"attempts":          s.Attempts,
+			"provider":          s.Provider,
+			"model":             s.Model,
 		})
 	}
 
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+	"pdf-backend-fiber/internal/models"
+)
+
+func TestRetrySummary(t *testing.T) {
+	env := newHandlerEnv(t)
+	editor, _ := dbtest.User(t, env.db, "editor@test.id")
+	viewer, _ := dbtest.User(t, env.db, "viewer@test.id")
+	team := sharedWorkspace(t, env.db, "Tim", map[string]int{"owner": env.userID, "editor": editor, "viewer": viewer})
+	users := map[string]int{"owner": env.userID, "editor": editor, "viewer": viewer}
+
+	pdfID := dbtest.PDF(t, env.db, team, env.userID, "a.pdf")
+	summary := func(status, code string) int {
+		var id int
+		err := env.db.QueryRow(`
+			INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, status, attempts, error_code, user_id)
+			VALUES ($1, '', 'standard', 0, $2, 1, NULLIF($3, ''), $4) RETURNING id`, pdfID, status, code, env.userID,
+		).Scan(&id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	succeeded := summary(models.SummarySucceeded, "")
+	failed := summary(models.SummaryFailed, "timeout")
+	path := "/pdf/" + strconv.Itoa(pdfID) + "/retry"
+
+	// langkah berurutan: yang ditolak dulu, lalu retry berhasil, lalu tidak ada lagi yang bisa di-retry
+	tests := []struct {
+		name     string
+		path     string
+		user     string
+		payload  interface{}
+		wantCode int
+		wantErr  string //field "code" di body ("" = tidak dicek)
+	}{
+		{"id tidak valid", "/pdf/abc/retry", "owner", nil, 400, ""},
+		{"PDF tidak ada", "/pdf/9999/retry", "owner", nil, 404, ""},
+		{"viewer tidak boleh", path, "viewer", nil, 403, ""},
+		{"ringkasan yang berhasil", path, "owner", fiber.Map{"summary_id": succeeded}, 409, "NOT_FAILED"},
+		{"ringkasan PDF lain", path, "owner", fiber.Map{"summary_id": 9999}, 404, ""},
+		{"editor retry yang gagal", path, "editor", nil, 202, ""},
+		{"sudah pending", path, "owner", fiber.Map{"summary_id": failed}, 409, "NOT_FAILED"},
+		{"tidak ada yang gagal", path, "owner", nil, 409, "NOTHING_TO_RETRY"},
+	}
+	for _, tt := range tests {
+		code, body := env.sendJSONAs(t, "POST", tt.path, users[tt.user], tt.payload)
+		if code != tt.wantCode || (tt.wantErr != "" && body["code"] != tt.wantErr) {
+			t.Fatalf("%s: status = %d, want %d %s (body %v)", tt.name, code, tt.wantCode, tt.wantErr, body)
+		}
+		if code == 202 && (bodyInt(body, "summary_id") != failed || body["status"] != models.SummaryPending) {
+			t.Fatalf("%s: body = %v", tt.name, body)
+		}
+	}
+
+	// ringkasan gagal dipakai lagi (pending, error dibersihkan), yang sudah berhasil tidak disentuh
+	var failedStatus, errorCode, succeededStatus, pdfStatus string
+	err := env.db.QueryRow(`
+		SELECT f.status, COALESCE(f.error_code, ''), s.status, COALESCE(p.summary_status, '')
+		FROM summaries f, summaries s, pdf_files p WHERE f.id = $1 AND s.id = $2 AND p.id = $3`, failed, succeeded, pdfID,
+	).Scan(&failedStatus, &errorCode, &succeededStatus, &pdfStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failedStatus != models.SummaryPending || errorCode != "" || succeededStatus != models.SummarySucceeded || pdfStatus != models.JobQueued {
+		t.Fatalf("after retry: failed=%s/%q succeeded=%s pdf=%s", failedStatus, errorCode, succeededStatus, pdfStatus)
+	}
+	var audits int
+	if err := env.db.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE action = $1 AND target_id = $2`, models.AuditPDFRetry, pdfID).Scan(&audits); err != nil {
+		t.Fatal(err)
+	}
+	if audits != 1 {
+		t.Fatalf("pdf.retry audit rows = %d, want 1", audits)
+	}
+}
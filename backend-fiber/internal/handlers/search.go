@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// headlineOptions = opsi ts_headline untuk snippet. Penanda <mark> dari Postgres, teks lain tidak di-escape,
+// jadi client harus escape dulu sebelum render sebagai HTML.
+const headlineOptions = "MaxFragments=2, MaxWords=30, MinWords=12, StartSel=<mark>, StopSel=</mark>"
+
+const maxSearchQueryLength = 200
+
+type searchRow struct {
+	ID                int
+	OriginalFilename  string
+	WorkspaceID       int
+	CreatedAt         time.Time
+	TextLanguage      string
+	Score             float64
+	FilenameHighlight string
+	TextSnippet       string
+	SummaryID         sql.NullInt64
+	SummarySnippet    string
+	SummaryLanguage   string
+}
+
+type SearchHandler struct {
+	DB       *sql.DB
+	Driver   string //postgres (tsvector) / sqlite (FTS5, lihat search_sqlite.go)
+	IDConfig string //text search config untuk bahasa Indonesia ("indonesian" atau fallback "simple")
+}
+
+func NewSearchHandler(db *sql.DB, driver string) *SearchHandler {
+	h := &SearchHandler{DB: db, Driver: driver, IDConfig: "simple"}
+	if driver != "sqlite" {
+		h.IDConfig = database.IndonesianTSConfig(context.Background(), db)
+	}
+	return h
+}
+
+// searchSQL menyusun query pencarian. Query user diparse tiga kali (simple / english / indonesian) supaya
+// GIN index tetap terpakai; ranking & snippet per baris pakai versi yang sesuai bahasa dokumennya.
+func (h *SearchHandler) searchSQL(filterSQL string) string {
+	rowQuery := func(langColumn string) string {
+		return fmt.Sprintf(`(CASE WHEN %[1]s IN ('id', 'ms') THEN q.qid WHEN %[1]s = 'en' THEN q.qen ELSE q.qs END || q.qs)`, langColumn)
+	}
+	matches := func(vector string) string {
+		return fmt.Sprintf(`(%[1]s @@ q.qs OR %[1]s @@ q.qen OR %[1]s @@ q.qid)`, vector)
+	}
+
+	return fmt.Sprintf(`
+		WITH q AS (
+			SELECT websearch_to_tsquery('simple', $1) AS qs,
+			       websearch_to_tsquery('english', $1) AS qen,
+			       websearch_to_tsquery('%[1]s', $1) AS qid
+		),
+		visible AS (
+			SELECT p.* FROM pdf_files p
+			WHERE p.deleted_at IS NULL
+			  AND p.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2)
+			  AND ($3 = 0 OR p.workspace_id = $3)%[9]s
+		),
+		pdf_hits AS (
+			SELECT p.id, ts_rank(p.search_vector, %[2]s) AS rank
+			FROM visible p, q
+			WHERE %[3]s
+		),
+		summary_hits AS (
+			SELECT DISTINCT ON (s.pdf_id) s.pdf_id, s.id AS summary_id, ts_rank(s.search_vector, %[4]s) AS rank
+			FROM summaries s JOIN visible p ON p.id = s.pdf_id, q
+			WHERE s.status = 'succeeded' AND (%[5]s)
+			ORDER BY s.pdf_id, rank DESC, s.created_at DESC
+		),
+		hits AS (
+			SELECT id FROM pdf_hits UNION SELECT pdf_id FROM summary_hits
+		)
+		SELECT p.id, COALESCE(p.original_filename, p.filename), p.workspace_id, p.created_at,
+		       COALESCE(p.text_language, ''),
+		       COALESCE(ph.rank, 0) + COALESCE(sh.rank, 0) AS score,
+		       ts_headline('simple', COALESCE(p.original_filename, p.filename), q.qs, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		       CASE WHEN ph.id IS NOT NULL AND COALESCE(p.extracted_text, '') <> ''
+		            THEN ts_headline(%[6]s, p.extracted_text, %[2]s, '%[8]s') ELSE '' END,
+		       sh.summary_id,
+		       CASE WHEN sh.summary_id IS NOT NULL
+		            THEN ts_headline(%[7]s, s.summary_text, %[4]s, '%[8]s') ELSE '' END,
+		       COALESCE(s.language_detected, ''),
+		       COUNT(*) OVER () AS total
+		FROM hits
+		JOIN pdf_files p ON p.id = hits.id
+		LEFT JOIN pdf_hits ph ON ph.id = p.id
+		LEFT JOIN summary_hits sh ON sh.pdf_id = p.id
+		LEFT JOIN summaries s ON s.id = sh.summary_id
+		CROSS JOIN q
+		ORDER BY score DESC, p.id DESC
+		LIMIT $4 OFFSET $5`,
+		h.IDConfig,
+		rowQuery("p.text_language"),
+		matches("p.search_vector"),
+		rowQuery("s.language_detected"),
+		matches("s.search_vector"),
+		database.TSConfigExpr("p.text_language", h.IDConfig),
+		database.TSConfigExpr("s.language_detected", h.IDConfig),
+		headlineOptions,
+		filterSQL,
+	)
+}
+
+// Search = GET /search?q=. Mencari di nama file, teks PDF, dan semua ringkasan di workspace user.
+// Sintaks q seperti mesin pencari: "frasa persis", -kata untuk mengecualikan, OR.
+// Satu hasil per PDF, diurutkan dari yang paling relevan; snippet berisi <mark> di kata yang cocok.
+// Filter tag / folder sama seperti /history (?tag_id=, ?folder_id=, ?recursive=).
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Parameter q wajib diisi"})
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Query terlalu panjang (maks %d karakter)", maxSearchQueryLength)})
+	}
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+	filter, err := parsePDFFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	limit := c.QueryInt("limit", 20)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	filterSQL, filterArgs := filter.Where("p.", 6)
+	searchSQL, match := h.searchSQL(filterSQL), query
+	if h.Driver == "sqlite" {
+		searchSQL, match = h.sqliteSearchSQL(filterSQL), ftsQuery(query)
+		if match == "" {
+			//cuma kata yang dikecualikan, sama seperti Postgres: tidak ada yang cocok
+			return c.JSON(fiber.Map{"query": query, "results": []fiber.Map{}, "count": 0, "total": 0, "limit": limit, "offset": offset})
+		}
+	}
+	args := append([]interface{}{match, currentUserID(c), workspaceID, limit, offset}, filterArgs...)
+	rows, err := h.DB.Query(searchSQL, args...)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Search failed"})
+	}
+	defer rows.Close()
+
+	jakartaLoc := getJakartaLocation()
+	results := []fiber.Map{}
+	var ids []int
+	total := 0
+	for rows.Next() {
+		var r searchRow
+		if err := rows.Scan(&r.ID, &r.OriginalFilename, &r.WorkspaceID, &r.CreatedAt, &r.TextLanguage, &r.Score,
+			&r.FilenameHighlight, &r.TextSnippet, &r.SummaryID, &r.SummarySnippet, &r.SummaryLanguage, &total); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Search failed"})
+		}
+		result := fiber.Map{
+			"pdf_id":             r.ID,
+			"original_filename":  r.OriginalFilename,
+			"filename_highlight": r.FilenameHighlight,
+			"workspace_id":       r.WorkspaceID,
+			"created_at":         r.CreatedAt.In(jakartaLoc),
+			"language":           r.TextLanguage,
+			"score":              r.Score,
+			"text_snippet":       r.TextSnippet,
+			"summary":            nil,
+		}
+		if r.SummaryID.Valid {
+			result["summary"] = fiber.Map{
+				"summary_id": r.SummaryID.Int64,
+				"snippet":    r.SummarySnippet,
+				"language":   r.SummaryLanguage,
+			}
+		}
+		results = append(results, result)
+		ids = append(ids, r.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Search failed"})
+	}
+	tags, err := loadPDFTags(h.DB, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Search failed"})
+	}
+	for i, id := range ids {
+		results[i]["tags"] = append([]models.Tag{}, tags[id]...)
+	}
+
+	return c.JSON(fiber.Map{
+		"query":   query,
+		"results": results,
+		"count":   len(results),
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+//for learn, full-text search: tsvector = daftar kata dasar dokumen, tsquery = kata yang dicari, @@ = cocok atau tidak
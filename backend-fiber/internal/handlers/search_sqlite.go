@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ftsQuery menerjemahkan sintaks websearch (seperti websearch_to_tsquery di Postgres) ke query MATCH FTS5:
+// "frasa persis", -kata untuk mengecualikan, OR di antara dua kata. Semua kata di-quote supaya karakter
+// khusus FTS5 (*, :, ^, dll) tidak diparse. "" = tidak ada kata positif, hasilnya pasti kosong.
+func ftsQuery(raw string) string {
+	type term struct {
+		text   string
+		negate bool
+	}
+	var terms []term
+	var orBefore []bool //orBefore[i] = term i disambung OR dengan term sebelumnya
+	pendingOr := false
+
+	rs := []rune(raw)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+		negate := false
+		if rs[i] == '-' {
+			negate = true
+			i++
+		}
+		var text string
+		quoted := i < len(rs) && rs[i] == '"'
+		if quoted {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			text = string(rs[i+1 : end])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) {
+				end++
+			}
+			text = string(rs[i:end])
+			i = end
+		}
+		if !quoted && !negate && strings.EqualFold(text, "or") {
+			pendingOr = len(terms) > 0
+			continue
+		}
+		if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		terms = append(terms, term{text: `"` + strings.ReplaceAll(text, `"`, `""`) + `"`, negate: negate})
+		orBefore = append(orBefore, pendingOr && !negate)
+		pendingOr = false
+	}
+
+	// kata positif: AND antar grup, OR di dalam grup
+	var groups [][]string
+	var negatives []string
+	for i, t := range terms {
+		switch {
+		case t.negate:
+			negatives = append(negatives, t.text)
+		case orBefore[i] && len(groups) > 0:
+			groups[len(groups)-1] = append(groups[len(groups)-1], t.text)
+		default:
+			groups = append(groups, []string{t.text})
+		}
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		parts[i] = g[0]
+		if len(g) > 1 {
+			parts[i] = "(" + strings.Join(g, " OR ") + ")"
+		}
+	}
+	expr := strings.Join(parts, " AND ")
+	for _, n := range negatives {
+		expr = "(" + expr + ") NOT " + n //NOT di FTS5 biner: a NOT b
+	}
+	return expr
+}
+
+// sqliteSearchSQL = versi FTS5 dari searchSQL, kolom hasil dan urutan argumennya sama ($1 = query MATCH).
+// Skor = -bm25 (bm25 FTS5 makin kecil makin relevan), snippet pakai highlight() / snippet() dengan <mark>.
+func (h *SearchHandler) sqliteSearchSQL(filterSQL string) string {
+	return fmt.Sprintf(`
+		WITH visible AS (
+			SELECT p.id FROM pdf_files p
+			WHERE p.deleted_at IS NULL
+			  AND p.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $2)
+			  AND ($3 = 0 OR p.workspace_id = $3)%[1]s
+		),
+		pdf_hits AS (
+			SELECT pdf_files_fts.rowid AS id, -bm25(pdf_files_fts) AS rank,
+			       highlight(pdf_files_fts, 0, '<mark>', '</mark>') AS filename_highlight,
+			       snippet(pdf_files_fts, 1, '<mark>', '</mark>', '…', 30) AS snippet
+			FROM pdf_files_fts
+			WHERE pdf_files_fts MATCH $1 AND pdf_files_fts.rowid IN (SELECT id FROM visible)
+		),
+		summary_matches AS (
+			SELECT s.pdf_id, s.id AS summary_id, s.created_at, -bm25(summaries_fts) AS rank,
+			       snippet(summaries_fts, 0, '<mark>', '</mark>', '…', 30) AS snippet
+			FROM summaries_fts JOIN summaries s ON s.id = summaries_fts.rowid
+			WHERE summaries_fts MATCH $1 AND s.status = 'succeeded' AND s.pdf_id IN (SELECT id FROM visible)
+		),
+		summary_hits AS (
+			SELECT * FROM (
+				SELECT m.*, ROW_NUMBER() OVER (PARTITION BY m.pdf_id ORDER BY m.rank DESC, m.created_at DESC) AS rn
+				FROM summary_matches m
+			) WHERE rn = 1
+		),
+		hits AS (
+			SELECT id FROM pdf_hits UNION SELECT pdf_id FROM summary_hits
+		)
+		SELECT p.id, COALESCE(p.original_filename, p.filename), p.workspace_id, p.created_at,
+		       COALESCE(p.text_language, ''),
+		       COALESCE(ph.rank, 0) + COALESCE(sh.rank, 0) AS score,
+		       COALESCE(ph.filename_highlight, p.original_filename, p.filename),
+		       CASE WHEN ph.id IS NOT NULL AND COALESCE(p.extracted_text, '') <> '' THEN COALESCE(ph.snippet, '') ELSE '' END,
+		       sh.summary_id,
+		       COALESCE(sh.snippet, ''),
+		       COALESCE(s.language_detected, ''),
+		       COUNT(*) OVER () AS total
+		FROM hits
+		JOIN pdf_files p ON p.id = hits.id
+		LEFT JOIN pdf_hits ph ON ph.id = p.id
+		LEFT JOIN summary_hits sh ON sh.pdf_id = p.id
+		LEFT JOIN summaries s ON s.id = sh.summary_id
+		ORDER BY score DESC, p.id DESC
+		LIMIT $4 OFFSET $5`,
+		filterSQL,
+	)
+}
+
+//for learn, FTS5 = full-text search bawaan SQLite, MATCH mirip @@ di Postgres tapi tanpa stemming
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+	"pdf-backend-fiber/internal/sharelink"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShareHandler struct {
+	DB        *sql.DB //cek akses + audit log
+	Config    config.Config
+	Signer    *sharelink.Signer
+	Pdf       *PdfHandler //untuk kirim file asli (scope file)
+	Shares    repository.ShareRepository
+	PDFs      repository.PdfRepository
+	Summaries repository.SummaryRepository
+}
+
+func NewShareHandler(db *sql.DB, cfg config.Config, pdfHandler *PdfHandler) *ShareHandler {
+	repos := repository.New(db, cfg.DBDriver)
+	return &ShareHandler{
+		DB:        db,
+		Config:    cfg,
+		Signer:    sharelink.NewSigner(cfg.ShareSecret),
+		Pdf:       pdfHandler,
+		Shares:    repos.Shares,
+		PDFs:      repos.PDFs,
+		Summaries: repos.Summaries,
+	}
+}
+
+// shareURL menyusun URL publik. Token bisa dibuat ulang dari baris share_links karena signature-nya deterministik.
+func (h *ShareHandler) shareURL(c *fiber.Ctx, linkID string, pdfID int, scope string, expiresAt time.Time) (string, string) {
+	token := h.Signer.Sign(linkID, pdfID, scope, expiresAt)
+	base := strings.TrimRight(h.Config.PublicBaseURL, "/")
+	if base == "" {
+		base = c.BaseURL()
+	}
+	return token, base + "/share/" + token
+}
+
+type createShareRequest struct {
+	Scope     string `json:"scope"`
+	ExpiresIn string `json:"expires_in"` //durasi Go, misal "24h", "90m"
+}
+
+// CreateShareLink membuat link read-only untuk satu PDF (scope: summary, history, file).
+func (h *ShareHandler) CreateShareLink(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+
+	var req createShareRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+	req.Scope = strings.ToLower(strings.TrimSpace(req.Scope))
+	if req.Scope == "" {
+		req.Scope = sharelink.ScopeSummary
+	}
+	if !sharelink.ValidScope(req.Scope) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid scope (available: summary, history, file)"})
+	}
+
+	ttl := h.Config.ShareLinkTTL
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid expires_in (contoh: 24h, 90m)"})
+		}
+		ttl = d
+	}
+	if ttl > h.Config.ShareLinkMaxTTL {
+		return c.Status(400).JSON(fiber.Map{
+			"error":               "expires_in melebihi batas maksimal",
+			"max_expires_in_secs": int64(h.Config.ShareLinkMaxTTL.Seconds()),
+		})
+	}
+
+	// bikin link = membuka akses ke luar workspace, minimal editor
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+
+	linkID, err := sharelink.NewLinkID()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create share link"})
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second) //token cuma simpan detik
+
+	createdAt, err := h.Shares.Create(c.UserContext(), linkID, pdfID, req.Scope, expiresAt)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save share link"})
+	}
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditShareCreate,
+		TargetType:  models.TargetShareLink,
+		TargetID:    linkID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"pdf_id": pdfID, "scope": req.Scope, "expires_at": expiresAt},
+	})
+
+	token, url := h.shareURL(c, linkID, pdfID, req.Scope, expiresAt)
+	return c.Status(201).JSON(fiber.Map{
+		"id":         linkID,
+		"pdf_id":     pdfID,
+		"scope":      req.Scope,
+		"token":      token,
+		"url":        url,
+		"expires_at": expiresAt.In(getJakartaLocation()),
+		"created_at": createdAt.In(getJakartaLocation()),
+	})
+}
+
+// ListShareLinks menampilkan semua link untuk satu PDF, termasuk yang sudah dicabut / kedaluwarsa.
+func (h *ShareHandler) ListShareLinks(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+
+	if _, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleViewer); !ok {
+		return err
+	}
+
+	rows, err := h.Shares.ListByPDF(c.UserContext(), pdfID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	jakartaLoc := getJakartaLocation()
+	now := time.Now()
+	links := []fiber.Map{}
+	for _, l := range rows {
+		status := "active"
+		if l.RevokedAt != nil {
+			status = "revoked"
+		} else if now.After(l.ExpiresAt) {
+			status = "expired"
+		}
+		link := fiber.Map{
+			"id":             l.LinkID,
+			"scope":          l.Scope,
+			"status":         status,
+			"expires_at":     l.ExpiresAt.In(jakartaLoc),
+			"view_count":     l.ViewCount,
+			"last_viewed_at": nil,
+			"revoked_at":     nil,
+			"created_at":     l.CreatedAt.In(jakartaLoc),
+		}
+		if l.LastViewedAt != nil {
+			link["last_viewed_at"] = l.LastViewedAt.In(jakartaLoc)
+		}
+		if l.RevokedAt != nil {
+			link["revoked_at"] = l.RevokedAt.In(jakartaLoc)
+		}
+		if status == "active" {
+			_, link["url"] = h.shareURL(c, l.LinkID, pdfID, l.Scope, l.ExpiresAt)
+		}
+		links = append(links, link)
+	}
+
+	return c.JSON(fiber.Map{"pdf_id": pdfID, "links": links, "count": len(links)})
+}
+
+// RevokeShareLink mencabut link (masuk revocation list, token langsung ditolak walau belum kedaluwarsa).
+func (h *ShareHandler) RevokeShareLink(c *fiber.Ctx) error {
+	linkID := strings.TrimSpace(c.Params("id"))
+
+	link, err := h.Shares.Get(c.UserContext(), linkID, false)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Share link not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	pdfID := link.PdfID
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+
+	// sudah dicabut = revoked_at lama dipertahankan
+	revokedAt, err := h.Shares.Revoke(c.UserContext(), linkID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Share link not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditShareRevoke,
+		TargetType:  models.TargetShareLink,
+		TargetID:    linkID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"pdf_id": pdfID, "revoked_at": revokedAt},
+	})
+
+	return c.JSON(fiber.Map{"success": true, "id": linkID, "revoked_at": revokedAt.In(getJakartaLocation())})
+}
+
+// ViewShareLink = endpoint publik /share/:token, tanpa auth. Isi yang dikirim tergantung scope link.
+func (h *ShareHandler) ViewShareLink(c *fiber.Ctx) error {
+	token, err := sharelink.Parse(c.Params("token"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Share link not found"})
+	}
+
+	// PDF di trash = link tidak berlaku (tapi hidup lagi kalau PDF di-restore)
+	link, err := h.Shares.Get(c.UserContext(), token.LinkID, true)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Share link not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	pdfID, scope := link.PdfID, link.Scope
+
+	// signature salah = diperlakukan sama dengan link yang tidak ada
+	if err := h.Signer.Verify(token, pdfID, scope); err != nil {
+		if err == sharelink.ErrExpired {
+			return c.Status(410).JSON(fiber.Map{"error": "Share link expired"})
+		}
+		return c.Status(404).JSON(fiber.Map{"error": "Share link not found"})
+	}
+	if link.RevokedAt != nil {
+		return c.Status(410).JSON(fiber.Map{"error": "Share link revoked"})
+	}
+
+	// request Range lanjutan dari viewer PDF tidak dihitung sebagai view baru
+	countView := true
+	if rh := c.Get(fiber.HeaderRange); rh != "" && !strings.HasPrefix(strings.TrimSpace(rh), "bytes=0-") {
+		countView = false
+	}
+	if countView {
+		if err := h.Shares.CountView(c.UserContext(), token.LinkID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("X-Robots-Tag", "noindex")
+
+	if scope == sharelink.ScopeFile {
+		return h.Pdf.sendPDFFile(c, pdfID)
+	}
+
+	jakartaLoc := getJakartaLocation()
+	pdf, err := h.PDFs.Get(c.UserContext(), pdfID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	all, err := h.Summaries.ListByPDF(c.UserContext(), pdfID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get summaries"})
+	}
+	summaries := []fiber.Map{}
+	for _, s := range all {
+		if s.Status != models.SummarySucceeded {
+			continue
+		}
+		summaries = append(summaries, fiber.Map{
+			"summary_text":      s.SummaryText,
+			"summary_style":     s.SummaryStyle,
+			"language_detected": s.LanguageDetected,
+			"created_at":        s.CreatedAt.In(jakartaLoc),
+		})
+		if scope == sharelink.ScopeSummary {
+			break //cukup yang terbaru
+		}
+	}
+
+	resp := fiber.Map{
+		"scope": scope,
+		"pdf": fiber.Map{
+			"original_filename": pdf.OriginalFilename,
+			"page_count":        pdf.PageCount,
+			"pdf_title":         pdf.PdfTitle,
+		},
+		"expires_at": token.ExpiresAt.In(jakartaLoc),
+	}
+	if scope == sharelink.ScopeSummary {
+		var latest interface{}
+		if len(summaries) > 0 {
+			latest = summaries[0]
+		}
+		resp["summary"] = latest
+	} else {
+		resp["summaries"] = summaries
+		resp["count"] = len(summaries)
+	}
+	return c.JSON(resp)
+}
+
+//for learn, share link: token = id.expired.signature, isi pdf/scope tetap diambil dari DB biar bisa dicabut
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxBulkTagPDFs = 500
+
+var tagColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// parsePDFFilter membaca ?tag_id=1,2&folder_id=5&recursive=true (folder_id=root untuk PDF tanpa folder).
+func parsePDFFilter(c *fiber.Ctx) (repository.PDFFilter, error) {
+	var f repository.PDFFilter
+	seen := map[int64]bool{}
+	for _, raw := range strings.Split(c.Query("tag_id"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return f, fmt.Errorf("invalid tag_id %q", raw)
+		}
+		if !seen[id] {
+			seen[id] = true
+			f.TagIDs = append(f.TagIDs, id)
+		}
+	}
+
+	switch raw := strings.TrimSpace(c.Query("folder_id")); raw {
+	case "":
+	case "root":
+		f.Root = true
+	default:
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return f, fmt.Errorf("invalid folder_id %q", raw)
+		}
+		f.FolderID = id
+		f.Recursive = c.QueryBool("recursive")
+	}
+	return f, nil
+}
+
+// loadPDFTags mengambil tag untuk banyak PDF sekaligus (satu query), key = pdf id.
+func loadPDFTags(db *sql.DB, pdfIDs []int) (map[int][]models.Tag, error) {
+	result, err := repository.NewTagRepository(db).ForPDFs(context.Background(), pdfIDs)
+	if err != nil {
+		return nil, err
+	}
+	jakartaLoc := getJakartaLocation()
+	for _, tags := range result {
+		for i := range tags {
+			tags[i].CreatedAt = tags[i].CreatedAt.In(jakartaLoc)
+		}
+	}
+	return result, nil
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+type TagHandler struct {
+	DB   *sql.DB //cek akses + audit log
+	Tags repository.TagRepository
+}
+
+func NewTagHandler(db *sql.DB) *TagHandler {
+	return &TagHandler{DB: db, Tags: repository.NewTagRepository(db)}
+}
+
+func validTagInput(name, color string) string {
+	if name == "" {
+		return "Name is required"
+	}
+	if len([]rune(name)) > 64 {
+		return "Name terlalu panjang (maks 64 karakter)"
+	}
+	if color != "" && !tagColorRegex.MatchString(color) {
+		return "Color harus format #RRGGBB"
+	}
+	return ""
+}
+
+// ListTags = tag di semua workspace user (atau satu workspace lewat ?workspace_id=) + jumlah PDF aktifnya.
+func (h *TagHandler) ListTags(c *fiber.Ctx) error {
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+	tags, err := h.Tags.ListForUser(c.UserContext(), currentUserID(c), workspaceID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	jakartaLoc := getJakartaLocation()
+	for i := range tags {
+		tags[i].CreatedAt = tags[i].CreatedAt.In(jakartaLoc)
+	}
+	return c.JSON(fiber.Map{"tags": tags, "count": len(tags)})
+}
+
+// CreateTag (minimal editor). workspace_id kosong = workspace pribadi.
+func (h *TagHandler) CreateTag(c *fiber.Ctx) error {
+	var req struct {
+		WorkspaceID int    `json:"workspace_id"`
+		Name        string `json:"name"`
+		Color       string `json:"color"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Color = strings.TrimSpace(req.Color)
+	if msg := validTagInput(req.Name, req.Color); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+	workspaceID, status, body := resolveUploadWorkspace(h.DB, req.WorkspaceID, currentUserID(c))
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	t := models.Tag{WorkspaceID: workspaceID, Name: req.Name, Color: req.Color}
+	if err := h.Tags.Create(c.UserContext(), &t, currentUserID(c)); err != nil {
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{"error": "Tag dengan nama ini sudah ada di workspace"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create tag"})
+	}
+	t.CreatedAt = t.CreatedAt.In(getJakartaLocation())
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditTagCreate,
+		TargetType:  models.TargetTag,
+		TargetID:    t.ID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"name": t.Name, "color": t.Color},
+	})
+	return c.Status(201).JSON(fiber.Map{"success": true, "tag": t})
+}
+
+// UpdateTag mengganti nama / warna tag (minimal editor). Field yang tidak dikirim tidak berubah.
+func (h *TagHandler) UpdateTag(c *fiber.Ctx) error {
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+	workspaceID, ok, err := requireItemRole(c, h.DB, "tags", tagID, models.RoleEditor, "Tag not found")
+	if !ok {
+		return err
+	}
+	var req struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+
+	old, err := h.Tags.Get(c.UserContext(), tagID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	name, color := old.Name, old.Color
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+	}
+	if req.Color != nil {
+		color = strings.TrimSpace(*req.Color)
+	}
+	if msg := validTagInput(name, color); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := h.Tags.Update(c.UserContext(), tagID, name, color); err != nil {
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{"error": "Tag dengan nama ini sudah ada di workspace"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Update failed"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditTagUpdate,
+		TargetType:  models.TargetTag,
+		TargetID:    tagID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": old.Name, "color": old.Color},
+		After:       fiber.Map{"name": name, "color": color},
+	})
+	return c.JSON(fiber.Map{"success": true, "tag_id": tagID, "name": name, "color": color})
+}
+
+// DeleteTag menghapus tag (minimal editor); PDF-nya tetap ada, cuma labelnya lepas.
+func (h *TagHandler) DeleteTag(c *fiber.Ctx) error {
+	tagID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+	workspaceID, ok, err := requireItemRole(c, h.DB, "tags", tagID, models.RoleEditor, "Tag not found")
+	if !ok {
+		return err
+	}
+
+	name, pdfCount, err := h.Tags.Delete(c.UserContext(), tagID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditTagDelete,
+		TargetType:  models.TargetTag,
+		TargetID:    tagID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": name, "pdf_count": pdfCount},
+	})
+	return c.JSON(fiber.Map{"success": true, "tag_id": tagID})
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := map[int64]bool{}
+	out := []int64{}
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// SetPDFTags mengganti semua tag satu PDF (minimal editor). Body {"tag_ids": [..]}, [] = lepas semua.
+func (h *TagHandler) SetPDFTags(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	var req struct {
+		TagIDs []int64 `json:"tag_ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	workspaceID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+	tagIDs := uniqueIDs(req.TagIDs)
+	// semua tag harus ada di workspace yang sama dengan PDF
+	if n, err := h.Tags.CountInWorkspace(c.UserContext(), workspaceID, tagIDs); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	} else if n != len(tagIDs) {
+		return c.Status(400).JSON(fiber.Map{"error": "Tag tidak ditemukan di workspace PDF ini"})
+	}
+
+	change, err := h.Tags.SetPDFTags(c.UserContext(), pdfID, tagIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update tags"})
+	}
+	added, removed := change.Added, change.Removed
+
+	if len(added) > 0 || len(removed) > 0 {
+		recordAudit(c, h.DB, auditEntry{
+			Action:      models.AuditPDFTag,
+			TargetType:  models.TargetPDF,
+			TargetID:    pdfID,
+			WorkspaceID: workspaceID,
+			After:       fiber.Map{"added": added, "removed": removed},
+		})
+	}
+
+	tags, err := loadPDFTags(h.DB, []int{pdfID})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	return c.JSON(fiber.Map{"success": true, "pdf_id": pdfID, "tags": append([]models.Tag{}, tags[pdfID]...)})
+}
+
+// BulkTag menambah / melepas tag di banyak PDF sekaligus.
+// Body {"pdf_ids": [..], "add": [tag id], "remove": [tag id]}. Tag cuma dipasang ke PDF di workspace yang sama;
+// PDF yang tidak bisa diubah user (bukan editor / tidak ada) dilaporkan di "skipped", sisanya tetap diproses.
+func (h *TagHandler) BulkTag(c *fiber.Ctx) error {
+	var req struct {
+		PDFIDs []int64 `json:"pdf_ids"`
+		Add    []int64 `json:"add"`
+		Remove []int64 `json:"remove"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	pdfIDs, add, remove := uniqueIDs(req.PDFIDs), uniqueIDs(req.Add), uniqueIDs(req.Remove)
+	if len(pdfIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "pdf_ids is required"})
+	}
+	if len(pdfIDs) > maxBulkTagPDFs {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Maksimal %d PDF per request", maxBulkTagPDFs)})
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "add atau remove wajib diisi"})
+	}
+
+	// tag harus ada di workspace yang bisa diakses user
+	allTags := uniqueIDs(append(append([]int64{}, add...), remove...))
+	known, err := h.Tags.CountForUser(c.UserContext(), currentUserID(c), allTags)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if known != len(allTags) {
+		return c.Status(400).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	userID := currentUserID(c)
+	allowed := []int64{}
+	workspaceOf := map[int]int{}
+	skipped := []fiber.Map{}
+	for _, id := range pdfIDs {
+		wsID, role, err := pdfRole(h.DB, int(id), userID, false)
+		switch {
+		case err == repository.ErrNotFound:
+			skipped = append(skipped, fiber.Map{"pdf_id": id, "error": "PDF not found"})
+		case err != nil:
+			return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		case !models.RoleAtLeast(role, models.RoleEditor):
+			skipped = append(skipped, fiber.Map{"pdf_id": id, "error": "Forbidden", "code": "INSUFFICIENT_ROLE"})
+		default:
+			allowed = append(allowed, id)
+			workspaceOf[int(id)] = wsID
+		}
+	}
+
+	changes, err := h.Tags.BulkUpdate(c.UserContext(), allowed, add, remove)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update tags"})
+	}
+
+	added, removed := 0, 0
+	for pdfID, ch := range changes {
+		added += len(ch.Added)
+		removed += len(ch.Removed)
+		recordAudit(c, h.DB, auditEntry{
+			Action:      models.AuditPDFTag,
+			TargetType:  models.TargetPDF,
+			TargetID:    pdfID,
+			WorkspaceID: workspaceOf[pdfID],
+			After:       fiber.Map{"added": ch.Added, "removed": ch.Removed, "bulk": true},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":      true,
+		"updated_pdfs": len(changes),
+		"added":        added,
+		"removed":      removed,
+		"skipped":      skipped,
+	})
+}
+
+//for learn, tag many-to-many: tabel pdf_tags cuma berisi pasangan (pdf_id, tag_id)
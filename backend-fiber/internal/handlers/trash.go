@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+	"pdf-backend-fiber/internal/trash"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListTrash = PDF yang sudah dihapus (soft delete) di workspace user, terbaru dulu.
+// purge_at = kapan PDF dihapus permanen oleh purger.
+func (h *PdfHandler) ListTrash(c *fiber.Ctx) error {
+	workspaceID, ok := requestedWorkspace(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT p.id, COALESCE(p.original_filename, p.filename), p.filesize, p.workspace_id,
+		       p.deleted_at, COALESCE(u.email, '')
+		FROM pdf_files p
+		LEFT JOIN users u ON u.id = p.deleted_by
+		WHERE p.deleted_at IS NOT NULL
+		  AND p.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND ($2 = 0 OR p.workspace_id = $2)
+		ORDER BY p.deleted_at DESC
+	`, currentUserID(c), workspaceID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	defer rows.Close()
+
+	jakartaLoc := getJakartaLocation()
+	items := []fiber.Map{}
+	for rows.Next() {
+		var id, pdfWorkspaceID int
+		var originalFilename, deletedBy string
+		var filesize int64
+		var deletedAt time.Time
+		if err := rows.Scan(&id, &originalFilename, &filesize, &pdfWorkspaceID, &deletedAt, &deletedBy); err != nil {
+			continue
+		}
+		items = append(items, fiber.Map{
+			"id":                id,
+			"original_filename": originalFilename,
+			"filesize":          filesize,
+			"workspace_id":      pdfWorkspaceID,
+			"deleted_at":        deletedAt.In(jakartaLoc),
+			"deleted_by":        deletedBy,
+			"purge_at":          deletedAt.Add(h.Config.TrashRetention).In(jakartaLoc),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"pdfs":           items,
+		"count":          len(items),
+		"retention_secs": int64(h.Config.TrashRetention.Seconds()),
+	})
+}
+
+// RestorePDF mengeluarkan PDF dari trash (minimal editor).
+func (h *PdfHandler) RestorePDF(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	workspaceID, ok, err := requireTrashedPDFRole(c, h.DB, pdfID, models.RoleEditor)
+	if !ok {
+		return err
+	}
+
+	deletedAt, err := h.PDFs.Restore(c.UserContext(), pdfID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF not found in trash"})
+		}
+		// isi file yang sama sudah diupload ulang selama PDF ini di trash
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "PDF dengan isi yang sama sudah ada di workspace ini, hapus salah satunya dulu",
+				"code":  "DUPLICATE_ACTIVE",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to restore PDF"})
+	}
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFRestore,
+		TargetType:  models.TargetPDF,
+		TargetID:    pdfID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"deleted_at": deletedAt},
+		After:       fiber.Map{"deleted_at": nil},
+	})
+
+	return c.JSON(fiber.Map{"success": true, "pdf_id": pdfID, "workspace_id": workspaceID, "message": "PDF restored"})
+}
+
+// PurgePDF = hapus permanen PDF yang sudah di trash tanpa menunggu masa retensi (owner).
+func (h *PdfHandler) PurgePDF(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	workspaceID, ok, err := requireTrashedPDFRole(c, h.DB, pdfID, models.RoleOwner)
+	if !ok {
+		return err
+	}
+
+	snapshot, err := trash.Purge(c.UserContext(), h.DB, h.Storage, pdfID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(404).JSON(fiber.Map{"error": "PDF not found in trash"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to purge PDF"})
+	}
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFPurge,
+		TargetType:  models.TargetPDF,
+		TargetID:    pdfID,
+		WorkspaceID: workspaceID,
+		Before:      snapshot,
+	})
+
+	return c.JSON(fiber.Map{"success": true, "pdf_id": pdfID, "message": "PDF deleted permanently"})
+}
+
+//for learn, trash: hapus = isi deleted_at, restore = kosongkan lagi, purge = baru benar-benar DELETE
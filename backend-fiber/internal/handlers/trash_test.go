@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+)
+
+// listIDs mengambil id dari array JSON body[key] (field idKey tiap item).
+func listIDs(body map[string]interface{}, key, idKey string) []int {
+	items, _ := body[key].([]interface{})
+	var ids []int
+	for _, it := range items {
+		m, _ := it.(map[string]interface{})
+		ids = append(ids, bodyInt(m, idKey))
+	}
+	return ids
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// storedPDF = dbtest.PDF + file-nya di storage lokal (key = name).
+func storedPDF(t *testing.T, env *handlerEnv, workspaceID int, name string) int {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(env.dir, name), samplePDF(t, name), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dbtest.PDF(t, env.db, workspaceID, env.userID, name)
+}
+
+func TestTrash(t *testing.T) {
+	env := newHandlerEnv(t)
+	editor, _ := dbtest.User(t, env.db, "editor@test.id")
+	viewer, _ := dbtest.User(t, env.db, "viewer@test.id")
+	team := sharedWorkspace(t, env.db, "Tim", map[string]int{"owner": env.userID, "editor": editor, "viewer": viewer})
+	users := map[string]int{"owner": env.userID, "editor": editor, "viewer": viewer}
+
+	kontrak := storedPDF(t, env, team, "kontrak.pdf")
+	other := storedPDF(t, env, team, "memo.pdf")
+	ws := "?workspace_id=" + strconv.Itoa(team)
+
+	// di mana kontrak.pdf kelihatan: listing, search, trash, detail
+	where := func() string {
+		var got []string
+		if _, body := env.sendJSON(t, "GET", "/pdfs"+ws, nil); containsID(listIDs(body, "pdfs", "id"), kontrak) {
+			got = append(got, "list")
+		}
+		if _, body := env.sendJSON(t, "GET", "/search"+ws+"&q=kontrak", nil); containsID(listIDs(body, "results", "pdf_id"), kontrak) {
+			got = append(got, "search")
+		}
+		if _, body := env.sendJSON(t, "GET", "/trash"+ws, nil); containsID(listIDs(body, "pdfs", "id"), kontrak) {
+			got = append(got, "trash")
+		}
+		if code, _ := env.sendJSON(t, "GET", "/pdf/"+strconv.Itoa(kontrak), nil); code == 200 {
+			got = append(got, "detail")
+		}
+		return strings.Join(got, ",")
+	}
+	if got := where(); got != "list,search,detail" {
+		t.Fatalf("before delete: kontrak visible in %q", got)
+	}
+
+	id := strconv.Itoa(kontrak)
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		user      string
+		wantCode  int
+		wantWhere string //"" = tidak dicek
+	}{
+		{"viewer tidak bisa hapus", "DELETE", "/pdf/" + id, "viewer", 403, ""},
+		{"editor tidak bisa hapus", "DELETE", "/pdf/" + id, "editor", 403, ""},
+		{"owner hapus ke trash", "DELETE", "/pdf/" + id, "owner", 200, "trash"},
+		{"hapus lagi = 404", "DELETE", "/pdf/" + id, "owner", 404, ""},
+		{"purge PDF yang tidak di trash", "DELETE", "/trash/" + strconv.Itoa(other), "owner", 404, ""},
+		{"viewer tidak bisa restore", "POST", "/pdf/" + id + "/restore", "viewer", 403, ""},
+		{"editor restore", "POST", "/pdf/" + id + "/restore", "editor", 200, "list,search,detail"},
+		{"restore PDF aktif = 404", "POST", "/pdf/" + id + "/restore", "owner", 404, ""},
+		{"hapus lagi", "DELETE", "/pdf/" + id, "owner", 200, "trash"},
+		{"editor tidak bisa purge", "DELETE", "/trash/" + id, "editor", 403, ""},
+		{"owner purge", "DELETE", "/trash/" + id, "owner", 200, ""},
+		{"purge lagi = 404", "DELETE", "/trash/" + id, "owner", 404, ""},
+	}
+	for _, tt := range tests {
+		code, body := env.sendJSONAs(t, tt.method, tt.path, users[tt.user], nil)
+		if code != tt.wantCode {
+			t.Fatalf("%s: status = %d, want %d (body %v)", tt.name, code, tt.wantCode, body)
+		}
+		if tt.wantWhere != "" {
+			if got := where(); got != tt.wantWhere {
+				t.Fatalf("%s: kontrak visible in %q, want %q", tt.name, got, tt.wantWhere)
+			}
+		}
+	}
+
+	// purge menghapus baris + file di storage, PDF lain tidak ikut
+	var n int
+	if err := env.db.QueryRow(`SELECT COUNT(*) FROM pdf_files WHERE id = $1`, kontrak).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("pdf row after purge = %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(env.dir, "kontrak.pdf")); !os.IsNotExist(err) {
+		t.Errorf("file still in storage after purge: %v", err)
+	}
+	if code, _ := env.sendJSON(t, "GET", "/pdf/"+strconv.Itoa(other), nil); code != 200 {
+		t.Errorf("other PDF status = %d", code)
+	}
+}
+
+// restore ditolak kalau isi yang sama sudah diupload ulang selama PDF-nya di trash,
+// dan purge tidak menghapus file yang masih dipakai baris lain.
+func TestTrashDuplicate(t *testing.T) {
+	env := newHandlerEnv(t)
+	old := storedPDF(t, env, env.workspaceID, "a.pdf")
+	if code, body := env.sendJSON(t, "DELETE", "/pdf/"+strconv.Itoa(old), nil); code != 200 {
+		t.Fatalf("delete: status = %d (body %v)", code, body)
+	}
+	reupload := dbtest.PDF(t, env.db, env.workspaceID, env.userID, "a.pdf") //sha + filepath sama
+
+	code, body := env.sendJSON(t, "POST", "/pdf/"+strconv.Itoa(old)+"/restore", nil)
+	if code != 409 || body["code"] != "DUPLICATE_ACTIVE" {
+		t.Fatalf("restore: status = %d (body %v)", code, body)
+	}
+	if code, body := env.sendJSON(t, "DELETE", "/trash/"+strconv.Itoa(old), nil); code != 200 {
+		t.Fatalf("purge: status = %d (body %v)", code, body)
+	}
+	if _, err := env.store.Stat(context.Background(), "a.pdf"); err != nil {
+		t.Errorf("shared file deleted by purge: %v", err)
+	}
+	if code, _ := env.sendJSON(t, "GET", "/pdf/"+strconv.Itoa(reupload), nil); code != 200 {
+		t.Errorf("re-uploaded PDF status = %d", code)
+	}
+}
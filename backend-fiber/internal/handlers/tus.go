@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Server tus 1.0 (https://tus.io/protocols/resumable-upload) di /files/.
+// Extension: creation, checksum, termination, expiration.
+// Session-nya pakai folder yang sama dengan chunk upload biasa (.chunks/<upload_id>):
+// meta.json + satu file 00000000.part yang di-append setiap PATCH, jadi janitor & finalizeUpload bisa dipakai ulang.
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,checksum,termination,expiration"
+	tusChecksums  = "sha1,sha256,md5"
+
+	statusChecksumMismatch = 460 //status khusus dari extension checksum tus
+)
+
+// tusLocks = satu mutex per upload_id, supaya dua PATCH ke upload yang sama tidak nulis barengan.
+var tusLocks sync.Map
+
+func lockUpload(uploadID string) func() {
+	m, _ := tusLocks.LoadOrStore(uploadID, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (h *UploadHandler) tusResultPath(uploadID string) string {
+	return filepath.Join(h.uploadDir(uploadID), "result.json")
+}
+
+func (h *UploadHandler) setTusHeaders(c *fiber.Ctx) {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Cache-Control", "no-store")
+}
+
+// TusMiddleware mengecek header Tus-Resumable (wajib di semua request kecuali OPTIONS).
+func (h *UploadHandler) TusMiddleware(c *fiber.Ctx) error {
+	h.setTusHeaders(c)
+	if c.Method() == fiber.MethodOptions {
+		return c.Next()
+	}
+	if c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return c.Status(412).JSON(fiber.Map{"error": "Unsupported Tus-Resumable version"})
+	}
+	return c.Next()
+}
+
+// TusOptions = discovery: versi, extension, batas ukuran dan algoritma checksum yang didukung.
+func (h *UploadHandler) TusOptions(c *fiber.Ctx) error {
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(h.Config.MaxFileSize, 10))
+	c.Set("Tus-Checksum-Algorithm", tusChecksums)
+	return c.SendStatus(204)
+}
+
+// parseTusMetadata membaca Upload-Metadata: "key base64value,key2 base64value2" (value boleh kosong).
+func parseTusMetadata(header string) (map[string]string, bool) {
+	meta := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return meta, true
+	}
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, false
+		}
+		value := ""
+		if len(parts) == 2 {
+			b, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, false
+			}
+			value = string(b)
+		}
+		meta[parts[0]] = value
+	}
+	return meta, true
+}
+
+// TusCreate (extension creation): POST /files/ dengan Upload-Length + Upload-Metadata.
+// Metadata yang dibaca: filename (atau name), style, provider, no_cache, force, sha256 (checksum seluruh file, hex),
+// workspace_id (kosong = workspace pribadi).
+func (h *UploadHandler) TusCreate(c *fiber.Ctx) error {
+	if c.Get("Upload-Defer-Length") != "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Upload-Defer-Length is not supported"})
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Length"})
+	}
+	if length > h.Config.MaxFileSize {
+		return c.Status(413).JSON(fiber.Map{"error": "File terlalu besar (maks 10MB)"})
+	}
+
+	md, ok := parseTusMetadata(c.Get("Upload-Metadata"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Metadata"})
+	}
+	filename := md["filename"]
+	if filename == "" {
+		filename = md["name"]
+	}
+	filename = filepath.Base(filename)
+	if filename == "" || filename == "." || strings.ToLower(filepath.Ext(filename)) != ".pdf" {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya file PDF yang diizinkan"})
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(md["provider"]))
+	if provider != "" {
+		if _, err := h.Queue.Summarizers.Get(provider); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	fileSum, ok := normalizeSHA256(md["sha256"])
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "sha256 harus 64 karakter hex", "code": "INVALID_CHECKSUM"})
+	}
+
+	workspaceID := 0
+	if raw := strings.TrimSpace(md["workspace_id"]); raw != "" {
+		workspaceID, err = strconv.Atoi(raw)
+		if err != nil || workspaceID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid workspace_id"})
+		}
+	}
+	workspaceID, status, body := resolveUploadWorkspace(h.DB, workspaceID, currentUserID(c))
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	uploadID := uuid.NewString()
+	if err := os.MkdirAll(h.uploadDir(uploadID), os.ModePerm); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to init upload"})
+	}
+
+	// seluruh file = satu "chunk", jadi finalizeUpload tinggal merge 1 part
+	meta := uploadMeta{
+		OriginalFilename: filename,
+		FileSize:         length,
+		ChunkSize:        length,
+		TotalChunks:      1,
+		Style:            normalizeStyle(md["style"]),
+		Provider:         provider,
+		NoCache:          md["no_cache"] == "true",
+		Force:            md["force"] == "true",
+		FileSHA256:       fileSum,
+		Tus:              true,
+		UserID:           currentUserID(c),
+		WorkspaceID:      workspaceID,
+		CreatedAtUnix:    time.Now().Unix(),
+	}
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to init upload"})
+	}
+	if err := os.WriteFile(h.uploadMetaPath(uploadID), b, 0644); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to init upload"})
+	}
+	if err := os.WriteFile(h.chunkPath(uploadID, 0), nil, 0644); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to init upload"})
+	}
+
+	c.Set("Location", c.BaseURL()+"/files/"+uploadID)
+	c.Set("Upload-Expires", h.tusExpiry(time.Now()).Format(http.TimeFormat))
+	return c.SendStatus(201)
+}
+
+func (h *UploadHandler) tusExpiry(lastActivity time.Time) time.Time {
+	return lastActivity.Add(h.Config.UploadSessionTTL).UTC()
+}
+
+// loadTusUpload membaca meta session tus + offset sekarang (= ukuran file part).
+// Session yang sudah lewat TTL dihapus dan dianggap hilang (410), session milik user lain dianggap tidak ada (404).
+func (h *UploadHandler) loadTusUpload(uploadID string, userID int) (uploadMeta, int64, time.Time, int) {
+	var meta uploadMeta
+	if uploadID == "" || uploadID != filepath.Base(uploadID) {
+		return meta, 0, time.Time{}, 404
+	}
+	b, err := os.ReadFile(h.uploadMetaPath(uploadID))
+	if err != nil {
+		return meta, 0, time.Time{}, 404
+	}
+	if err := json.Unmarshal(b, &meta); err != nil || !meta.Tus || meta.UserID != userID {
+		return meta, 0, time.Time{}, 404
+	}
+
+	var offset int64
+	lastActivity := time.Unix(meta.CreatedAtUnix, 0)
+	if st, err := os.Stat(h.chunkPath(uploadID, 0)); err == nil {
+		offset = st.Size()
+		if st.ModTime().After(lastActivity) {
+			lastActivity = st.ModTime()
+		}
+	} else if st, err := os.Stat(h.tusResultPath(uploadID)); err == nil {
+		offset = meta.FileSize //sudah selesai, part-nya sudah dipindah ke UploadDir
+		lastActivity = st.ModTime()
+	} else {
+		return meta, 0, time.Time{}, 404
+	}
+
+	expires := h.tusExpiry(lastActivity)
+	if time.Now().After(expires) {
+		_ = os.RemoveAll(h.uploadDir(uploadID))
+		return meta, 0, time.Time{}, 410
+	}
+	return meta, offset, expires, 0
+}
+
+// setTusResultHeaders menambahkan hasil upload yang sudah selesai (pdf_id, job_id) ke header respons.
+func (h *UploadHandler) setTusResultHeaders(c *fiber.Ctx, uploadID string) {
+	b, err := os.ReadFile(h.tusResultPath(uploadID))
+	if err != nil {
+		return
+	}
+	var result struct {
+		PdfID     int  `json:"pdf_id"`
+		JobID     int  `json:"job_id"`
+		Duplicate bool `json:"duplicate"`
+	}
+	if json.Unmarshal(b, &result) != nil {
+		return
+	}
+	c.Set("X-PDF-ID", strconv.Itoa(result.PdfID))
+	if result.JobID > 0 {
+		c.Set("X-Job-ID", strconv.Itoa(result.JobID))
+	}
+	c.Set("X-Duplicate", strconv.FormatBool(result.Duplicate))
+}
+
+// TusHead = cek offset upload (dipakai client untuk resume).
+func (h *UploadHandler) TusHead(c *fiber.Ctx) error {
+	uploadID := c.Params("id")
+	meta, offset, expires, status := h.loadTusUpload(uploadID, currentUserID(c))
+	if status != 0 {
+		return c.SendStatus(status)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(meta.FileSize, 10))
+	c.Set("Upload-Expires", expires.Format(http.TimeFormat))
+	h.setTusResultHeaders(c, uploadID)
+	return c.SendStatus(200)
+}
+
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// TusPatch menambahkan byte ke upload mulai dari Upload-Offset.
+// Kalau ada Upload-Checksum dan tidak cocok, byte dari request ini dibuang (460).
+// Begitu offset == Upload-Length, pipeline yang sama dengan /upload/complete langsung dijalankan.
+func (h *UploadHandler) TusPatch(c *fiber.Ctx) error {
+	if c.Get("Content-Type") != "application/offset+octet-stream" {
+		return c.Status(415).JSON(fiber.Map{"error": "Content-Type must be application/offset+octet-stream"})
+	}
+	clientOffset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || clientOffset < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Offset"})
+	}
+
+	var checksum hash.Hash
+	var expectedSum []byte
+	if header := strings.TrimSpace(c.Get("Upload-Checksum")); header != "" {
+		parts := strings.Fields(header)
+		if len(parts) != 2 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Checksum"})
+		}
+		checksum = newChecksumHash(parts[0])
+		if checksum == nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Unsupported checksum algorithm"})
+		}
+		expectedSum, err = base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Checksum"})
+		}
+	}
+
+	uploadID := c.Params("id")
+	unlock := lockUpload(uploadID)
+	defer unlock()
+
+	meta, offset, _, status := h.loadTusUpload(uploadID, currentUserID(c))
+	if status != 0 {
+		return c.SendStatus(status)
+	}
+	if _, err := os.Stat(h.tusResultPath(uploadID)); err == nil {
+		c.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		h.setTusResultHeaders(c, uploadID)
+		return c.SendStatus(204) //sudah selesai sebelumnya
+	}
+	if clientOffset != offset {
+		return c.Status(409).JSON(fiber.Map{"error": "Upload-Offset mismatch", "offset": offset})
+	}
+
+	body := c.Body()
+	if offset+int64(len(body)) > meta.FileSize {
+		return c.Status(413).JSON(fiber.Map{"error": "Upload melebihi Upload-Length"})
+	}
+
+	partPath := h.chunkPath(uploadID, 0)
+	f, err := os.OpenFile(partPath, os.O_WRONLY, 0644)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save upload"})
+	}
+	var w io.Writer = f
+	if checksum != nil {
+		w = io.MultiWriter(f, checksum)
+	}
+	_, writeErr := f.Seek(offset, io.SeekStart)
+	if writeErr == nil {
+		_, writeErr = w.Write(body)
+	}
+	if writeErr == nil {
+		writeErr = f.Sync()
+	}
+	if writeErr == nil && checksum != nil && string(checksum.Sum(nil)) != string(expectedSum) {
+		_ = f.Truncate(offset) //buang byte dari PATCH ini
+		_ = f.Close()
+		return c.Status(statusChecksumMismatch).JSON(fiber.Map{"error": "Checksum Mismatch", "code": "CHUNK_CHECKSUM_MISMATCH"})
+	}
+	if writeErr != nil {
+		_ = f.Truncate(offset)
+		_ = f.Close()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save upload"})
+	}
+	if err := f.Close(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save upload"})
+	}
+
+	newOffset := offset + int64(len(body))
+	c.Set("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	c.Set("Upload-Expires", h.tusExpiry(time.Now()).Format(http.TimeFormat))
+	if newOffset < meta.FileSize {
+		return c.SendStatus(204)
+	}
+
+	// upload lengkap: jalankan pipeline yang sama dengan /upload/complete
+	status, result := h.finalizeUpload(uploadID, meta, meta.Force)
+	if status >= 300 {
+		return c.Status(status).JSON(result)
+	}
+	if status == 202 {
+		h.auditUpload(c, meta, result)
+	}
+
+	// finalizeUpload menghapus folder session; simpan lagi meta + hasilnya biar HEAD setelah selesai tetap bisa dijawab
+	// (dibersihkan janitor setelah TTL)
+	if err := os.MkdirAll(h.uploadDir(uploadID), os.ModePerm); err == nil {
+		if b, err := json.Marshal(meta); err == nil {
+			_ = os.WriteFile(h.uploadMetaPath(uploadID), b, 0644)
+		}
+		if b, err := json.Marshal(result); err == nil {
+			_ = os.WriteFile(h.tusResultPath(uploadID), b, 0644)
+		}
+	}
+	h.setTusResultHeaders(c, uploadID)
+	return c.SendStatus(204)
+}
+
+// TusDelete (extension termination): batalkan upload dan hapus semua byte-nya.
+func (h *UploadHandler) TusDelete(c *fiber.Ctx) error {
+	uploadID := c.Params("id")
+	unlock := lockUpload(uploadID)
+	defer unlock()
+
+	if _, _, _, status := h.loadTusUpload(uploadID, currentUserID(c)); status != 0 {
+		return c.SendStatus(status)
+	}
+	if err := os.RemoveAll(h.uploadDir(uploadID)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete upload"})
+	}
+	tusLocks.Delete(uploadID)
+	return c.SendStatus(204)
+}
+
+//for learn, ini protokol upload standar (tus) biar client lain (app mobile, script python) ga perlu pakai /upload/* kita
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+)
+
+func TestParseTusMetadata(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		header string
+		want   map[string]string
+		ok     bool
+	}{
+		{"kosong", "", map[string]string{}, true},
+		{"satu key", "filename " + b64("a.pdf"), map[string]string{"filename": "a.pdf"}, true},
+		{"beberapa key + value kosong", "filename " + b64("a b.pdf") + ",no_cache,style " + b64("bullets"),
+			map[string]string{"filename": "a b.pdf", "no_cache": "", "style": "bullets"}, true},
+		{"base64 rusak", "filename ###", nil, false},
+		{"terlalu banyak bagian", "filename a b", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTusMetadata(tt.header)
+			if ok != tt.ok || (ok && len(got) != len(tt.want)) {
+				t.Fatalf("got %v, %v", got, ok)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+// tusRequest membuat request tus (header Tus-Resumable sudah diisi). headers berpasangan: nama, nilai.
+func tusRequest(method, target string, body []byte, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req.Header.Set("Tus-Resumable", tusVersion)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func TestTusUpload(t *testing.T) {
+	env := newHandlerEnv(t)
+	otherUser, _ := dbtest.User(t, env.db, "b@test.id")
+	data := samplePDF(t, "")
+	half := len(data) / 2
+
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("laporan.pdf"))
+	resp, body := env.send(t, tusRequest("POST", "/files/", nil, "Upload-Length", strconv.Itoa(len(data)), "Upload-Metadata", metadata), env.userID)
+	if resp.StatusCode != 201 {
+		t.Fatalf("create status = %d (body %v)", resp.StatusCode, body)
+	}
+	location := resp.Header.Get("Location")
+	uploadID := location[strings.LastIndex(location, "/")+1:]
+	path := "/files/" + uploadID
+
+	patch := func(offset int, chunk []byte, headers ...string) *http.Request {
+		h := append([]string{"Content-Type", "application/offset+octet-stream", "Upload-Offset", strconv.Itoa(offset)}, headers...)
+		return tusRequest("PATCH", path, chunk, h...)
+	}
+	sha1Sum := func(b []byte) string {
+		sum := sha1.Sum(b)
+		return "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+	}
+
+	// langkah berurutan di session yang sama: upload setengah, "putus", HEAD, lanjut dari offset server
+	tests := []struct {
+		name       string
+		req        *http.Request
+		userID     int
+		wantCode   int
+		wantOffset string //header Upload-Offset ("" = tidak dicek)
+	}{
+		{"tanpa Tus-Resumable", httptest.NewRequest("HEAD", path, nil), env.userID, 412, ""},
+		{"HEAD awal", tusRequest("HEAD", path, nil), env.userID, 200, "0"},
+		{"HEAD user lain", tusRequest("HEAD", path, nil), otherUser, 404, ""},
+		{"PATCH setengah", patch(0, data[:half]), env.userID, 204, strconv.Itoa(half)},
+		{"HEAD untuk resume", tusRequest("HEAD", path, nil), env.userID, 200, strconv.Itoa(half)},
+		{"PATCH offset lama", patch(0, data[:half]), env.userID, 409, ""},
+		{"PATCH offset kelewatan", patch(half+10, data[half:]), env.userID, 409, ""},
+		{"PATCH content-type salah", tusRequest("PATCH", path, data[half:], "Upload-Offset", strconv.Itoa(half)), env.userID, 415, ""},
+		{"PATCH melebihi Upload-Length", patch(half, append(append([]byte{}, data[half:]...), 'x')), env.userID, 413, ""},
+		{"PATCH checksum salah", patch(half, data[half:], "Upload-Checksum", sha1Sum([]byte("lain"))), env.userID, 460, ""},
+		{"offset tidak maju setelah checksum salah", tusRequest("HEAD", path, nil), env.userID, 200, strconv.Itoa(half)},
+		{"PATCH sisa dengan checksum", patch(half, data[half:], "Upload-Checksum", sha1Sum(data[half:])), env.userID, 204, strconv.Itoa(len(data))},
+		{"HEAD setelah selesai", tusRequest("HEAD", path, nil), env.userID, 200, strconv.Itoa(len(data))},
+		{"PATCH ulang setelah selesai", patch(len(data), nil), env.userID, 204, strconv.Itoa(len(data))},
+	}
+	for _, tt := range tests {
+		resp, body := env.send(t, tt.req, tt.userID)
+		if resp.StatusCode != tt.wantCode {
+			t.Fatalf("%s: status = %d, want %d (body %v)", tt.name, resp.StatusCode, tt.wantCode, body)
+		}
+		if tt.wantOffset != "" && resp.Header.Get("Upload-Offset") != tt.wantOffset {
+			t.Fatalf("%s: Upload-Offset = %q, want %s", tt.name, resp.Header.Get("Upload-Offset"), tt.wantOffset)
+		}
+	}
+
+	// hasil upload ikut di header, PDF-nya masuk workspace pribadi dengan isi utuh
+	resp, _ = env.send(t, tusRequest("HEAD", path, nil), env.userID)
+	pdfID, _ := strconv.Atoi(resp.Header.Get("X-PDF-ID"))
+	if pdfID == 0 || resp.Header.Get("X-Job-ID") == "" || resp.Header.Get("X-Duplicate") != "false" {
+		t.Fatalf("result headers = %v", resp.Header)
+	}
+	var sum string
+	var ws int
+	if err := env.db.QueryRow(`SELECT content_sha256, workspace_id FROM pdf_files WHERE id = $1`, pdfID).Scan(&sum, &ws); err != nil {
+		t.Fatal(err)
+	}
+	if sum != sha256Hex(data) || ws != env.workspaceID {
+		t.Errorf("stored pdf sha=%s workspace=%d", sum, ws)
+	}
+
+	// upload kedua isi sama = duplikat, DELETE membatalkan session yang belum selesai
+	resp, _ = env.send(t, tusRequest("POST", "/files/", nil, "Upload-Length", strconv.Itoa(len(data)), "Upload-Metadata", metadata), env.userID)
+	path = "/files/" + resp.Header.Get("Location")[strings.LastIndex(resp.Header.Get("Location"), "/")+1:]
+	if resp, _ := env.send(t, patch(0, data), env.userID); resp.StatusCode != 204 || resp.Header.Get("X-Duplicate") != "true" ||
+		resp.Header.Get("X-PDF-ID") != strconv.Itoa(pdfID) {
+		t.Fatalf("duplicate upload: status = %d headers = %v", resp.StatusCode, resp.Header)
+	}
+
+	resp, _ = env.send(t, tusRequest("POST", "/files/", nil, "Upload-Length", strconv.Itoa(len(data)), "Upload-Metadata", metadata), env.userID)
+	path = "/files/" + resp.Header.Get("Location")[strings.LastIndex(resp.Header.Get("Location"), "/")+1:]
+	if resp, _ := env.send(t, patch(0, data[:half]), env.userID); resp.StatusCode != 204 {
+		t.Fatalf("patch before delete: status = %d", resp.StatusCode)
+	}
+	if resp, _ := env.send(t, tusRequest("DELETE", path, nil), env.userID); resp.StatusCode != 204 {
+		t.Fatalf("delete: status = %d", resp.StatusCode)
+	}
+	if resp, _ := env.send(t, tusRequest("HEAD", path, nil), env.userID); resp.StatusCode != 404 {
+		t.Fatalf("HEAD after delete: status = %d", resp.StatusCode)
+	}
+}
+
+func TestTusCreateValidation(t *testing.T) {
+	env := newHandlerEnv(t)
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name     string
+		length   string
+		metadata string
+		wantCode int
+	}{
+		{"valid", "100", "filename " + b64("a.pdf"), 201},
+		{"pakai key name", "100", "name " + b64("a.pdf"), 201},
+		{"bukan pdf", "100", "filename " + b64("a.txt"), 400},
+		{"tanpa Upload-Length", "", "filename " + b64("a.pdf"), 400},
+		{"terlalu besar", strconv.Itoa(20 << 20), "filename " + b64("a.pdf"), 413},
+		{"sha256 tidak valid", "100", "filename " + b64("a.pdf") + ",sha256 " + b64("abc"), 400},
+		{"workspace bukan anggota", "100", "filename " + b64("a.pdf") + ",workspace_id " + b64("999"), 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := env.send(t, tusRequest("POST", "/files/", nil, "Upload-Length", tt.length, "Upload-Metadata", tt.metadata), env.userID)
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %v)", resp.StatusCode, tt.wantCode, body)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json" //persing response pyhton
 	"fmt"           //format nama file d pesan
 	"io"
@@ -10,22 +13,30 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time" //bikin namafile unik katanya
 
 	"pdf-backend-fiber/internal/config" //disini dia utk max size d folder upload
+	"pdf-backend-fiber/internal/jobs"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/pdfinfo"
+	"pdf-backend-fiber/internal/repository"
 	"pdf-backend-fiber/internal/services"
+	"pdf-backend-fiber/internal/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type UploadHandler struct {
-	DB     *sql.DB
-	Config config.Config
-	Python *services.PythonClient
+	DB      *sql.DB
+	Config  config.Config
+	Queue   *jobs.Queue
+	Storage storage.Storage
+	PDFs    repository.PdfRepository
 }
 
 func isValidPDFFileHeader(fileHeaderBytes []byte) bool { //cek apakah file pdf valid, nanti dipake nanti di completechunkupload
@@ -33,11 +44,13 @@ func isValidPDFFileHeader(fileHeaderBytes []byte) bool { //cek apakah file pdf v
 	return bytes.HasPrefix(trimmed, []byte("%PDF-")) //header file harus diawali "%PDF-"
 } // Dia TrimLeft dulu (buang byte kosong/spasi/enter) lalu cek prefix %PDF-.
 
-func NewUploadHandler(db *sql.DB, cfg config.Config) *UploadHandler { //nah ini buat handler sekali alu diupload di routes
+func NewUploadHandler(db *sql.DB, cfg config.Config, queue *jobs.Queue, store storage.Storage) *UploadHandler { //nah ini buat handler sekali alu diupload di routes
 	return &UploadHandler{
-		DB:     db,
-		Config: cfg,
-		Python: services.NewPythonClient(cfg.PythonAPI),
+		DB:      db,
+		Config:  cfg,
+		Queue:   queue,
+		Storage: store,
+		PDFs:    repository.New(db, cfg.DBDriver).PDFs,
 	}
 }
 
@@ -47,6 +60,13 @@ type uploadMeta struct { //tanpa ini pengecekan biasanya akan salah
 	ChunkSize        int64  `json:"chunk_size"`
 	TotalChunks      int    `json:"total_chunks"`
 	Style            string `json:"style"`
+	Provider         string `json:"provider,omitempty"` //kosong = summarizer default
+	NoCache          bool   `json:"no_cache,omitempty"`
+	FileSHA256       string `json:"file_sha256,omitempty"` //opsional, dicek waktu complete
+	Force            bool   `json:"force,omitempty"`       //tus: simpan walaupun isi file sudah ada (sama dengan ?force=true)
+	Tus              bool   `json:"tus,omitempty"`         //session dibuat lewat /files/ (tus), seluruh file ada di chunk 0
+	UserID           int    `json:"user_id"`               //pemilik session; user lain dapat 404
+	WorkspaceID      int    `json:"workspace_id"`          //tujuan PDF, dicek lagi waktu complete
 	CreatedAtUnix    int64  `json:"created_at_unix"`
 }
 
@@ -73,20 +93,32 @@ func (h *UploadHandler) expectedChunkSize(meta uploadMeta, chunkIndex int) (int6
 	return remain, nil //jika sisa byte lebih kecil dari chunk size, maka return sisa byte
 }
 
+var sha256HexRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// normalizeSHA256 merapikan checksum dari client (huruf kecil, tanpa spasi). "" = tidak dikirim.
+func normalizeSHA256(sum string) (string, bool) {
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if sum == "" {
+		return "", true
+	}
+	return sum, sha256HexRegex.MatchString(sum)
+}
+
 // atomicWriteMultipartFile menyimpan file upload secara atomic:
 // tulis dulu ke file .tmp lalu rename ke nama final.
 // Tujuan utamanya agar kalau koneksi putus di tengah upload, file .part tidak pernah tersimpan setengah.
-func atomicWriteMultipartFile(fileHeader *multipart.FileHeader, finalPath string) (int64, error) {
+// SHA-256 isi yang ditulis ikut dikembalikan buat verifikasi checksum chunk.
+func atomicWriteMultipartFile(fileHeader *multipart.FileHeader, finalPath string) (int64, string, error) {
 	f, err := fileHeader.Open() //buka file
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 	defer f.Close()
 
 	dir := filepath.Dir(finalPath)
 	tmp, err := os.CreateTemp(dir, ".chunk-*.tmp")
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 	tmpName := tmp.Name() 
 
@@ -97,26 +129,27 @@ func atomicWriteMultipartFile(fileHeader *multipart.FileHeader, finalPath string
 		return nil
 	}
 
-	n, copyErr := io.Copy(tmp, f) //copy file ke tmp
+	hasher := sha256.New()
+	n, copyErr := io.Copy(io.MultiWriter(tmp, hasher), f) //copy file ke tmp sambil hitung hash
 	if copyErr != nil {
 		_ = tmp.Close()
 		_ = os.Remove(tmpName)
-		return 0, copyErr
+		return 0, "", copyErr
 	}
 	if err := tmp.Sync(); err != nil { //pakai sync untuk memastikan data tersimpan ke disk
 		_ = tmp.Close()
 		_ = os.Remove(tmpName)
-		return 0, err
+		return 0, "", err
 	}
 	if err := closeErr(); err != nil {
 		_ = os.Remove(tmpName)
-		return 0, err
+		return 0, "", err
 	}
 	if err := os.Rename(tmpName, finalPath); err != nil {
 		_ = os.Remove(tmpName)
-		return 0, err
+		return 0, "", err
 	}
-	return n, nil
+	return n, hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 func (h *UploadHandler) chunkRootDir() string { //folder tempat menyimpan chunk
@@ -135,6 +168,20 @@ func (h *UploadHandler) chunkPath(uploadID string, chunkIndex int) string {
 	return filepath.Join(h.uploadDir(uploadID), fmt.Sprintf("%08d.part", chunkIndex))
 } //biar file urut rapi dan gampang merge 0..N tanpa sorting aneh.
 
+// chunkSumPath = file sidecar berisi SHA-256 chunk yang sudah lolos verifikasi checksum dari client.
+func (h *UploadHandler) chunkSumPath(uploadID string, chunkIndex int) string {
+	return filepath.Join(h.uploadDir(uploadID), fmt.Sprintf("%08d.sha256", chunkIndex))
+}
+
+// verifiedChunkSum membaca checksum sidecar chunk; "" kalau chunk belum pernah diverifikasi.
+func (h *UploadHandler) verifiedChunkSum(uploadID string, chunkIndex int) string {
+	b, err := os.ReadFile(h.chunkSumPath(uploadID, chunkIndex))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
 func normalizeStyle(style string) string {
 	style = strings.ToLower(strings.TrimSpace(style))
 	switch style {
@@ -148,7 +195,7 @@ func normalizeStyle(style string) string {
 // InitChunkUpload membuat "upload session" untuk chunk upload.
 // Di sini server:
 // - validasi file (hanya .pdf, size <= MaxFileSize)
-// - normalisasi style
+// - normalisasi style + cek provider summarizer (kalau dipilih)
 // - membuat folder .chunks/<upload_id>
 // - menulis meta.json sebagai sumber kebenaran (berapa total chunk, ukuran file, dll)
 func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
@@ -158,7 +205,11 @@ func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
 		ChunkSize        int64  `json:"chunk_size"`
 		TotalChunks      int    `json:"total_chunks"`
 		Style            string `json:"style"`
+		Provider         string `json:"provider"`
+		NoCache          bool   `json:"no_cache"` //true = jangan pakai summary_cache, generate ulang
+		FileSHA256       string `json:"file_sha256"` //opsional, SHA-256 seluruh file (hex)
 		UploadID         string `json:"upload_id"`
+		WorkspaceID      int    `json:"workspace_id"` //kosong = workspace pribadi
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -179,10 +230,38 @@ func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
 
 	style := normalizeStyle(req.Style)
 
+	fileSum, ok := normalizeSHA256(req.FileSHA256)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "file_sha256 harus 64 karakter hex", "code": "INVALID_CHECKSUM"})
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	if provider != "" {
+		if _, err := h.Queue.Summarizers.Get(provider); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	workspaceID, status, body := resolveUploadWorkspace(h.DB, req.WorkspaceID, currentUserID(c))
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
 	uploadID := strings.TrimSpace(req.UploadID) //kirim upload id
 	if uploadID == "" {
 		uploadID = uuid.NewString() //lek gaada buat id baru
 	}
+	if uploadID != filepath.Base(uploadID) || strings.HasPrefix(uploadID, ".") {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid upload_id"})
+	}
+
+	// upload_id dari client boleh dipakai ulang (re-init), tapi tidak boleh menimpa session milik user lain
+	if b, err := os.ReadFile(h.uploadMetaPath(uploadID)); err == nil {
+		var existing uploadMeta
+		if json.Unmarshal(b, &existing) == nil && existing.UserID != currentUserID(c) {
+			return c.Status(409).JSON(fiber.Map{"error": "upload_id sudah dipakai"})
+		}
+	}
 
 	if err := os.MkdirAll(h.chunkRootDir(), os.ModePerm); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to init upload"})
@@ -197,6 +276,11 @@ func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
 		ChunkSize:        req.ChunkSize,
 		TotalChunks:      req.TotalChunks,
 		Style:            style,
+		Provider:         provider,
+		NoCache:          req.NoCache,
+		FileSHA256:       fileSum,
+		UserID:           currentUserID(c),
+		WorkspaceID:      workspaceID,
 		CreatedAtUnix:    time.Now().Unix(),
 	}
 
@@ -213,6 +297,9 @@ func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
 		"chunk_size":   meta.ChunkSize,
 		"total_chunks": meta.TotalChunks,
 		"style":        meta.Style,
+		"provider":     meta.Provider,
+		"file_sha256":  meta.FileSHA256,
+		"workspace_id": meta.WorkspaceID,
 		"success":      true,
 	})
 }
@@ -223,6 +310,7 @@ func (h *UploadHandler) InitChunkUpload(c *fiber.Ctx) error {
 // - chunk_index = urutan chunk
 // - idempotent: kalau chunk sudah ada & ukurannya benar => sudah sukses (retry aman)
 // - atomic write: chunk tidak akan tersimpan setengah
+// - checksum (opsional): header X-Chunk-SHA256, chunk yang isinya beda ditolak dengan code CHUNK_CHECKSUM_MISMATCH
 func (h *UploadHandler) UploadChunk(c *fiber.Ctx) error {
 	uploadID := strings.TrimSpace(c.FormValue("upload_id"))
 	chunkIndexStr := strings.TrimSpace(c.FormValue("chunk_index"))
@@ -245,11 +333,19 @@ func (h *UploadHandler) UploadChunk(c *fiber.Ctx) error {
 	if err := json.Unmarshal(metaBytes, &meta); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Invalid upload session"})
 	}
+	if meta.UserID != currentUserID(c) {
+		return c.Status(404).JSON(fiber.Map{"error": "Upload session not found"})
+	}
 
 	if chunkIndex >= meta.TotalChunks {
 		return c.Status(400).JSON(fiber.Map{"error": "chunk_index out of range"})
 	}
 
+	expectedSum, ok := normalizeSHA256(c.Get("X-Chunk-SHA256"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "X-Chunk-SHA256 harus 64 karakter hex", "code": "INVALID_CHECKSUM"})
+	}
+
 	// hitung ukuran chunk yang seharusnya, supaya bisa deteksi chunk korup/setengah
 	expectedSize, err := h.expectedChunkSize(meta, chunkIndex)
 	if err != nil {
@@ -267,28 +363,53 @@ func (h *UploadHandler) UploadChunk(c *fiber.Ctx) error {
 
 	// path final chunk: .chunks/<upload_id>/<chunk_index>.part
 	chunkPath := h.chunkPath(uploadID, chunkIndex)
+	sumPath := h.chunkSumPath(uploadID, chunkIndex)
 	if st, err := os.Stat(chunkPath); err == nil {
 		if st.Size() == expectedSize {
-			return c.JSON(fiber.Map{"success": true, "upload_id": uploadID, "chunk_index": chunkIndex, "already_uploaded": true})
+			if expectedSum == "" {
+				return c.JSON(fiber.Map{"success": true, "upload_id": uploadID, "chunk_index": chunkIndex, "already_uploaded": true})
+			}
+			// retry dengan checksum: chunk lama cuma dianggap beres kalau isinya sama
+			if actual, err := services.HashFile(chunkPath); err == nil && actual == expectedSum {
+				_ = os.WriteFile(sumPath, []byte(actual), 0644)
+				return c.JSON(fiber.Map{"success": true, "upload_id": uploadID, "chunk_index": chunkIndex, "already_uploaded": true, "verified": true})
+			}
 		}
-		// kalau file sudah ada tapi ukurannya tidak sesuai, berarti korup/setengah -> hapus lalu upload ulang
+		// kalau file sudah ada tapi ukuran / isinya tidak sesuai, berarti korup/setengah -> hapus lalu upload ulang
 		_ = os.Remove(chunkPath)
 	}
+	_ = os.Remove(sumPath)
 
-	written, err := atomicWriteMultipartFile(file, chunkPath)
+	written, actualSum, err := atomicWriteMultipartFile(file, chunkPath)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to save chunk"})
 	}
 	if written != expectedSize {
 		_ = os.Remove(chunkPath)
-		return c.Status(400).JSON(fiber.Map{"error": "Chunk size mismatch"})
+		return c.Status(400).JSON(fiber.Map{"error": "Chunk size mismatch", "code": "CHUNK_SIZE_MISMATCH"})
+	}
+	if expectedSum != "" {
+		if actualSum != expectedSum {
+			_ = os.Remove(chunkPath)
+			return c.Status(422).JSON(fiber.Map{
+				"error":       "Chunk checksum mismatch",
+				"code":        "CHUNK_CHECKSUM_MISMATCH",
+				"chunk_index": chunkIndex,
+				"expected":    expectedSum,
+				"actual":      actualSum,
+			})
+		}
+		if err := os.WriteFile(sumPath, []byte(actualSum), 0644); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to save chunk"})
+		}
 	}
 
-	return c.JSON(fiber.Map{"success": true, "upload_id": uploadID, "chunk_index": chunkIndex})
+	return c.JSON(fiber.Map{"success": true, "upload_id": uploadID, "chunk_index": chunkIndex, "sha256": actualSum, "verified": expectedSum != ""})
 }
 
 // UploadStatus dipakai untuk resume.
-// Server mengembalikan daftar chunk_index yang sudah diterima (file *.part yang ada di folder upload).
+// Server mengembalikan daftar chunk_index yang sudah diterima (file *.part yang ada di folder upload),
+// plus chunk yang lolos verifikasi checksum (verified) dan yang isinya sudah tidak cocok lagi (corrupted).
 func (h *UploadHandler) UploadStatus(c *fiber.Ctx) error {
 	uploadID := strings.TrimSpace(c.Query("upload_id"))
 	if uploadID == "" {
@@ -304,6 +425,9 @@ func (h *UploadHandler) UploadStatus(c *fiber.Ctx) error {
 	if err := json.Unmarshal(metaBytes, &meta); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Invalid upload session"})
 	}
+	if meta.UserID != currentUserID(c) {
+		return c.Status(404).JSON(fiber.Map{"error": "Upload session not found"})
+	}
 
 	entries, err := os.ReadDir(h.uploadDir(uploadID))
 	if err != nil {
@@ -325,10 +449,28 @@ func (h *UploadHandler) UploadStatus(c *fiber.Ctx) error {
 	}
 	sort.Ints(received)
 
+	// hash ulang chunk yang punya sidecar, jadi chunk yang rusak di disk juga ketahuan
+	verified := make([]int, 0, len(received))
+	corrupted := make([]int, 0)
+	for _, idx := range received {
+		expected := h.verifiedChunkSum(uploadID, idx)
+		if expected == "" {
+			continue
+		}
+		if actual, err := services.HashFile(h.chunkPath(uploadID, idx)); err == nil && actual == expected {
+			verified = append(verified, idx)
+		} else {
+			corrupted = append(corrupted, idx)
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"success":       true,
 		"upload_id":     uploadID,
 		"received":      received,
+		"verified":      verified,
+		"corrupted":     corrupted,
+		"file_sha256":   meta.FileSHA256,
 		"total_chunks":  meta.TotalChunks,
 		"chunk_size":    meta.ChunkSize,
 		"file_size":     meta.FileSize,
@@ -339,9 +481,10 @@ func (h *UploadHandler) UploadStatus(c *fiber.Ctx) error {
 
 // CompleteChunkUpload menyelesaikan upload chunk:
 // - validasi semua chunk 0..N-1 sudah ada
-// - gabungkan semua chunk berurutan ke file final di UploadDir
+// - gabungkan semua chunk berurutan ke file sementara di folder session
 // - validasi hasil merge (magic bytes %PDF- dan size harus sama)
-// - simpan metadata ke DB + panggil Python summarizer
+// - inspeksi PDF di Go (halaman, versi, metadata); PDF terenkripsi / tanpa teks ditolak
+// - simpan file ke storage (lokal / S3), metadata ke DB + masukkan job summary ke antrian (dikerjakan worker, bukan di request ini)
 // - bersihkan folder chunk supaya hemat storage
 func (h *UploadHandler) CompleteChunkUpload(c *fiber.Ctx) error {
 	var req struct {
@@ -364,48 +507,108 @@ func (h *UploadHandler) CompleteChunkUpload(c *fiber.Ctx) error {
 	if err := json.Unmarshal(metaBytes, &meta); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Invalid upload session"})
 	}
+	if meta.UserID != currentUserID(c) {
+		return c.Status(404).JSON(fiber.Map{"error": "Upload session not found"})
+	}
+
+	status, result := h.finalizeUpload(uploadID, meta, c.QueryBool("force", false))
+	if status == 202 {
+		h.auditUpload(c, meta, result)
+	}
+	return c.Status(status).JSON(result)
+}
+
+// auditUpload mencatat PDF baru yang tersimpan (upload duplikat yang tidak disimpan tidak dicatat).
+func (h *UploadHandler) auditUpload(c *fiber.Ctx, meta uploadMeta, result fiber.Map) {
+	workspaceID, _ := result["workspace_id"].(int)
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditPDFUpload,
+		TargetType:  models.TargetPDF,
+		TargetID:    result["pdf_id"],
+		WorkspaceID: workspaceID,
+		After: fiber.Map{
+			"original_filename": meta.OriginalFilename,
+			"filesize":          meta.FileSize,
+			"content_sha256":    result["content_sha256"],
+			"job_id":            result["job_id"],
+			"style":             meta.Style,
+			"provider":          meta.Provider,
+			"duplicate_of":      result["duplicate_of"],
+			"tus":               meta.Tus,
+		},
+	})
+}
+
+// finalizeUpload = pipeline setelah semua byte file ada di folder session (.chunks/<upload_id>):
+// merge, validasi, dedup, simpan DB, antre summary. Dipakai CompleteChunkUpload dan upload tus (/files/).
+// Mengembalikan status HTTP + body JSON.
+func (h *UploadHandler) finalizeUpload(uploadID string, meta uploadMeta, force bool) (int, fiber.Map) {
+	// role bisa saja dicabut di tengah upload
+	workspaceID, status, body := resolveUploadWorkspace(h.DB, meta.WorkspaceID, meta.UserID)
+	if status != 0 {
+		return status, body
+	}
+	meta.WorkspaceID = workspaceID
 
 	for i := 0; i < meta.TotalChunks; i++ {
 		if _, err := os.Stat(h.chunkPath(uploadID, i)); err != nil {
 			if os.IsNotExist(err) {
-				return c.Status(409).JSON(fiber.Map{"error": "Chunks incomplete", "missing_chunk": i})
+				return 409, fiber.Map{"error": "Chunks incomplete", "missing_chunk": i}
 			}
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to validate chunks"})
+			return 500, fiber.Map{"error": "Failed to validate chunks"}
 		}
 	}
 
-	if err := os.MkdirAll(h.Config.UploadDir, os.ModePerm); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan file"})
-	}
-	filename := fmt.Sprintf("%d_%s", time.Now().Unix(), meta.OriginalFilename)
-	savePath := filepath.Join(h.Config.UploadDir, filename)
+	// file digabung dulu di folder session, baru dipindah ke storage (lokal / S3) setelah lolos validasi
+	// sekaligus jadi key storage; upload_id ikut supaya dua upload nama sama di detik yang sama tidak saling menimpa
+	filename := fmt.Sprintf("%d_%s_%s", time.Now().Unix(), uploadID, filepath.Base(meta.OriginalFilename))
+	savePath := filepath.Join(h.uploadDir(uploadID), "assembled.pdf")
 
 	dst, err := os.OpenFile(savePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan file"})
+		return 500, fiber.Map{"error": "Gagal menyimpan file"}
 	}
 	defer dst.Close()
 
+	// hash dihitung sambil menggabung chunk, jadi file tidak perlu dibaca dua kali
+	hasher := sha256.New()
+	out := io.MultiWriter(dst, hasher)
+
+	var badChunks []int
 	for i := 0; i < meta.TotalChunks; i++ {
 		p := h.chunkPath(uploadID, i)
 		src, err := os.Open(p)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to read chunk"})
+			return 500, fiber.Map{"error": "Failed to read chunk"}
 		}
-		_, copyErr := io.Copy(dst, src)
+		chunkHasher := sha256.New()
+		_, copyErr := io.Copy(io.MultiWriter(out, chunkHasher), src)
 		_ = src.Close()
 		if copyErr != nil {
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to assemble file"})
+			return 500, fiber.Map{"error": "Failed to assemble file"}
+		}
+		// chunk yang dulu lolos checksum tapi sekarang isinya beda (rusak di disk)
+		if expected := h.verifiedChunkSum(uploadID, i); expected != "" && expected != hex.EncodeToString(chunkHasher.Sum(nil)) {
+			badChunks = append(badChunks, i)
 		}
 	}
+	if len(badChunks) > 0 {
+		_ = dst.Close()
+		_ = os.Remove(savePath)
+		for _, i := range badChunks {
+			_ = os.Remove(h.chunkPath(uploadID, i))
+			_ = os.Remove(h.chunkSumPath(uploadID, i))
+		}
+		return 409, fiber.Map{"error": "Chunk checksum mismatch", "code": "CHUNK_CHECKSUM_MISMATCH", "bad_chunks": badChunks}
+	}
 
 	if err := dst.Sync(); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to finalize file"})
+		return 500, fiber.Map{"error": "Failed to finalize file"}
 	}
 
 	assembled, err := os.Open(savePath)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal membaca file upload"})
+		return 500, fiber.Map{"error": "Gagal membaca file upload"}
 	}
 	defer assembled.Close()
 
@@ -413,84 +616,157 @@ func (h *UploadHandler) CompleteChunkUpload(c *fiber.Ctx) error {
 	n, _ := io.ReadFull(assembled, header)
 	if n <= 0 || !isValidPDFFileHeader(header[:n]) {
 		_ = os.Remove(savePath)
-		return c.Status(400).JSON(fiber.Map{"error": "File bukan PDF valid (signature tidak sesuai)"})
+		return 400, fiber.Map{"error": "File bukan PDF valid (signature tidak sesuai)"}
 	}
 
 	fi, err := assembled.Stat()
 	if err != nil {
 		_ = os.Remove(savePath)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to stat file"})
+		return 500, fiber.Map{"error": "Failed to stat file"}
 	}
 	if fi.Size() != meta.FileSize {
 		_ = os.Remove(savePath)
-		return c.Status(400).JSON(fiber.Map{"error": "File size mismatch"})
+		return 400, fiber.Map{"error": "File size mismatch"}
 	}
 	if fi.Size() > h.Config.MaxFileSize {
 		_ = os.Remove(savePath)
-		return c.Status(400).JSON(fiber.Map{"error": "File terlalu besar (maks 10MB)"})
+		return 400, fiber.Map{"error": "File terlalu besar (maks 10MB)"}
 	}
 
-	var pdfID int
-	err = h.DB.QueryRow(
-		`INSERT INTO pdf_files (filename, original_filename, filepath, filesize, upload_time)
-		 VALUES ($1, $2, $3, $4, NOW()) RETURNING id`,
-		filename,
-		meta.OriginalFilename,
-		savePath,
-		fi.Size(),
-	).Scan(&pdfID)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal simpan metadata PDF"})
+	contentHash := hex.EncodeToString(hasher.Sum(nil))
+	if meta.FileSHA256 != "" && meta.FileSHA256 != contentHash {
+		_ = os.Remove(savePath)
+		return 422, fiber.Map{
+			"error":    "File checksum mismatch",
+			"code":     "FILE_CHECKSUM_MISMATCH",
+			"expected": meta.FileSHA256,
+			"actual":   contentHash,
+		}
 	}
 
-	summaryResult, duration, err := h.Python.Summarize(savePath, meta.Style)
-	summaryText := ""
-	language := "unknown"
+	// dedup: isi file yang sama tidak disimpan & diringkas ulang, kecuali ?force=true
+	ctx := context.Background()
+	existing, err := h.PDFs.FindByContentHash(ctx, contentHash, meta.WorkspaceID)
+	if err != nil {
+		_ = os.Remove(savePath)
+		return 500, fiber.Map{"error": "Database error"}
+	}
+	if existing != nil && !force {
+		_ = os.Remove(savePath)
+		_ = os.RemoveAll(h.uploadDir(uploadID))
+		return 200, duplicateResponse(existing, contentHash)
+	}
 
+	// parse PDF di Go: tolak yang terenkripsi / tanpa teks sebelum bayar panggilan AI.
+	// Parser Go tidak kenal semua PDF (misal xref rusak yang masih dibuka viewer / python), jadi gagal parse
+	// = metadata tidak diketahui (NULL), bukan alasan menolak; yang ditolak cuma yang pasti terenkripsi / tanpa teks
+	var pageCount *int
+	var textLanguage string
+	info, err := pdfinfo.Inspect(savePath)
 	if err != nil {
-		log.Printf("Python service failed: %v, using fallback", err)
-		summaryText = "Ringkasan tidak tersedia - Python service sedang maintenance"
-		duration = 0
+		log.Printf("PDF %q could not be parsed, storing without metadata: %v", meta.OriginalFilename, err)
+		info = &pdfinfo.Info{}
 	} else {
-		var response struct {
-			Summary  string `json:"summary"`
-			Language string `json:"detected_language"`
-			Style    string `json:"style"`
+		if err := info.Validate(); err != nil {
+			_ = os.Remove(savePath)
+			return 422, fiber.Map{"error": err.Error(), "encrypted": info.Encrypted, "has_text": info.HasText}
 		}
-		if err := json.Unmarshal([]byte(summaryResult), &response); err == nil {
-			summaryText = response.Summary
-			language = response.Language
-		} else {
-			summaryText = summaryResult
+		pageCount = &info.PageCount
+		textLanguage = services.DetectLanguage(info.Text)
+	}
+
+	// simpan ke storage; pdf_files.filepath isinya key storage, bukan path lokal
+	if _, err := assembled.Seek(0, io.SeekStart); err != nil {
+		_ = os.Remove(savePath)
+		return 500, fiber.Map{"error": "Gagal membaca file upload"}
+	}
+	if err := h.Storage.Put(ctx, filename, assembled, fi.Size(), "application/pdf"); err != nil {
+		_ = os.Remove(savePath)
+		return 500, fiber.Map{"error": "Gagal menyimpan file"}
+	}
+
+	pdfID, err := h.PDFs.Create(ctx, &models.PdfFile{
+		Filename:         filename,
+		OriginalFilename: meta.OriginalFilename,
+		Filepath:         filename,
+		Filesize:         fi.Size(),
+		PageCount:        pageCount,
+		PdfVersion:       info.Version,
+		PdfTitle:         info.Title,
+		PdfAuthor:        info.Author,
+		PdfSubject:       info.Subject,
+		PdfCreatedAt:     info.CreationDate,
+		IsEncrypted:      info.Encrypted,
+		ContentSHA256:    contentHash,
+		AllowDuplicate:   existing != nil, //force: simpan sebagai salinan terpisah, tidak ikut unique index
+		UserID:           &meta.UserID,    //uploader
+		WorkspaceID:      meta.WorkspaceID,
+		ExtractedText:    pdfinfo.SearchText(info.Text), //untuk GET /search
+		TextLanguage:     textLanguage,
+	})
+	if err != nil {
+		_ = os.Remove(savePath)
+		_ = h.Storage.Delete(ctx, filename)
+		// upload isi yang sama barengan: yang kalah race dapat unique violation, anggap duplikat
+		if err == repository.ErrDuplicate {
+			if existing, _ := h.PDFs.FindByContentHash(ctx, contentHash, meta.WorkspaceID); existing != nil {
+				_ = os.RemoveAll(h.uploadDir(uploadID))
+				return 200, duplicateResponse(existing, contentHash)
+			}
 		}
+		return 500, fiber.Map{"error": "Gagal simpan metadata PDF"}
 	}
 
-	_, err = h.DB.Exec(
-		`INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, language_detected)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		pdfID,
-		summaryText,
-		meta.Style,
-		duration,
-		language,
-	)
+	jobID, _, err := h.Queue.Enqueue(pdfID, meta.Style, meta.Provider, meta.NoCache, models.StageChunksAssembled, models.StagePDFValidated)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal simpan summary"})
+		// jangan tinggalkan PDF tanpa ringkasan: baris ini bakal dianggap "existing" oleh dedup waktu client upload ulang
+		log.Printf("Failed to enqueue summary job for PDF %d, rolling back upload: %v", pdfID, err)
+		if err := h.PDFs.Delete(ctx, pdfID); err != nil {
+			log.Printf("Failed to delete PDF %d after enqueue failure: %v", pdfID, err)
+		}
+		_ = h.Storage.Delete(ctx, filename)
+		_ = os.Remove(savePath)
+		return 500, fiber.Map{"error": "Gagal membuat job summary"}
 	}
 
 	_ = os.RemoveAll(h.uploadDir(uploadID))
 
-	return c.JSON(fiber.Map{
+	// summary dikerjakan worker di background, client cek status pakai job_id
+	return 202, fiber.Map{
 		"pdf_id":            pdfID,
+		"job_id":            jobID,
+		"status":            models.JobQueued,
 		"filename":          filename,
 		"original_filename": meta.OriginalFilename,
 		"style":             meta.Style,
-		"summary":           summaryText,
-		"language":          language,
-		"process_time_ms":   duration,
+		"provider":          meta.Provider,
+		"page_count":        pageCount,
+		"content_sha256":    contentHash,
+		"workspace_id":      meta.WorkspaceID,
+		"duplicate":         false,
+		"duplicate_of":      duplicateOf(existing),
 		"success":           true,
-	})
+	}
+}
+
+func duplicateResponse(existing *models.PdfFile, contentHash string) fiber.Map {
+	return fiber.Map{
+		"pdf_id":            existing.ID,
+		"duplicate":         true,
+		"original_filename": existing.OriginalFilename,
+		"summary":           existing.LatestSummary,
+		"content_sha256":    contentHash,
+		"message":           "PDF dengan isi yang sama sudah pernah diupload, pakai ?force=true untuk menyimpan salinan baru",
+		"success":           true,
+	}
+}
+
+func duplicateOf(existing *models.PdfFile) interface{} {
+	if existing == nil {
+		return nil
+	}
+	return existing.ID
 }
 
-//for learn this is for upload pdf and auto ai summarizer.
+//for learn this is for upload pdf, summarizer AI-nya jalan di background lewat internal/jobs.
 //handlers itu pokok penghubung anatara user d be, mengtur request d response
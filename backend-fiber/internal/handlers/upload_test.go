@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+	"pdf-backend-fiber/internal/jobs"
+	"pdf-backend-fiber/internal/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// handlerEnv = handler upload / PDF / search di atas SQLite + storage lokal sementara.
+// Queue tidak di-Start, job cuma masuk antrian.
+type handlerEnv struct {
+	app         *fiber.App
+	db          *sql.DB
+	store       storage.Storage
+	dir         string //UploadDir, session chunk ada di dir/.chunks/<upload_id>
+	userID      int
+	workspaceID int //workspace pribadi userID
+}
+
+func newHandlerEnv(t *testing.T) *handlerEnv {
+	t.Helper()
+	db := dbtest.Open(t)
+	userID, workspaceID := dbtest.User(t, db, "a@test.id")
+
+	dir := t.TempDir()
+	cfg := dbtest.Config(dir)
+	cfg.UploadDir = dir
+	cfg.MaxFileSize = 10 << 20
+	cfg.UploadSessionTTL = time.Hour
+	cfg.Summarizer = "extractive"
+	cfg.TrashRetention = 30 * 24 * time.Hour
+	store := storage.NewLocal(dir)
+	queue := jobs.NewQueue(db, cfg, store)
+	h := NewUploadHandler(db, cfg, queue, store)
+	pdfs := NewPdfHandler(db, cfg, queue, store)
+
+	app := testApp()
+	app.Post("/upload/init", h.InitChunkUpload)
+	app.Post("/upload/chunk", h.UploadChunk)
+	app.Get("/upload/status", h.UploadStatus)
+	app.Post("/upload/complete", h.CompleteChunkUpload)
+	files := app.Group("/files", h.TusMiddleware)
+	files.Post("/", h.TusCreate)
+	files.Head("/:id", h.TusHead)
+	files.Patch("/:id", h.TusPatch)
+	files.Delete("/:id", h.TusDelete)
+	app.Get("/pdf/:id", pdfs.GetPDF)
+	app.Delete("/pdf/:id", pdfs.DeletePDF)
+	app.Post("/pdf/:id/restore", pdfs.RestorePDF)
+	app.Get("/trash", pdfs.ListTrash)
+	app.Delete("/trash/:id", pdfs.PurgePDF)
+	app.Get("/pdfs", pdfs.ListPDFs)
+	app.Post("/pdf/:id/retry", pdfs.RetrySummary)
+	app.Get("/search", NewSearchHandler(db, cfg.DBDriver).Search)
+	return &handlerEnv{app: app, db: db, store: store, dir: dir, userID: userID, workspaceID: workspaceID}
+}
+
+// send menjalankan request sebagai userID. Body JSON di-decode ke map (nil kalau bukan JSON).
+func (e *handlerEnv) send(t *testing.T, req *http.Request, userID int) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req.Header.Set("X-Test-User", strconv.Itoa(userID))
+	resp, err := e.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(b, &body)
+	return resp, body
+}
+
+func (e *handlerEnv) sendJSON(t *testing.T, method, target string, payload interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	return e.sendJSONAs(t, method, target, e.userID, payload)
+}
+
+func (e *handlerEnv) sendJSONAs(t *testing.T, method, target string, userID int, payload interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	b, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, target, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, body := e.send(t, req, userID)
+	return resp.StatusCode, body
+}
+
+func (e *handlerEnv) initUpload(t *testing.T, data []byte, chunkSize int, workspaceID int, fileSum string) string {
+	t.Helper()
+	code, body := e.sendJSON(t, "POST", "/upload/init", fiber.Map{
+		"original_filename": "laporan.pdf",
+		"file_size":         len(data),
+		"chunk_size":        chunkSize,
+		"total_chunks":      (len(data) + chunkSize - 1) / chunkSize,
+		"file_sha256":       fileSum,
+		"workspace_id":      workspaceID,
+	})
+	if code != 200 {
+		t.Fatalf("init status = %d (body %v)", code, body)
+	}
+	return body["upload_id"].(string)
+}
+
+// sendChunk mengirim chunk ke-i. sum = header X-Chunk-SHA256 ("" = tidak dikirim).
+func (e *handlerEnv) sendChunk(t *testing.T, uploadID string, i int, chunk []byte, sum string) (int, map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("upload_id", uploadID)
+	_ = w.WriteField("chunk_index", strconv.Itoa(i))
+	part, _ := w.CreateFormFile("chunk", "chunk")
+	_, _ = part.Write(chunk)
+	_ = w.Close()
+
+	req := httptest.NewRequest("POST", "/upload/chunk", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if sum != "" {
+		req.Header.Set("X-Chunk-SHA256", sum)
+	}
+	resp, body := e.send(t, req, e.userID)
+	return resp.StatusCode, body
+}
+
+func (e *handlerEnv) complete(t *testing.T, uploadID string, force bool) (int, map[string]interface{}) {
+	t.Helper()
+	return e.sendJSON(t, "POST", "/upload/complete?force="+strconv.FormatBool(force), fiber.Map{"upload_id": uploadID})
+}
+
+// upload = init + semua chunk + complete dalam chunk 1KB.
+func (e *handlerEnv) upload(t *testing.T, data []byte, workspaceID int, force bool) (int, map[string]interface{}) {
+	t.Helper()
+	const chunkSize = 1024
+	uploadID := e.initUpload(t, data, chunkSize, workspaceID, "")
+	for i := 0; i*chunkSize < len(data); i++ {
+		end := (i + 1) * chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if code, body := e.sendChunk(t, uploadID, i, data[i*chunkSize:end], ""); code != 200 {
+			t.Fatalf("chunk %d status = %d (body %v)", i, code, body)
+		}
+	}
+	return e.complete(t, uploadID, force)
+}
+
+// samplePDF = PDF kecil berteks dari testdata pdfinfo. variant != "" ditambah sebagai komentar di akhir file,
+// jadi isinya (dan SHA-256-nya) beda tapi tetap PDF yang valid.
+func samplePDF(t *testing.T, variant string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("../pdfinfo/testdata/sample.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if variant != "" {
+		b = append(b, []byte("%"+variant+"\n")...)
+	}
+	return b
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func bodyInt(body map[string]interface{}, key string) int {
+	v, _ := body[key].(float64)
+	return int(v)
+}
+
+func TestUploadDedup(t *testing.T) {
+	env := newHandlerEnv(t)
+	otherUser, _ := dbtest.User(t, env.db, "b@test.id")
+	team := sharedWorkspace(t, env.db, "Tim", map[string]int{"owner": otherUser, "editor": env.userID})
+
+	// langkah dijalankan berurutan di DB yang sama; pdf menyimpan pdf_id hasil langkah sebelumnya per nama
+	pdfs := map[string]int{}
+	tests := []struct {
+		name          string
+		variant       string
+		team          bool //upload ke workspace tim, bukan workspace pribadi
+		force         bool
+		trash         string //pindahkan PDF langkah ini ke trash dulu
+		wantCode      int
+		wantDuplicate string //nama langkah yang jadi duplikatnya
+	}{
+		{"upload pertama", "", false, false, "", 202, ""},
+		{"isi sama di workspace sama", "", false, false, "", 200, "upload pertama"},
+		{"isi beda", "v2", false, false, "", 202, ""},
+		{"isi sama di workspace lain", "", true, false, "", 202, ""},
+		{"isi sama di workspace lain lagi", "", true, false, "", 200, "isi sama di workspace lain"},
+		{"force menyimpan salinan", "", false, true, "", 202, ""},
+		{"setelah force tetap menunjuk yang asli", "", false, false, "", 200, "upload pertama"},
+		{"force kedua juga boleh", "", false, true, "", 202, ""},
+		{"isi beda di trash tidak dihitung", "v2", false, false, "isi beda", 202, ""},
+	}
+	for _, tt := range tests {
+		if tt.trash != "" {
+			if _, err := env.db.Exec(`UPDATE pdf_files SET deleted_at = $1 WHERE id = $2`, time.Now().UTC(), pdfs[tt.trash]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		workspaceID := env.workspaceID
+		if tt.team {
+			workspaceID = team
+		}
+		code, body := env.upload(t, samplePDF(t, tt.variant), workspaceID, tt.force)
+		if code != tt.wantCode {
+			t.Fatalf("%s: status = %d, want %d (body %v)", tt.name, code, tt.wantCode, body)
+		}
+		if body["content_sha256"] != sha256Hex(samplePDF(t, tt.variant)) {
+			t.Errorf("%s: content_sha256 = %v", tt.name, body["content_sha256"])
+		}
+		pdfs[tt.name] = bodyInt(body, "pdf_id")
+
+		if tt.wantDuplicate != "" {
+			if body["duplicate"] != true || pdfs[tt.name] != pdfs[tt.wantDuplicate] {
+				t.Errorf("%s: got duplicate=%v pdf_id=%d, want duplicate of %d", tt.name, body["duplicate"], pdfs[tt.name], pdfs[tt.wantDuplicate])
+			}
+			continue
+		}
+		var ws int
+		var allowDuplicate bool
+		if err := env.db.QueryRow(`SELECT workspace_id, allow_duplicate FROM pdf_files WHERE id = $1`, pdfs[tt.name]).Scan(&ws, &allowDuplicate); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ws != workspaceID || allowDuplicate != tt.force {
+			t.Errorf("%s: got workspace=%d allow_duplicate=%v", tt.name, ws, allowDuplicate)
+		}
+		if tt.force && body["duplicate_of"] != float64(pdfs["upload pertama"]) {
+			t.Errorf("%s: duplicate_of = %v, want %d", tt.name, body["duplicate_of"], pdfs["upload pertama"])
+		}
+	}
+
+	var n int
+	if err := env.db.QueryRow(`SELECT COUNT(*) FROM pdf_files`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if want := 6; n != want {
+		t.Errorf("pdf_files rows = %d, want %d", n, want)
+	}
+}
+
+func TestUploadChecksum(t *testing.T) {
+	data := samplePDF(t, "")
+	const chunkSize = 256
+	chunks := [][]byte{}
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[i:end])
+	}
+	badSum := sha256Hex([]byte("bukan isi chunk"))
+
+	tests := []struct {
+		name         string
+		fileSum      string //file_sha256 waktu init
+		chunkSum     string //X-Chunk-SHA256 untuk chunk 1: "good", "bad", "invalid", "" = tidak dikirim
+		corrupt      bool   //chunk 1 dirusak di disk setelah lolos verifikasi
+		wantChunk    int
+		wantChunkErr string
+		wantComplete int
+		wantErr      string
+	}{
+		{"tanpa checksum", "", "", false, 200, "", 202, ""},
+		{"checksum chunk cocok", "", "good", false, 200, "", 202, ""},
+		{"checksum chunk beda", "", "bad", false, 422, "CHUNK_CHECKSUM_MISMATCH", 409, ""},
+		{"checksum chunk bukan hex", "", "invalid", false, 400, "INVALID_CHECKSUM", 409, ""},
+		{"checksum file cocok", sha256Hex(data), "good", false, 200, "", 202, ""},
+		{"checksum file beda", badSum, "", false, 200, "", 422, "FILE_CHECKSUM_MISMATCH"},
+		{"chunk rusak di disk", "", "good", true, 200, "", 409, "CHUNK_CHECKSUM_MISMATCH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newHandlerEnv(t)
+			uploadID := env.initUpload(t, data, chunkSize, 0, tt.fileSum)
+			for i, chunk := range chunks {
+				sum := ""
+				if i == 1 {
+					switch tt.chunkSum {
+					case "good":
+						sum = sha256Hex(chunk)
+					case "bad":
+						sum = badSum
+					case "invalid":
+						sum = "xyz"
+					}
+				}
+				code, body := env.sendChunk(t, uploadID, i, chunk, sum)
+				want, wantErr := 200, ""
+				if i == 1 {
+					want, wantErr = tt.wantChunk, tt.wantChunkErr
+				}
+				if code != want || (wantErr != "" && body["code"] != wantErr) {
+					t.Fatalf("chunk %d: status = %d code = %v, want %d %s", i, code, body["code"], want, wantErr)
+				}
+				if i == 1 && sum != "" && code == 200 && body["verified"] != true {
+					t.Errorf("chunk %d not verified: %v", i, body)
+				}
+			}
+
+			partPath := filepath.Join(env.dir, ".chunks", uploadID, "00000001.part")
+			if tt.corrupt {
+				if err := os.WriteFile(partPath, bytes.Repeat([]byte("x"), len(chunks[1])), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				_, status := env.sendJSON(t, "GET", "/upload/status?upload_id="+uploadID, nil)
+				if fmt.Sprint(status["corrupted"]) != "[1]" {
+					t.Errorf("status corrupted = %v, want [1]", status["corrupted"])
+				}
+			}
+
+			code, body := env.complete(t, uploadID, false)
+			if code != tt.wantComplete || (tt.wantErr != "" && body["code"] != tt.wantErr) {
+				t.Fatalf("complete: status = %d code = %v, want %d %s (body %v)", code, body["code"], tt.wantComplete, tt.wantErr, body)
+			}
+			switch {
+			case tt.corrupt:
+				// chunk rusak dihapus supaya client tinggal kirim ulang chunk itu
+				if fmt.Sprint(body["bad_chunks"]) != "[1]" {
+					t.Errorf("bad_chunks = %v, want [1]", body["bad_chunks"])
+				}
+				if _, err := os.Stat(partPath); !os.IsNotExist(err) {
+					t.Errorf("corrupted chunk still on disk: %v", err)
+				}
+				if code, _ := env.sendChunk(t, uploadID, 1, chunks[1], sha256Hex(chunks[1])); code != 200 {
+					t.Fatalf("resend chunk status = %d", code)
+				}
+				if code, body := env.complete(t, uploadID, false); code != 202 {
+					t.Fatalf("complete after resend: status = %d (body %v)", code, body)
+				}
+			case tt.wantComplete == 409:
+				// chunk yang ditolak tidak tersimpan
+				if body["missing_chunk"] != float64(1) {
+					t.Errorf("missing_chunk = %v, want 1", body["missing_chunk"])
+				}
+			case tt.wantErr == "FILE_CHECKSUM_MISMATCH":
+				if body["expected"] != tt.fileSum || body["actual"] != sha256Hex(data) {
+					t.Errorf("got expected=%v actual=%v", body["expected"], body["actual"])
+				}
+			}
+		})
+	}
+}
+
+// retry chunk yang sudah ada: dengan checksum yang cocok dianggap beres, yang beda ditolak dan chunk lama dibuang.
+func TestUploadChunkRetry(t *testing.T) {
+	env := newHandlerEnv(t)
+	data := samplePDF(t, "")
+	uploadID := env.initUpload(t, data, len(data), 0, "")
+
+	tests := []struct {
+		name         string
+		sum          string
+		wantCode     int
+		wantUploaded bool //already_uploaded
+	}{
+		{"upload pertama", "", 200, false},
+		{"retry tanpa checksum", "", 200, true},
+		{"retry checksum cocok", sha256Hex(data), 200, true},
+		{"retry checksum beda", sha256Hex([]byte("lain")), 422, false},
+		{"kirim ulang setelah ditolak", sha256Hex(data), 200, false},
+	}
+	for _, tt := range tests {
+		code, body := env.sendChunk(t, uploadID, 0, data, tt.sum)
+		if code != tt.wantCode || (body["already_uploaded"] == true) != tt.wantUploaded {
+			t.Fatalf("%s: status = %d body = %v", tt.name, code, body)
+		}
+	}
+	if code, body := env.complete(t, uploadID, false); code != 202 {
+		t.Fatalf("complete: status = %d (body %v)", code, body)
+	}
+}
@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// roleLookup = repository workspace untuk cek role saja (cuma baca, Driver tidak dipakai).
+func roleLookup(db *sql.DB) *repository.SQLWorkspaceRepository {
+	return &repository.SQLWorkspaceRepository{DB: db}
+}
+
+// workspaceRole mengembalikan role user di workspace, "" kalau bukan anggota.
+func workspaceRole(db *sql.DB, workspaceID, userID int) (string, error) {
+	return roleLookup(db).Role(context.Background(), workspaceID, userID)
+}
+
+// pdfRole mengembalikan workspace PDF + role user di sana. repository.ErrNotFound kalau PDF tidak ada
+// atau user bukan anggota workspace-nya (keduanya dijawab 404, biar id PDF orang lain tidak ketahuan).
+// trashed = cari PDF yang ada di trash (restore / purge), selain itu PDF di trash dianggap tidak ada.
+func pdfRole(db *sql.DB, pdfID, userID int, trashed bool) (int, string, error) {
+	return roleLookup(db).PDFRole(context.Background(), pdfID, userID, trashed)
+}
+
+// personalWorkspaceID = workspace pribadi user, default tujuan upload.
+func personalWorkspaceID(db *sql.DB, userID int) (int, error) {
+	return roleLookup(db).PersonalID(context.Background(), userID)
+}
+
+// requestedWorkspace membaca ?workspace_id= (atau header X-Workspace-ID). 0 = semua workspace user.
+func requestedWorkspace(c *fiber.Ctx) (int, bool) {
+	raw := strings.TrimSpace(c.Query("workspace_id"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.Get("X-Workspace-ID"))
+	}
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// resolveUploadWorkspace memilih workspace tujuan upload (default workspace pribadi) dan memastikan user minimal editor.
+// Mengembalikan status HTTP != 0 kalau ditolak.
+func resolveUploadWorkspace(db *sql.DB, workspaceID, userID int) (int, int, fiber.Map) {
+	if workspaceID == 0 {
+		id, err := personalWorkspaceID(db, userID)
+		if err != nil {
+			return 0, 500, fiber.Map{"error": "Personal workspace not found"}
+		}
+		workspaceID = id
+	}
+	role, err := workspaceRole(db, workspaceID, userID)
+	if err != nil {
+		return 0, 500, fiber.Map{"error": "Database error"}
+	}
+	if role == "" {
+		return 0, 404, fiber.Map{"error": "Workspace not found"}
+	}
+	if !models.RoleAtLeast(role, models.RoleEditor) {
+		return 0, 403, forbidden(models.RoleEditor)
+	}
+	return workspaceID, 0, nil
+}
+
+func forbidden(required string) fiber.Map {
+	return fiber.Map{"error": "Forbidden", "code": "INSUFFICIENT_ROLE", "required_role": required}
+}
+
+// requirePDFRole = cek akses PDF untuk handler: 404 kalau tidak bisa dilihat, 403 kalau role kurang.
+// Kalau ok == false, respons error sudah dikirim (kembalikan err-nya).
+func requirePDFRole(c *fiber.Ctx, db *sql.DB, pdfID int, min string) (workspaceID int, ok bool, err error) {
+	return checkPDFRole(c, db, pdfID, min, false)
+}
+
+// requireTrashedPDFRole = requirePDFRole untuk PDF yang ada di trash.
+func requireTrashedPDFRole(c *fiber.Ctx, db *sql.DB, pdfID int, min string) (workspaceID int, ok bool, err error) {
+	return checkPDFRole(c, db, pdfID, min, true)
+}
+
+func checkPDFRole(c *fiber.Ctx, db *sql.DB, pdfID int, min string, trashed bool) (workspaceID int, ok bool, err error) {
+	workspaceID, role, err := pdfRole(db, pdfID, currentUserID(c), trashed)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return 0, false, c.Status(404).JSON(fiber.Map{"error": "PDF not found"})
+		}
+		return 0, false, c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if !models.RoleAtLeast(role, min) {
+		return 0, false, c.Status(403).JSON(forbidden(min))
+	}
+	return workspaceID, true, nil
+}
+
+// requireWorkspaceRole = cek role user di workspace: 404 kalau bukan anggota, 403 kalau role kurang.
+// Kalau ok == false, respons error sudah dikirim.
+func requireWorkspaceRole(c *fiber.Ctx, db *sql.DB, workspaceID int, min string) (ok bool, err error) {
+	role, err := workspaceRole(db, workspaceID, currentUserID(c))
+	if err != nil {
+		return false, c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if role == "" {
+		return false, c.Status(404).JSON(fiber.Map{"error": "Workspace not found"})
+	}
+	if !models.RoleAtLeast(role, min) {
+		return false, c.Status(403).JSON(forbidden(min))
+	}
+	return true, nil
+}
+
+// requireItemRole = cek role untuk baris yang menempel ke workspace (table: "tags" / "folders").
+// Baris yang tidak ada dan workspace yang bukan milik user sama-sama 404 dengan pesan notFound.
+func requireItemRole(c *fiber.Ctx, db *sql.DB, table string, id int, min, notFound string) (workspaceID int, ok bool, err error) {
+	workspaceID, role, err := roleLookup(db).ItemRole(c.UserContext(), table, id, currentUserID(c))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return 0, false, c.Status(404).JSON(fiber.Map{"error": notFound})
+		}
+		return 0, false, c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if !models.RoleAtLeast(role, min) {
+		return 0, false, c.Status(403).JSON(forbidden(min))
+	}
+	return workspaceID, true, nil
+}
+
+type WorkspaceHandler struct {
+	DB         *sql.DB //cek akses + audit log
+	Workspaces repository.WorkspaceRepository
+	Users      repository.UserRepository
+}
+
+func NewWorkspaceHandler(db *sql.DB, driver string) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		DB:         db,
+		Workspaces: repository.NewWorkspaceRepository(db, driver),
+		Users:      repository.NewUserRepository(db),
+	}
+}
+
+// requireRole = versi requirePDFRole untuk workspace (:id).
+func (h *WorkspaceHandler) requireRole(c *fiber.Ctx, min string) (int, bool, error) {
+	workspaceID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, false, c.Status(400).JSON(fiber.Map{"error": "Invalid workspace ID"})
+	}
+	ok, err := requireWorkspaceRole(c, h.DB, workspaceID, min)
+	return workspaceID, ok, err
+}
+
+// ListWorkspaces = semua workspace tempat user jadi anggota, beserta role-nya.
+func (h *WorkspaceHandler) ListWorkspaces(c *fiber.Ctx) error {
+	items, err := h.Workspaces.ListForUser(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	jakartaLoc := getJakartaLocation()
+	workspaces := []fiber.Map{}
+	for _, it := range items {
+		w := it.Workspace
+		w.CreatedAt = w.CreatedAt.In(jakartaLoc)
+		workspaces = append(workspaces, fiber.Map{"workspace": w, "pdf_count": it.PDFCount})
+	}
+
+	return c.JSON(fiber.Map{"workspaces": workspaces, "count": len(workspaces)})
+}
+
+// CreateWorkspace membuat workspace baru, pembuatnya jadi owner.
+func (h *WorkspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+
+	w, err := h.Workspaces.Create(c.UserContext(), req.Name, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create workspace"})
+	}
+	w.CreatedAt = w.CreatedAt.In(getJakartaLocation())
+
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditWorkspaceCreate,
+		TargetType:  models.TargetWorkspace,
+		TargetID:    w.ID,
+		WorkspaceID: w.ID,
+		After:       fiber.Map{"name": w.Name},
+	})
+
+	return c.Status(201).JSON(fiber.Map{"success": true, "workspace": w})
+}
+
+// GetWorkspace = detail workspace + daftar anggota (semua anggota boleh lihat).
+func (h *WorkspaceHandler) GetWorkspace(c *fiber.Ctx) error {
+	workspaceID, ok, err := h.requireRole(c, models.RoleViewer)
+	if !ok {
+		return err
+	}
+
+	w, err := h.Workspaces.GetForUser(c.UserContext(), workspaceID, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	members, err := h.Workspaces.Members(c.UserContext(), workspaceID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	jakartaLoc := getJakartaLocation()
+	w.CreatedAt = w.CreatedAt.In(jakartaLoc)
+	for i := range members {
+		members[i].CreatedAt = members[i].CreatedAt.In(jakartaLoc)
+	}
+
+	return c.JSON(fiber.Map{"workspace": w, "members": members})
+}
+
+// RenameWorkspace (owner).
+func (h *WorkspaceHandler) RenameWorkspace(c *fiber.Ctx) error {
+	workspaceID, ok, err := h.requireRole(c, models.RoleOwner)
+	if !ok {
+		return err
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	oldName, err := h.Workspaces.Rename(c.UserContext(), workspaceID, req.Name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Update failed"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditWorkspaceRename,
+		TargetType:  models.TargetWorkspace,
+		TargetID:    workspaceID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": oldName},
+		After:       fiber.Map{"name": req.Name},
+	})
+	return c.JSON(fiber.Map{"success": true, "workspace_id": workspaceID, "name": req.Name})
+}
+
+// DeleteWorkspace (owner). Workspace pribadi tidak bisa dihapus, workspace yang masih ada PDF-nya juga tidak.
+func (h *WorkspaceHandler) DeleteWorkspace(c *fiber.Ctx) error {
+	workspaceID, ok, err := h.requireRole(c, models.RoleOwner)
+	if !ok {
+		return err
+	}
+
+	w, err := h.Workspaces.GetForUser(c.UserContext(), workspaceID, currentUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	pdfCount, err := h.Workspaces.CountPDFs(c.UserContext(), workspaceID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if w.Personal {
+		return c.Status(409).JSON(fiber.Map{"error": "Workspace pribadi tidak bisa dihapus"})
+	}
+	if pdfCount > 0 {
+		// PDF di trash juga dihitung (masih bisa di-restore), kosongkan lewat DELETE /trash/:id
+		return c.Status(409).JSON(fiber.Map{"error": "Workspace masih berisi PDF (termasuk di trash), pindahkan atau hapus permanen dulu", "pdf_count": pdfCount})
+	}
+
+	if err := h.Workspaces.Delete(c.UserContext(), workspaceID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete workspace"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditWorkspaceDelete,
+		TargetType:  models.TargetWorkspace,
+		TargetID:    workspaceID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"name": w.Name},
+	})
+	return c.JSON(fiber.Map{"success": true, "workspace_id": workspaceID})
+}
+
+// AddMember menambahkan user (berdasarkan email) ke workspace (owner).
+func (h *WorkspaceHandler) AddMember(c *fiber.Ctx) error {
+	workspaceID, ok, err := h.requireRole(c, models.RoleOwner)
+	if !ok {
+		return err
+	}
+	var req struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if req.Role == "" {
+		req.Role = models.RoleViewer
+	}
+	if !models.ValidRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid role (available: owner, editor, viewer)"})
+	}
+
+	user, err := h.Users.GetByEmail(c.UserContext(), strings.TrimSpace(req.Email))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	userID := user.ID
+
+	if err := h.Workspaces.AddMember(c.UserContext(), workspaceID, userID, req.Role); err != nil {
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{"error": "User sudah jadi anggota, pakai PUT untuk ganti role"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add member"})
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditMemberAdd,
+		TargetType:  models.TargetUser,
+		TargetID:    userID,
+		WorkspaceID: workspaceID,
+		After:       fiber.Map{"role": req.Role},
+	})
+
+	return c.Status(201).JSON(fiber.Map{"success": true, "workspace_id": workspaceID, "user_id": userID, "role": req.Role})
+}
+
+// UpdateMember mengganti role anggota (owner).
+func (h *WorkspaceHandler) UpdateMember(c *fiber.Ctx) error {
+	workspaceID, ok, err := h.requireRole(c, models.RoleOwner)
+	if !ok {
+		return err
+	}
+	memberID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if !models.ValidRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid role (available: owner, editor, viewer)"})
+	}
+
+	current, err := h.Workspaces.SetMemberRole(c.UserContext(), workspaceID, memberID, req.Role)
+	if err != nil {
+		return memberError(c, err, "Update failed")
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditMemberUpdate,
+		TargetType:  models.TargetUser,
+		TargetID:    memberID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"role": current},
+		After:       fiber.Map{"role": req.Role},
+	})
+	return c.JSON(fiber.Map{"success": true, "workspace_id": workspaceID, "user_id": memberID, "role": req.Role})
+}
+
+// memberError = jawaban untuk error SetMemberRole / RemoveMember.
+func memberError(c *fiber.Ctx, err error, msg string) error {
+	switch err {
+	case repository.ErrNotFound:
+		return c.Status(404).JSON(fiber.Map{"error": "Member not found"})
+	case repository.ErrLastOwner:
+		// owner terakhir tidak bisa turun role / keluar
+		return c.Status(409).JSON(fiber.Map{"error": "Workspace harus punya minimal satu owner"})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": msg})
+}
+
+// RemoveMember mengeluarkan anggota (owner), atau keluar sendiri (semua role).
+func (h *WorkspaceHandler) RemoveMember(c *fiber.Ctx) error {
+	memberID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	minRole := models.RoleOwner
+	if memberID == currentUserID(c) {
+		minRole = models.RoleViewer
+	}
+	workspaceID, ok, err := h.requireRole(c, minRole)
+	if !ok {
+		return err
+	}
+
+	current, err := h.Workspaces.RemoveMember(c.UserContext(), workspaceID, memberID)
+	if err != nil {
+		return memberError(c, err, "Failed to remove member")
+	}
+	recordAudit(c, h.DB, auditEntry{
+		Action:      models.AuditMemberRemove,
+		TargetType:  models.TargetUser,
+		TargetID:    memberID,
+		WorkspaceID: workspaceID,
+		Before:      fiber.Map{"role": current},
+	})
+	return c.JSON(fiber.Map{"success": true, "workspace_id": workspaceID, "user_id": memberID})
+}
+
+// MovePDF memindahkan PDF ke workspace lain. Butuh owner di workspace asal (PDF "hilang" dari sana)
+// dan minimal editor di workspace tujuan. Tag dan folder dilepas karena berlaku per workspace.
+func (h *WorkspaceHandler) MovePDF(c *fiber.Ctx) error {
+	pdfID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF ID"})
+	}
+	var req struct {
+		WorkspaceID int `json:"workspace_id"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.WorkspaceID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "workspace_id is required"})
+	}
+
+	fromID, ok, err := requirePDFRole(c, h.DB, pdfID, models.RoleOwner)
+	if !ok {
+		return err
+	}
+	if fromID == req.WorkspaceID {
+		return c.JSON(fiber.Map{"success": true, "pdf_id": pdfID, "workspace_id": fromID, "moved": false})
+	}
+	if _, status, body := resolveUploadWorkspace(h.DB, req.WorkspaceID, currentUserID(c)); status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	// tag & folder milik workspace asal, jadi dilepas waktu pindah
+	move, err := h.Workspaces.MovePDF(c.UserContext(), pdfID, req.WorkspaceID)
+	if err != nil {
+		// isi file yang sama sudah ada di workspace tujuan (unique index dedup)
+		if err == repository.ErrDuplicate {
+			return c.Status(409).JSON(fiber.Map{"error": "PDF dengan isi yang sama sudah ada di workspace tujuan", "code": "DUPLICATE_IN_TARGET"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to move PDF"})
+	}
+
+	// dicatat di kedua workspace, biar owner masing-masing bisa lihat
+	for _, wsID := range []int{fromID, req.WorkspaceID} {
+		recordAudit(c, h.DB, auditEntry{
+			Action:      models.AuditPDFMove,
+			TargetType:  models.TargetPDF,
+			TargetID:    pdfID,
+			WorkspaceID: wsID,
+			Before:      fiber.Map{"workspace_id": fromID, "folder_id": move.OldFolderID, "tag_ids": move.RemovedTags},
+			After:       fiber.Map{"workspace_id": req.WorkspaceID},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":           true,
+		"pdf_id":            pdfID,
+		"from_workspace_id": fromID,
+		"workspace_id":      req.WorkspaceID,
+		"moved":             true,
+	})
+}
+
+//for learn, workspace = ruang dokumen per departemen. owner > editor > viewer
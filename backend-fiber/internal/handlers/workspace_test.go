@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pdf-backend-fiber/internal/auth"
+	"pdf-backend-fiber/internal/database/dbtest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testApp = fiber app dengan login palsu: header X-Test-User berisi id user yang dianggap login.
+func testApp() *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id, err := strconv.Atoi(c.Get("X-Test-User")); err == nil {
+			auth.SetPrincipal(c, &auth.Principal{UserID: id, Method: "jwt"})
+		}
+		return c.Next()
+	})
+	return app
+}
+
+// doRequest mengirim request sebagai userID, mengembalikan status + body.
+func doRequest(t *testing.T, app *fiber.App, method, target string, userID int, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", strconv.Itoa(userID))
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+// sharedWorkspace membuat workspace tim. Key roles = role anggota, boleh diberi angka (owner2) untuk role yang sama.
+func sharedWorkspace(t *testing.T, db *sql.DB, name string, roles map[string]int) int {
+	t.Helper()
+	var id int
+	if err := db.QueryRow(`INSERT INTO workspaces (name, personal, created_by) VALUES ($1, FALSE, NULL) RETURNING id`, name).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	for role, userID := range roles {
+		dbtest.Member(t, db, id, userID, strings.TrimRight(role, "0123456789"))
+	}
+	return id
+}
+
+func memberRole(t *testing.T, db *sql.DB, workspaceID, userID int) string {
+	t.Helper()
+	role, err := workspaceRole(db, workspaceID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return role
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	tests := []struct {
+		name        string
+		secondOwner bool   //ada owner kedua di workspace
+		actor       string //owner / editor / viewer / outsider
+		method      string
+		target      string //owner / owner2 / editor / viewer / outsider
+		role        string //role baru untuk PUT
+		wantCode    int
+		wantRole    string //role target setelahnya ("" = bukan anggota)
+	}{
+		{"owner menaikkan viewer", false, "owner", "PUT", "viewer", "editor", 200, "editor"},
+		{"owner menurunkan editor", false, "owner", "PUT", "editor", "viewer", 200, "viewer"},
+		{"editor tidak bisa ganti role", false, "editor", "PUT", "viewer", "editor", 403, "viewer"},
+		{"viewer tidak bisa ganti role", false, "viewer", "PUT", "viewer", "owner", 403, "viewer"},
+		{"bukan anggota dapat 404", false, "outsider", "PUT", "viewer", "editor", 404, "viewer"},
+		{"role tidak valid", false, "owner", "PUT", "viewer", "admin", 400, "viewer"},
+		{"ganti role bukan anggota", false, "owner", "PUT", "outsider", "editor", 404, ""},
+		{"owner terakhir tidak bisa turun", false, "owner", "PUT", "owner", "editor", 409, "owner"},
+		{"owner turun kalau ada owner lain", true, "owner", "PUT", "owner", "viewer", 200, "viewer"},
+		{"owner menurunkan owner lain", true, "owner", "PUT", "owner2", "editor", 200, "editor"},
+		{"owner tetap owner", false, "owner", "PUT", "owner", "owner", 200, "owner"},
+
+		{"owner mengeluarkan editor", false, "owner", "DELETE", "editor", "", 200, ""},
+		{"editor tidak bisa mengeluarkan viewer", false, "editor", "DELETE", "viewer", "", 403, "viewer"},
+		{"viewer keluar sendiri", false, "viewer", "DELETE", "viewer", "", 200, ""},
+		{"editor keluar sendiri", false, "editor", "DELETE", "editor", "", 200, ""},
+		{"owner terakhir tidak bisa keluar", false, "owner", "DELETE", "owner", "", 409, "owner"},
+		{"owner keluar kalau ada owner lain", true, "owner", "DELETE", "owner", "", 200, ""},
+		{"mengeluarkan bukan anggota", false, "owner", "DELETE", "outsider", "", 404, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			users := map[string]int{}
+			for _, name := range []string{"owner", "owner2", "editor", "viewer", "outsider"} {
+				users[name], _ = dbtest.User(t, db, name+"@test.id")
+			}
+			roles := map[string]int{"owner": users["owner"], "editor": users["editor"], "viewer": users["viewer"]}
+			if tt.secondOwner {
+				roles["owner2"] = users["owner2"]
+			}
+			wsID := sharedWorkspace(t, db, "Tim", roles)
+
+			app := testApp()
+			h := NewWorkspaceHandler(db, "sqlite")
+			app.Put("/workspaces/:id/members/:user_id", h.UpdateMember)
+			app.Delete("/workspaces/:id/members/:user_id", h.RemoveMember)
+
+			path := "/workspaces/" + strconv.Itoa(wsID) + "/members/" + strconv.Itoa(users[tt.target])
+			code, body := doRequest(t, app, tt.method, path, users[tt.actor], `{"role":"`+tt.role+`"}`)
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", code, tt.wantCode, body)
+			}
+			if got := memberRole(t, db, wsID, users[tt.target]); got != tt.wantRole {
+				t.Errorf("role = %q, want %q", got, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestMovePDF(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string //role di workspace asal ("" = bukan anggota)
+		dest      string //role di workspace tujuan
+		duplicate bool   //isi yang sama sudah ada di tujuan
+		wantCode  int
+	}{
+		{"owner ke editor", "owner", "editor", false, 200},
+		{"owner ke owner", "owner", "owner", false, 200},
+		{"owner ke viewer", "owner", "viewer", false, 403},
+		{"owner ke bukan anggota", "owner", "", false, 404},
+		{"editor di asal", "editor", "owner", false, 403},
+		{"viewer di asal", "viewer", "owner", false, 403},
+		{"bukan anggota di asal", "", "owner", false, 404},
+		{"duplikat di tujuan", "owner", "editor", true, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			userID, _ := dbtest.User(t, db, "a@test.id")
+			otherID, _ := dbtest.User(t, db, "b@test.id")
+
+			roles := func(role string) map[string]int {
+				m := map[string]int{"owner1": otherID}
+				if role != "" {
+					m[role] = userID
+				}
+				return m
+			}
+			from := sharedWorkspace(t, db, "Asal", roles(tt.source))
+			to := sharedWorkspace(t, db, "Tujuan", roles(tt.dest))
+			pdfID := dbtest.PDF(t, db, from, otherID, "a.pdf")
+			if tt.duplicate {
+				dbtest.PDF(t, db, to, otherID, "a.pdf")
+			}
+
+			app := testApp()
+			app.Post("/pdf/:id/move", NewWorkspaceHandler(db, "sqlite").MovePDF)
+			code, body := doRequest(t, app, "POST", "/pdf/"+strconv.Itoa(pdfID)+"/move", userID, `{"workspace_id":`+strconv.Itoa(to)+`}`)
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", code, tt.wantCode, body)
+			}
+
+			want := from
+			if tt.wantCode == 200 {
+				want = to
+			}
+			var got int
+			if err := db.QueryRow(`SELECT workspace_id FROM pdf_files WHERE id = $1`, pdfID).Scan(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("workspace_id = %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package janitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+)
+
+// tmpGrace = umur minimal file .chunk-*.tmp sebelum dianggap sampah.
+// File tmp normalnya cuma hidup selama satu request upload chunk.
+const tmpGrace = 30 * time.Minute
+
+// Session = satu folder upload chunk di .chunks/<upload_id>.
+type Session struct {
+	UploadID         string    `json:"upload_id"`
+	OriginalFilename string    `json:"original_filename,omitempty"`
+	FileSize         int64     `json:"file_size"`
+	TotalChunks      int       `json:"total_chunks"`
+	ReceivedChunks   int       `json:"received_chunks"`
+	Bytes            int64     `json:"bytes"` //ukuran di disk
+	CreatedAt        time.Time `json:"created_at"`
+	LastActivity     time.Time `json:"last_activity"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// Stats = metrik kumulatif sejak server jalan.
+type Stats struct {
+	Runs            int64     `json:"runs"`
+	SessionsRemoved int64     `json:"sessions_removed"`
+	TmpFilesRemoved int64     `json:"tmp_files_removed"`
+	BytesReclaimed  int64     `json:"bytes_reclaimed"`
+	LastRunAt       time.Time `json:"last_run_at,omitempty"`
+	LastError       string    `json:"last_error,omitempty"`
+}
+
+// SweepResult = hasil satu kali bersih-bersih.
+type SweepResult struct {
+	SessionsRemoved int   `json:"sessions_removed"`
+	TmpFilesRemoved int   `json:"tmp_files_removed"`
+	BytesReclaimed  int64 `json:"bytes_reclaimed"`
+}
+
+// Janitor menghapus session chunk upload yang ditinggal (tidak ada aktivitas lebih dari TTL)
+// dan file .chunk-*.tmp yang tertinggal karena proses mati di tengah upload.
+type Janitor struct {
+	ChunkRoot string
+	TTL       time.Duration
+	Interval  time.Duration
+
+	mu    sync.Mutex //satu sweep / purge dalam satu waktu, sekaligus jaga stats
+	stats Stats
+}
+
+func New(cfg config.Config) *Janitor {
+	return &Janitor{
+		ChunkRoot: filepath.Join(cfg.UploadDir, ".chunks"), //sama dengan UploadHandler.chunkRootDir
+		TTL:       cfg.UploadSessionTTL,
+		Interval:  cfg.JanitorInterval,
+	}
+}
+
+// Start menjalankan sweep sekali di awal lalu berkala setiap Interval.
+func (j *Janitor) Start() {
+	go func() {
+		for {
+			j.Sweep()
+			time.Sleep(j.Interval)
+		}
+	}()
+}
+
+type sessionMeta struct {
+	OriginalFilename string `json:"original_filename"`
+	FileSize         int64  `json:"file_size"`
+	TotalChunks      int    `json:"total_chunks"`
+	CreatedAtUnix    int64  `json:"created_at_unix"`
+}
+
+// inspect membaca isi folder session. Aktivitas terakhir = file paling baru di folder,
+// jadi upload yang masih di-resume tidak ikut terhapus walaupun dibuat sudah lama.
+func (j *Janitor) inspect(uploadID string) (Session, error) {
+	dir := filepath.Join(j.ChunkRoot, uploadID)
+	st, err := os.Stat(dir)
+	if err != nil {
+		return Session{}, err
+	}
+	if !st.IsDir() {
+		return Session{}, fmt.Errorf("%s is not a directory", uploadID)
+	}
+
+	s := Session{UploadID: uploadID, CreatedAt: st.ModTime(), LastActivity: st.ModTime()}
+	if b, err := os.ReadFile(filepath.Join(dir, "meta.json")); err == nil {
+		var meta sessionMeta
+		if json.Unmarshal(b, &meta) == nil {
+			s.OriginalFilename = meta.OriginalFilename
+			s.FileSize = meta.FileSize
+			s.TotalChunks = meta.TotalChunks
+			if meta.CreatedAtUnix > 0 {
+				s.CreatedAt = time.Unix(meta.CreatedAtUnix, 0)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Session{}, err
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		s.Bytes += info.Size()
+		if strings.HasSuffix(e.Name(), ".part") {
+			s.ReceivedChunks++
+		}
+		if info.ModTime().After(s.LastActivity) {
+			s.LastActivity = info.ModTime()
+		}
+	}
+	s.ExpiresAt = s.LastActivity.Add(j.TTL)
+	return s, nil
+}
+
+// Sessions mengembalikan semua session upload yang masih ada di disk.
+func (j *Janitor) Sessions() ([]Session, error) {
+	entries, err := os.ReadDir(j.ChunkRoot)
+	if os.IsNotExist(err) {
+		return []Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := j.inspect(e.Name())
+		if err != nil {
+			continue //bisa saja barusan dihapus oleh CompleteChunkUpload
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// Purge menghapus satu session (dipakai endpoint admin).
+func (j *Janitor) Purge(uploadID string) (Session, error) {
+	if uploadID == "" || uploadID != filepath.Base(uploadID) || strings.HasPrefix(uploadID, ".") {
+		return Session{}, fmt.Errorf("invalid upload_id")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s, err := j.inspect(uploadID)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := os.RemoveAll(filepath.Join(j.ChunkRoot, uploadID)); err != nil {
+		return Session{}, err
+	}
+	j.stats.SessionsRemoved++
+	j.stats.BytesReclaimed += s.Bytes
+	log.Printf("Janitor: purged upload session %s (%d bytes)", uploadID, s.Bytes)
+	return s, nil
+}
+
+// Sweep menghapus session yang kedaluwarsa dan file tmp yang tertinggal.
+func (j *Janitor) Sweep() SweepResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var result SweepResult
+	var sweepErr error
+	now := time.Now()
+
+	sessions, err := j.Sessions()
+	if err != nil {
+		sweepErr = err
+	}
+	for _, s := range sessions {
+		dir := filepath.Join(j.ChunkRoot, s.UploadID)
+
+		if now.After(s.ExpiresAt) {
+			if err := os.RemoveAll(dir); err != nil {
+				sweepErr = err
+				continue
+			}
+			result.SessionsRemoved++
+			result.BytesReclaimed += s.Bytes
+			continue
+		}
+
+		// session masih aktif, tapi file tmp lama di dalamnya sudah pasti sampah
+		n, bytes, err := removeStaleTmp(dir, now)
+		if err != nil {
+			sweepErr = err
+		}
+		result.TmpFilesRemoved += n
+		result.BytesReclaimed += bytes
+	}
+
+	j.stats.Runs++
+	j.stats.LastRunAt = now
+	j.stats.SessionsRemoved += int64(result.SessionsRemoved)
+	j.stats.TmpFilesRemoved += int64(result.TmpFilesRemoved)
+	j.stats.BytesReclaimed += result.BytesReclaimed
+	j.stats.LastError = ""
+	if sweepErr != nil {
+		j.stats.LastError = sweepErr.Error()
+		log.Printf("Janitor: sweep error: %v", sweepErr)
+	}
+
+	if result.SessionsRemoved > 0 || result.TmpFilesRemoved > 0 {
+		log.Printf("Janitor: removed %d expired upload session(s) and %d tmp file(s), reclaimed %d bytes",
+			result.SessionsRemoved, result.TmpFilesRemoved, result.BytesReclaimed)
+	}
+	return result
+}
+
+func removeStaleTmp(dir string, now time.Time) (int, int64, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, ".chunk-*.tmp"))
+	if err != nil {
+		return 0, 0, err
+	}
+	removed := 0
+	var bytes int64
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || now.Sub(info.ModTime()) < tmpGrace {
+			continue
+		}
+		if err := os.Remove(m); err != nil {
+			return removed, bytes, err
+		}
+		removed++
+		bytes += info.Size()
+	}
+	return removed, bytes, nil
+}
+
+func (j *Janitor) Stats() Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+//tukang bersih-bersih folder .chunks, biar upload yang ditinggal ga numpuk di disk
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"pdf-backend-fiber/internal/pdfinfo"
+	"pdf-backend-fiber/internal/services"
+	"pdf-backend-fiber/internal/storage"
+)
+
+// BackfillSearchText mengisi extracted_text + text_language untuk PDF yang diupload sebelum ada full-text search.
+// Dijalankan sekali di background waktu start. PDF yang gagal dibaca diisi teks kosong supaya tidak dicoba terus.
+func BackfillSearchText(ctx context.Context, db *sql.DB, store storage.Storage) {
+	rows, err := db.QueryContext(ctx, `SELECT id, filepath FROM pdf_files WHERE extracted_text IS NULL ORDER BY id`)
+	if err != nil {
+		log.Printf("search backfill: %v", err)
+		return
+	}
+	type pending struct {
+		id  int
+		key string
+	}
+	var todo []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.key); err == nil {
+			todo = append(todo, p)
+		}
+	}
+	rows.Close()
+	if len(todo) == 0 {
+		return
+	}
+
+	done := 0
+	for _, p := range todo {
+		text := ""
+		if fp, cleanup, err := storage.LocalCopy(ctx, store, p.key); err != nil {
+			log.Printf("search backfill: PDF %d: %v", p.id, err)
+		} else {
+			if t, err := pdfinfo.ExtractText(fp); err != nil {
+				log.Printf("search backfill: PDF %d: %v", p.id, err)
+			} else {
+				text = t
+			}
+			cleanup()
+		}
+
+		language := ""
+		if text != "" {
+			language = services.DetectLanguage(text)
+		}
+		if _, err := db.ExecContext(ctx, `
+			UPDATE pdf_files SET extracted_text = $1, text_language = NULLIF($2, '') WHERE id = $3 AND extracted_text IS NULL
+		`, pdfinfo.SearchText(text), language, p.id); err != nil {
+			log.Printf("search backfill: PDF %d: %v", p.id, err)
+			continue
+		}
+		done++
+	}
+	log.Printf("search backfill: %d/%d PDF lama sudah bisa dicari", done, len(todo))
+}
+
+//PDF lama tidak punya extracted_text, jadi diisi sekali di sini biar ikut muncul di /search
@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"pdf-backend-fiber/internal/services"
+)
+
+// Coverage mengubah info chunk hasil summarizer jadi nilai kolom summaries.
+// Summarizer yang tidak lewat map-reduce (misal fallback ke file) tidak punya info ini, jadi NULL.
+func Coverage(result *services.SummaryResult) (chunksCount, charsCovered, totalChars *int) {
+	if result == nil || result.ChunksCount == 0 {
+		return
+	}
+	chunks, covered, total := result.ChunksCount, result.CharsCovered, result.TotalChars
+	return &chunks, &covered, &total
+}
+
+//simpan hasil map-reduce biar bisa dicek bagian mana saja yang ikut diringkas
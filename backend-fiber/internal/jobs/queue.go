@@ -0,0 +1,416 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/repository"
+	"pdf-backend-fiber/internal/services"
+	"pdf-backend-fiber/internal/storage"
+)
+
+// ErrNotRetryable = ringkasan yang mau di-retry tidak ada atau statusnya bukan failed.
+var ErrNotRetryable = errors.New("summary is not failed")
+
+// ErrLeaseLost = job sudah tidak running atas nama worker ini (lease habis lalu diambil worker lain).
+var ErrLeaseLost = errors.New("summary job lease lost")
+
+// pollInterval dipakai worker untuk cek ulang tabel kalau tidak ada sinyal wake,
+// jadi job yang ketinggalan (misal di-insert proses lain) tetap kejemput.
+const pollInterval = 5 * time.Second
+
+// Queue menjalankan summarization di background.
+// summary_jobs di Postgres adalah sumber kebenaran, channel wake cuma buat bangunin worker lebih cepat.
+type Queue struct {
+	DB          *sql.DB
+	Summarizers *services.Registry
+	Storage     storage.Storage
+	Workers     int
+	PDFs        repository.PdfRepository
+	Summaries   repository.SummaryRepository
+
+	Lease       time.Duration //lease job running, diperpanjang tiap Lease/3 selama job jalan
+	MaxAttempts int           //batas berapa kali satu job boleh diambil worker
+
+	driver   string
+	workerID string //host:pid, ditambah nomor worker
+	wake     chan struct{}
+}
+
+func NewQueue(db *sql.DB, cfg config.Config, store storage.Storage) *Queue {
+	repos := repository.New(db, cfg.DBDriver)
+	return &Queue{
+		DB:          db,
+		Summarizers: services.NewRegistry(db, cfg),
+		Storage:     store,
+		Workers:     cfg.SummaryWorkers,
+		PDFs:        repos.PDFs,
+		Summaries:   repos.Summaries,
+		Lease:       cfg.SummaryJobLease,
+		MaxAttempts: cfg.SummaryJobMaxAttempts,
+		driver:      cfg.DBDriver,
+		workerID:    fmt.Sprintf("%s:%d", hostname(), os.Getpid()),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return h
+}
+
+// Start menyalakan worker pool. Job running yang lease-nya sudah habis (server mati di tengah proses)
+// diambil lagi oleh claim; job running milik instance lain yang masih hidup tidak disentuh.
+func (q *Queue) Start() error {
+	var expired int
+	err := q.DB.QueryRow(`SELECT COUNT(*) FROM summary_jobs WHERE status = $1 AND (locked_until IS NULL OR locked_until < $2)`,
+		models.JobRunning, time.Now().UTC()).Scan(&expired)
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Printf("Resuming %d interrupted summary job(s)", expired)
+	}
+
+	for i := 0; i < q.Workers; i++ {
+		go q.worker(fmt.Sprintf("%s/%d", q.workerID, i+1))
+	}
+	q.notify()
+	return nil
+}
+
+// Enqueue menyimpan job baru dengan status queued (+ baris summaries berstatus pending) lalu membangunkan worker.
+// Yang dikembalikan id job dan id ringkasan pending-nya.
+// provider kosong = summarizer default dari config, noCache = lewati summary_cache.
+// doneStages = tahapan yang sudah selesai sebelum job dibuat (misal chunk sudah digabung & PDF sudah divalidasi),
+// disimpan di transaksi yang sama supaya urutan event di SSE tidak kesalip worker.
+func (q *Queue) Enqueue(pdfID int, style, provider string, noCache bool, doneStages ...string) (jobID, summaryID int, err error) {
+	tx, err := q.DB.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	lastStage := ""
+	if len(doneStages) > 0 {
+		lastStage = doneStages[len(doneStages)-1]
+	}
+
+	// ringkasan ikut dimiliki pemilik PDF, baru jadi latest_summary setelah worker menandainya succeeded
+	err = tx.QueryRow(
+		`INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, status, attempts, user_id)
+		 SELECT id, '', $2, 0, $3, 0, user_id FROM pdf_files WHERE id = $1 RETURNING id`,
+		pdfID, style, models.SummaryPending,
+	).Scan(&summaryID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = tx.QueryRow(
+		`INSERT INTO summary_jobs (pdf_id, style, provider, no_cache, status, stage, summary_id) VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7) RETURNING id`,
+		pdfID, style, provider, noCache, models.JobQueued, lastStage, summaryID,
+	).Scan(&jobID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, stage := range doneStages {
+		if _, err := tx.Exec(`INSERT INTO summary_job_events (job_id, stage) VALUES ($1, $2)`, jobID, stage); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	q.notify()
+	return jobID, summaryID, nil
+}
+
+// Retry menjalankan ulang satu ringkasan yang gagal lewat job baru. Baris summaries-nya dipakai lagi
+// (status balik ke pending), style / provider / no_cache ikut job terakhir ringkasan itu.
+func (q *Queue) Retry(summaryID int) (int, error) {
+	tx, err := q.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE summaries SET status = $1, error_code = NULL, error_message = NULL WHERE id = $2 AND status = $3`,
+		models.SummaryPending, summaryID, models.SummaryFailed)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrNotRetryable
+	}
+
+	var jobID int
+	err = tx.QueryRow(`
+		INSERT INTO summary_jobs (pdf_id, style, provider, no_cache, status, summary_id)
+		SELECT s.pdf_id, s.summary_style, j.provider, COALESCE(j.no_cache, FALSE), $2, s.id
+		FROM summaries s
+		LEFT JOIN summary_jobs j ON j.id = (SELECT MAX(id) FROM summary_jobs WHERE summary_id = s.id)
+		WHERE s.id = $1
+		RETURNING id
+	`, summaryID, models.JobQueued).Scan(&jobID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	q.notify()
+	return jobID, nil
+}
+
+// recordStage menyimpan tahapan terbaru job + event-nya. Gagal simpan event tidak menggagalkan job.
+func (q *Queue) recordStage(jobID int, stage, message string) {
+	var msg interface{}
+	if message != "" {
+		msg = message
+	}
+	if _, err := q.DB.Exec(`UPDATE summary_jobs SET stage = $1 WHERE id = $2`, stage, jobID); err != nil {
+		log.Printf("Failed to update stage of summary job %d: %v", jobID, err)
+	}
+	if _, err := q.DB.Exec(`INSERT INTO summary_job_events (job_id, stage, message) VALUES ($1, $2, $3)`, jobID, stage, msg); err != nil {
+		log.Printf("Failed to record stage of summary job %d: %v", jobID, err)
+	}
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default: //sudah ada sinyal yang nunggu
+	}
+}
+
+func (q *Queue) worker(workerID string) {
+	for {
+		job, err := q.claim(workerID)
+		if err != nil {
+			log.Printf("Summary job claim failed: %v", err)
+		}
+		if job != nil {
+			q.runLeased(job, workerID)
+			continue //langsung cek job berikutnya
+		}
+
+		select {
+		case <-q.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// claim mengambil satu job queued (atau running yang lease-nya habis) paling lama dan memasang lease atas nama workerID.
+// SKIP LOCKED biar dua worker tidak dapat job yang sama.
+// SQLite tidak punya row lock, tapi penulisnya memang cuma satu per waktu jadi UPDATE ini sudah atomik.
+func (q *Queue) claim(workerID string) (*models.SummaryJob, error) {
+	lock := "FOR UPDATE SKIP LOCKED"
+	if q.driver == "sqlite" {
+		lock = ""
+	}
+	var job models.SummaryJob
+	var summaryID sql.NullInt64
+	now := time.Now().UTC()
+	err := q.DB.QueryRow(`
+		UPDATE summary_jobs
+		SET status = $1, started_at = NOW(), attempts = attempts + 1, locked_until = $3, worker_id = $4
+		WHERE id = (
+			SELECT id FROM summary_jobs
+			WHERE status = $2 OR (status = $1 AND (locked_until IS NULL OR locked_until < $5))
+			ORDER BY id
+			`+lock+`
+			LIMIT 1
+		)
+		RETURNING id, pdf_id, style, COALESCE(provider, ''), no_cache, attempts, summary_id
+	`, models.JobRunning, models.JobQueued, now.Add(q.Lease), workerID, now).Scan(&job.ID, &job.PdfID, &job.Style, &job.Provider, &job.NoCache, &job.Attempts, &summaryID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.Status = models.JobRunning
+	if summaryID.Valid {
+		id := int(summaryID.Int64)
+		job.SummaryID = &id
+	}
+	return &job, nil
+}
+
+// runLeased menjalankan job sambil memperpanjang lease-nya tiap Lease/3 (heartbeat) sampai selesai.
+// Kalau lease hilang (habis lalu diambil worker lain), ctx job dibatalkan supaya summarizer berhenti,
+// dan finish / fail tidak menulis apa pun karena job sudah bukan milik worker ini.
+func (q *Queue) runLeased(job *models.SummaryJob, workerID string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(q.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				res, err := q.DB.Exec(`UPDATE summary_jobs SET locked_until = $1 WHERE id = $2 AND worker_id = $3 AND status = $4`,
+					time.Now().UTC().Add(q.Lease), job.ID, workerID, models.JobRunning)
+				if err != nil {
+					log.Printf("Failed to extend lease of summary job %d: %v", job.ID, err)
+				} else if n, _ := res.RowsAffected(); n == 0 {
+					log.Printf("Lease of summary job %d lost (taken over by another worker), cancelling", job.ID)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	q.run(ctx, job, workerID)
+}
+
+func (q *Queue) run(ctx context.Context, job *models.SummaryJob, workerID string) {
+	// job dari sebelum ada status ringkasan belum punya baris pending
+	if job.SummaryID == nil {
+		id, err := q.Summaries.Create(ctx, &models.Summary{PdfID: job.PdfID, SummaryStyle: job.Style, Status: models.SummaryPending})
+		if err != nil {
+			_ = q.finish(job, workerID, models.JobFailed, "Gagal simpan summary: "+err.Error(), nil, "")
+			return
+		}
+		job.SummaryID = &id
+	}
+	summary := &models.Summary{ID: *job.SummaryID, LanguageDetected: "unknown"}
+
+	// sudah berkali-kali diambil tapi worker-nya mati terus (misal PDF bikin proses crash): berhenti di sini
+	if job.Attempts > q.MaxAttempts {
+		q.fail(job, workerID, summary, models.SummaryErrMaxAttempts,
+			fmt.Sprintf("Job terputus %d kali (batas SUMMARY_JOB_MAX_ATTEMPTS=%d)", job.Attempts-1, q.MaxAttempts))
+		return
+	}
+
+	pdf, err := q.PDFs.Get(ctx, job.PdfID)
+	if err != nil {
+		q.fail(job, workerID, summary, models.SummaryErrPDFNotFound, "PDF not found: "+err.Error())
+		return
+	}
+
+	// summarizer butuh file di disk; untuk S3 didownload dulu ke file sementara
+	fp, cleanup, err := storage.LocalCopy(ctx, q.Storage, pdf.Filepath)
+	if err != nil {
+		q.fail(job, workerID, summary, models.SummaryErrStorage, "Gagal baca PDF dari storage: "+err.Error())
+		return
+	}
+	defer cleanup()
+
+	summarizer, err := q.Summarizers.Get(job.Provider)
+	if err != nil {
+		q.fail(job, workerID, summary, models.SummaryErrUnknownProvider, err.Error())
+		return
+	}
+
+	q.recordStage(job.ID, models.StageSentToSummarizer, summarizer.Name())
+	result, err := summarizer.Summarize(ctx, services.SummaryRequest{
+		FilePath:      fp,
+		Style:         job.Style,
+		ContentSHA256: pdf.ContentSHA256,
+		NoCache:       job.NoCache,
+		Progress: func(done, total int) {
+			q.recordStage(job.ID, models.StageChunkSummarized, fmt.Sprintf("%d/%d", done, total))
+		},
+		Fallback: func(from, to string, err error) {
+			q.recordStage(job.ID, models.StageProviderFallback, fmt.Sprintf("%s -> %s: %v", from, to, err))
+		},
+	})
+	if err != nil {
+		log.Printf("Summarizer %s failed for job %d: %v", summarizer.Name(), job.ID, err)
+		q.fail(job, workerID, summary, services.ErrorCode(err), err.Error())
+		return
+	}
+
+	summary.SummaryText = result.Summary
+	summary.LanguageDetected = result.Language
+	summary.ProcessTimeMs = result.DurationMs
+	summary.ChunksCount, summary.CharsCovered, summary.TotalChars = Coverage(result)
+	summary.CacheHit = result.CacheHit
+	summary.Provider = result.Provider
+	summary.Model = result.Model
+	summary.Status = models.SummarySucceeded
+	err = q.finish(job, workerID, models.JobSucceeded, "", summary, models.StageSummaryStored)
+	if err == ErrLeaseLost {
+		return
+	}
+	if err != nil {
+		// baris ringkasan jangan tertinggal pending: tandai failed supaya bisa di-retry
+		summary.SummaryText = ""
+		q.fail(job, workerID, summary, models.SummaryErrDatabase, "Gagal simpan summary: "+err.Error())
+		return
+	}
+	if err := q.Summaries.SaveChunks(context.Background(), summary.ID, result.Chunks); err != nil {
+		log.Printf("Failed to save chunk summaries of summary %d: %v", summary.ID, err)
+	}
+}
+
+// fail menandai ringkasan job ini failed (bisa di-retry lewat POST /pdf/:id/retry) lalu menutup job-nya.
+func (q *Queue) fail(job *models.SummaryJob, workerID string, summary *models.Summary, code, message string) {
+	summary.Status = models.SummaryFailed
+	summary.ErrorCode = code
+	summary.ErrorMessage = message
+	if err := q.finish(job, workerID, models.JobFailed, message, summary, ""); err != nil && err != ErrLeaseLost {
+		log.Printf("Failed to mark summary job %d as failed: %v", job.ID, err)
+	}
+}
+
+// finish menutup job (+ menyimpan hasil ringkasannya kalau summary != nil) dalam satu transaksi,
+// tapi cuma kalau job masih running atas nama workerID. ErrLeaseLost = job sudah diambil worker lain,
+// tidak ada yang ditulis. stage != "" ikut dicatat sebagai tahapan terakhir.
+func (q *Queue) finish(job *models.SummaryJob, workerID, status, errMsg string, summary *models.Summary, stage string) error {
+	ctx := context.Background() //ctx job bisa sudah dibatalkan, hasil tetap disimpan kalau lease masih dipegang
+	tx, err := q.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var errVal interface{}
+	if errMsg != "" {
+		errVal = errMsg
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE summary_jobs SET status = $1, summary_id = $2, error = $3, finished_at = NOW(), locked_until = NULL,
+		       stage = COALESCE(NULLIF($4, ''), stage)
+		WHERE id = $5 AND worker_id = $6 AND status = $7`,
+		status, job.SummaryID, errVal, stage, job.ID, workerID, models.JobRunning,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		log.Printf("Summary job %d is no longer leased by %s, result discarded", job.ID, workerID)
+		return ErrLeaseLost
+	}
+	if stage != "" {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO summary_job_events (job_id, stage) VALUES ($1, $2)`, job.ID, stage); err != nil {
+			return err
+		}
+	}
+	if summary != nil {
+		if err := q.Summaries.CompleteTx(ctx, tx, summary); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//worker pool buat summarization, jadi /upload/complete ga perlu nunggu AI selesai
@@ -0,0 +1,370 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/services"
+	"pdf-backend-fiber/internal/storage"
+)
+
+// blockingSummarizer menunggu sampai ctx dibatalkan, started ditutup begitu Summarize dipanggil.
+type blockingSummarizer struct {
+	started chan struct{}
+	err     chan error
+}
+
+func (b *blockingSummarizer) Name() string { return "blocking" }
+
+func (b *blockingSummarizer) Summarize(ctx context.Context, req services.SummaryRequest) (*services.SummaryResult, error) {
+	close(b.started)
+	<-ctx.Done()
+	b.err <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func newTestQueue(t *testing.T, lease time.Duration) (*Queue, int) {
+	t.Helper()
+	db := dbtest.Open(t)
+	userID, workspaceID := dbtest.User(t, db, "a@b.c")
+	pdfID := dbtest.PDF(t, db, workspaceID, userID, "a.pdf")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.pdf"), []byte("%PDF-1.4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := dbtest.Config(dir)
+	cfg.Summarizer = "extractive"
+	cfg.SummaryJobLease = lease
+	cfg.SummaryJobMaxAttempts = 3
+	return NewQueue(db, cfg, storage.NewLocal(dir)), pdfID
+}
+
+type jobRow struct {
+	status, workerID, summaryStatus string
+	attempts                        int
+}
+
+func loadJobRow(t *testing.T, db *sql.DB, jobID int) jobRow {
+	t.Helper()
+	var r jobRow
+	err := db.QueryRow(`
+		SELECT j.status, COALESCE(j.worker_id, ''), j.attempts, s.status
+		FROM summary_jobs j JOIN summaries s ON s.id = j.summary_id WHERE j.id = $1`, jobID,
+	).Scan(&r.status, &r.workerID, &r.attempts, &r.summaryStatus)
+	if err != nil {
+		t.Fatalf("load job %d: %v", jobID, err)
+	}
+	return r
+}
+
+func TestLeaseTakeover(t *testing.T) {
+	tests := []struct {
+		name  string
+		stale func(q *Queue, job *models.SummaryJob, summary *models.Summary) //yang dilakukan worker lama setelah lease-nya diambil
+	}{
+		{"worker lama selesai sukses", func(q *Queue, job *models.SummaryJob, summary *models.Summary) {
+			summary.Status = models.SummarySucceeded
+			summary.SummaryText = "hasil basi"
+			if err := q.finish(job, "A", models.JobSucceeded, "", summary, models.StageSummaryStored); err != ErrLeaseLost {
+				t.Fatalf("stale finish err = %v, want ErrLeaseLost", err)
+			}
+		}},
+		{"worker lama gagal", func(q *Queue, job *models.SummaryJob, summary *models.Summary) {
+			q.fail(job, "A", summary, services.ErrCodeTimeout, "timeout")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, pdfID := newTestQueue(t, time.Minute)
+			jobID, _, err := q.Enqueue(pdfID, "standard", "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stale, err := q.claim("A")
+			if err != nil || stale == nil || stale.ID != jobID {
+				t.Fatalf("claim A = %+v, %v", stale, err)
+			}
+			if again, _ := q.claim("B"); again != nil {
+				t.Fatalf("B claimed job %d while A's lease is still valid", again.ID)
+			}
+
+			// lease A habis (misal proses A hang), B mengambil alih
+			if _, err := q.DB.Exec(`UPDATE summary_jobs SET locked_until = $1 WHERE id = $2`, time.Now().UTC().Add(-time.Second), jobID); err != nil {
+				t.Fatal(err)
+			}
+			fresh, err := q.claim("B")
+			if err != nil || fresh == nil || fresh.ID != jobID {
+				t.Fatalf("claim B = %+v, %v", fresh, err)
+			}
+			if got := loadJobRow(t, q.DB, jobID); got.workerID != "B" || got.attempts != 2 {
+				t.Fatalf("after takeover: %+v", got)
+			}
+
+			tt.stale(q, stale, &models.Summary{ID: *stale.SummaryID, LanguageDetected: "unknown"})
+			if got := loadJobRow(t, q.DB, jobID); got != (jobRow{models.JobRunning, "B", models.SummaryPending, 2}) {
+				t.Fatalf("stale worker overwrote job: %+v", got)
+			}
+
+			summary := &models.Summary{ID: *fresh.SummaryID, SummaryText: "hasil B", LanguageDetected: "id", Status: models.SummarySucceeded}
+			if err := q.finish(fresh, "B", models.JobSucceeded, "", summary, models.StageSummaryStored); err != nil {
+				t.Fatalf("finish B: %v", err)
+			}
+			if got := loadJobRow(t, q.DB, jobID); got.status != models.JobSucceeded || got.summaryStatus != models.SummarySucceeded {
+				t.Fatalf("after B finished: %+v", got)
+			}
+		})
+	}
+}
+
+func TestRunLeasedCancelsWhenLeaseLost(t *testing.T) {
+	q, pdfID := newTestQueue(t, 30*time.Millisecond)
+	blocking := &blockingSummarizer{started: make(chan struct{}), err: make(chan error, 1)}
+	q.Summarizers.Register(blocking)
+
+	jobID, _, err := q.Enqueue(pdfID, "standard", "blocking", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := q.claim("A")
+	if err != nil || job == nil {
+		t.Fatalf("claim A = %+v, %v", job, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.runLeased(job, "A")
+		close(done)
+	}()
+
+	<-blocking.started
+	if _, err := q.DB.Exec(`UPDATE summary_jobs SET worker_id = 'B' WHERE id = $1`, jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runLeased did not stop after losing its lease")
+	}
+	if err := <-blocking.err; err != context.Canceled {
+		t.Fatalf("summarizer ctx err = %v, want context.Canceled", err)
+	}
+	if got := loadJobRow(t, q.DB, jobID); got.status != models.JobRunning || got.workerID != "B" || got.summaryStatus != models.SummaryPending {
+		t.Fatalf("job after lost lease: %+v", got)
+	}
+}
+
+// stubSummarizer = provider palsu untuk chain: gagal dengan err, atau berhasil dengan model itu.
+type stubSummarizer struct {
+	name, model string
+	err         error
+}
+
+func (s *stubSummarizer) Name() string { return s.name }
+
+func (s *stubSummarizer) Summarize(ctx context.Context, req services.SummaryRequest) (*services.SummaryResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &services.SummaryResult{Summary: "ringkasan dari " + s.name, Language: "id", Provider: s.name, Model: s.model}, nil
+}
+
+func TestRunRecordsFallbackProvider(t *testing.T) {
+	down := &services.ServiceError{Kind: services.ErrKindTransport, Err: errors.New("connection refused")}
+	tests := []struct {
+		name         string
+		chain        []*stubSummarizer
+		wantStatus   string
+		wantProvider string
+		wantModel    string
+		wantCode     string
+		wantFallback bool //event provider_fallback tercatat
+	}{
+		{"primary gagal, secondary berhasil",
+			[]*stubSummarizer{{name: "primary", err: down}, {name: "secondary", model: "llama3"}},
+			models.SummarySucceeded, "secondary", "llama3", "", true},
+		{"primary berhasil",
+			[]*stubSummarizer{{name: "primary", model: "gemini"}, {name: "secondary", model: "llama3"}},
+			models.SummarySucceeded, "primary", "gemini", "", false},
+		{"semua gagal",
+			[]*stubSummarizer{{name: "primary", err: down}, {name: "secondary", err: down}},
+			models.SummaryFailed, "", "", services.ErrCodeUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, pdfID := newTestQueue(t, time.Minute)
+			q.Summarizers.Chain = nil
+			for _, s := range tt.chain {
+				q.Summarizers.Register(s)
+				q.Summarizers.Chain = append(q.Summarizers.Chain, s.name)
+			}
+
+			jobID, summaryID, err := q.Enqueue(pdfID, "standard", "", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			job, err := q.claim("A")
+			if err != nil || job == nil {
+				t.Fatalf("claim = %+v, %v", job, err)
+			}
+			q.runLeased(job, "A")
+
+			var status, provider, model, code string
+			err = q.DB.QueryRow(`SELECT status, COALESCE(provider, ''), COALESCE(model, ''), COALESCE(error_code, '') FROM summaries WHERE id = $1`,
+				summaryID).Scan(&status, &provider, &model, &code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.wantStatus || provider != tt.wantProvider || model != tt.wantModel || code != tt.wantCode {
+				t.Fatalf("summary = %s %s/%s %q, want %s %s/%s %q", status, provider, model, code, tt.wantStatus, tt.wantProvider, tt.wantModel, tt.wantCode)
+			}
+
+			var fallbacks int
+			if err := q.DB.QueryRow(`SELECT COUNT(*) FROM summary_job_events WHERE job_id = $1 AND stage = $2`, jobID, models.StageProviderFallback).Scan(&fallbacks); err != nil {
+				t.Fatal(err)
+			}
+			if (fallbacks > 0) != tt.wantFallback {
+				t.Fatalf("provider_fallback events = %d, want any: %v", fallbacks, tt.wantFallback)
+			}
+		})
+	}
+}
+
+type summaryState struct {
+	summary, code, jobStatus, pdfStatus, latest string
+}
+
+// summaryStateOf membaca status ringkasan, job terakhirnya dan kolom ringkasan di pdf_files (diisi trigger).
+func summaryStateOf(t *testing.T, db *sql.DB, summaryID, jobID int) summaryState {
+	t.Helper()
+	var s summaryState
+	err := db.QueryRow(`
+		SELECT s.status, COALESCE(s.error_code, ''), j.status, COALESCE(p.summary_status, ''), COALESCE(p.latest_summary, '')
+		FROM summaries s
+		JOIN summary_jobs j ON j.id = $2
+		JOIN pdf_files p ON p.id = s.pdf_id
+		WHERE s.id = $1`, summaryID, jobID,
+	).Scan(&s.summary, &s.code, &s.jobStatus, &s.pdfStatus, &s.latest)
+	if err != nil {
+		t.Fatalf("load summary %d: %v", summaryID, err)
+	}
+	return s
+}
+
+// ringkasan gagal -> retry -> pending di job baru -> succeeded, dengan status ikut ke pdf_files.
+func TestSummaryRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		breakJob func(t *testing.T, q *Queue, stub *stubSummarizer, jobID int) //bikin run pertama gagal
+		fixJob   func(t *testing.T, q *Queue, stub *stubSummarizer)            //penyebabnya dibereskan sebelum retry
+		wantCode string
+	}{
+		{"summarizer gagal", "stub",
+			func(t *testing.T, q *Queue, stub *stubSummarizer, jobID int) {
+				stub.err = &services.ServiceError{Kind: services.ErrKindTransport, Err: errors.New("connection refused")}
+			},
+			func(t *testing.T, q *Queue, stub *stubSummarizer) { stub.err = nil },
+			services.ErrCodeUnavailable},
+		{"file hilang di storage", "stub",
+			func(t *testing.T, q *Queue, stub *stubSummarizer, jobID int) {
+				if err := q.Storage.Delete(context.Background(), "a.pdf"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			func(t *testing.T, q *Queue, stub *stubSummarizer) {
+				if err := q.Storage.Put(context.Background(), "a.pdf", strings.NewReader("%PDF-1.4\n"), 9, "application/pdf"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			models.SummaryErrStorage},
+		{"provider tidak dikenal", "nanti",
+			func(t *testing.T, q *Queue, stub *stubSummarizer, jobID int) {},
+			func(t *testing.T, q *Queue, stub *stubSummarizer) {
+				q.Summarizers.Register(&stubSummarizer{name: "nanti"})
+			},
+			models.SummaryErrUnknownProvider},
+		{"job terputus berkali-kali", "stub",
+			func(t *testing.T, q *Queue, stub *stubSummarizer, jobID int) {
+				if _, err := q.DB.Exec(`UPDATE summary_jobs SET attempts = $1 WHERE id = $2`, q.MaxAttempts, jobID); err != nil {
+					t.Fatal(err)
+				}
+			},
+			func(t *testing.T, q *Queue, stub *stubSummarizer) {}, //job retry mulai dari attempts 0
+			models.SummaryErrMaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, pdfID := newTestQueue(t, time.Minute)
+			stub := &stubSummarizer{name: "stub", model: "m1"}
+			q.Summarizers.Register(stub)
+
+			runNext := func() {
+				t.Helper()
+				job, err := q.claim("A")
+				if err != nil || job == nil {
+					t.Fatalf("claim = %+v, %v", job, err)
+				}
+				q.runLeased(job, "A")
+			}
+
+			jobID, summaryID, err := q.Enqueue(pdfID, "standard", tt.provider, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summaryStateOf(t, q.DB, summaryID, jobID); got != (summaryState{models.SummaryPending, "", models.JobQueued, models.JobQueued, ""}) {
+				t.Fatalf("after enqueue: %+v", got)
+			}
+			tt.breakJob(t, q, stub, jobID)
+			runNext()
+			if got := summaryStateOf(t, q.DB, summaryID, jobID); got != (summaryState{models.SummaryFailed, tt.wantCode, models.JobFailed, models.JobFailed, ""}) {
+				t.Fatalf("after failed run: %+v", got)
+			}
+
+			tt.fixJob(t, q, stub)
+			retryID, err := q.Retry(summaryID)
+			if err != nil || retryID == jobID {
+				t.Fatalf("retry = %d, %v", retryID, err)
+			}
+			if got := summaryStateOf(t, q.DB, summaryID, retryID); got != (summaryState{models.SummaryPending, "", models.JobQueued, models.JobQueued, ""}) {
+				t.Fatalf("after retry: %+v", got)
+			}
+			// job baru mewarisi provider + no_cache job lama
+			var provider string
+			var noCache bool
+			if err := q.DB.QueryRow(`SELECT COALESCE(provider, ''), no_cache FROM summary_jobs WHERE id = $1`, retryID).Scan(&provider, &noCache); err != nil {
+				t.Fatal(err)
+			}
+			if provider != tt.provider || !noCache {
+				t.Fatalf("retry job provider=%q no_cache=%v", provider, noCache)
+			}
+			if _, err := q.Retry(summaryID); err != ErrNotRetryable {
+				t.Fatalf("retry while pending err = %v, want ErrNotRetryable", err)
+			}
+
+			runNext()
+			want := summaryState{models.SummarySucceeded, "", models.JobSucceeded, models.JobSucceeded, "ringkasan dari " + tt.provider}
+			if got := summaryStateOf(t, q.DB, summaryID, retryID); got != want {
+				t.Fatalf("after retried run: %+v, want %+v", got, want)
+			}
+			if _, err := q.Retry(summaryID); err != ErrNotRetryable {
+				t.Fatalf("retry after success err = %v, want ErrNotRetryable", err)
+			}
+		})
+	}
+
+	q, _ := newTestQueue(t, time.Minute)
+	if _, err := q.Retry(9999); err != ErrNotRetryable {
+		t.Fatalf("retry unknown summary err = %v, want ErrNotRetryable", err)
+	}
+}
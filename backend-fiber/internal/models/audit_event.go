@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Action yang dicatat di audit_events (format <target>.<aksi>).
+const (
+	AuditPDFUpload       = "pdf.upload"
+	AuditPDFRename       = "pdf.rename"
+	AuditPDFResummarize  = "pdf.resummarize"
+	AuditPDFRetry        = "pdf.retry" //ringkasan gagal dijalankan ulang
+	AuditPDFExport       = "pdf.export"
+	AuditPDFDelete       = "pdf.delete" //masuk trash
+	AuditPDFRestore      = "pdf.restore"
+	AuditPDFPurge        = "pdf.purge" //hapus permanen (manual atau otomatis setelah retensi)
+	AuditPDFMove         = "pdf.move"
+	AuditPDFTag          = "pdf.tag"    //tag ditambah / dilepas
+	AuditPDFFolder       = "pdf.folder" //pindah folder
+	AuditTagCreate       = "tag.create"
+	AuditTagUpdate       = "tag.update"
+	AuditTagDelete       = "tag.delete"
+	AuditFolderCreate    = "folder.create"
+	AuditFolderUpdate    = "folder.update"
+	AuditFolderDelete    = "folder.delete"
+	AuditShareCreate     = "share.create"
+	AuditShareRevoke     = "share.revoke"
+	AuditWorkspaceCreate = "workspace.create"
+	AuditWorkspaceRename = "workspace.rename"
+	AuditWorkspaceDelete = "workspace.delete"
+	AuditMemberAdd       = "member.add"
+	AuditMemberUpdate    = "member.update"
+	AuditMemberRemove    = "member.remove"
+	AuditUserRegister    = "user.register"
+	AuditAPIKeyCreate    = "api_key.create"
+	AuditAPIKeyRevoke    = "api_key.revoke"
+)
+
+// Jenis target event.
+const (
+	TargetPDF       = "pdf"
+	TargetShareLink = "share_link"
+	TargetWorkspace = "workspace"
+	TargetUser      = "user"
+	TargetAPIKey    = "api_key"
+	TargetTag       = "tag"
+	TargetFolder    = "folder"
+)
+
+// AuditEvent = satu baris audit_events. Before/After = snapshot JSON nilai yang berubah (boleh kosong).
+type AuditEvent struct {
+	ID          int64           `json:"id" db:"id"`
+	ActorUserID *int            `json:"actor_user_id" db:"actor_user_id"`
+	ActorEmail  string          `json:"actor_email" db:"actor_email"`
+	AuthMethod  string          `json:"auth_method" db:"auth_method"` //jwt / api_key
+	Action      string          `json:"action" db:"action"`
+	TargetType  string          `json:"target_type" db:"target_type"`
+	TargetID    string          `json:"target_id" db:"target_id"`
+	WorkspaceID *int            `json:"workspace_id" db:"workspace_id"`
+	RequestID   string          `json:"request_id" db:"request_id"`
+	IP          string          `json:"ip" db:"ip"`
+	Before      json.RawMessage `json:"before" db:"before"`
+	After       json.RawMessage `json:"after" db:"after"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+//audit log untuk compliance: tabelnya append-only, tidak pernah di-update / dihapus
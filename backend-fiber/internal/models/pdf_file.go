@@ -6,11 +6,34 @@ type PdfFile struct {
 	ID               int       `json:"id" db:"id"`
 	Filename         string    `json:"filename" db:"filename"`
 	OriginalFilename string    `json:"original_filename" db:"original_filename"`
-	Filepath         string    `json:"filepath" db:"filepath"`
+	Filepath         string    `json:"-" db:"filepath"` //key di storage (local/S3), bukan path absolut
 	Filesize         int64     `json:"filesize" db:"filesize"`
 	UploadTime       time.Time `json:"upload_time" db:"upload_time"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	LatestSummary    string    `json:"latest_summary" db:"latest_summary"`
+	SummaryStatus    string    `json:"summary_status" db:"summary_status"` //status job summary terakhir (queued/running/succeeded/failed)
+
+	// metadata dari internal/pdfinfo (NULL untuk PDF lama sebelum fitur ini ada)
+	PageCount    *int       `json:"page_count" db:"page_count"`
+	PdfVersion   string     `json:"pdf_version" db:"pdf_version"`
+	PdfTitle     string     `json:"pdf_title" db:"pdf_title"`
+	PdfAuthor    string     `json:"pdf_author" db:"pdf_author"`
+	PdfSubject   string     `json:"pdf_subject" db:"pdf_subject"`
+	PdfCreatedAt *time.Time `json:"pdf_created_at" db:"pdf_created_at"`
+	IsEncrypted  bool       `json:"is_encrypted" db:"is_encrypted"`
+
+	// SHA-256 isi file; AllowDuplicate = salinan yang sengaja disimpan lewat ?force=true
+	ContentSHA256  string `json:"content_sha256" db:"content_sha256"`
+	AllowDuplicate bool   `json:"allow_duplicate" db:"allow_duplicate"`
+
+	UserID      *int       `json:"user_id" db:"user_id"` //uploader
+	WorkspaceID int        `json:"workspace_id" db:"workspace_id"`
+	FolderID    *int       `json:"folder_id" db:"folder_id"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// teks hasil ekstraksi untuk GET /search, cuma diisi waktu insert
+	ExtractedText string `json:"-" db:"extracted_text"`
+	TextLanguage  string `json:"-" db:"text_language"`
 }
 
 type HistoryItem struct {
@@ -21,6 +44,8 @@ type HistoryItem struct {
 	UploadedAt  time.Time `json:"uploaded_at"`
 	ProcessedAt time.Time `json:"processed_at,omitempty"`
 	Summary     string    `json:"summary,omitempty"`
+	FolderID    *int      `json:"folder_id"`
+	Tags        []Tag     `json:"tags"`
 }
 
 type HistoryResponse struct {
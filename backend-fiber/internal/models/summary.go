@@ -2,6 +2,22 @@ package models
 
 import "time"
 
+// status ringkasan (kolom summaries.status)
+const (
+	SummaryPending   = "pending" //job masih antre / jalan
+	SummarySucceeded = "succeeded"
+	SummaryFailed    = "failed" //summary_text kosong, alasannya di error_code + error_message
+)
+
+// kode error di luar services.ErrorCode (gagal sebelum summarizer dipanggil)
+const (
+	SummaryErrPDFNotFound     = "pdf_not_found"
+	SummaryErrStorage         = "storage_error"
+	SummaryErrUnknownProvider = "unknown_provider"
+	SummaryErrDatabase        = "database_error" //ringkasan sudah jadi tapi gagal disimpan
+	SummaryErrMaxAttempts     = "max_attempts"   //job terputus (worker mati) berkali-kali, tidak diambil lagi
+)
+
 type Summary struct {
 	ID               int       `json:"id" db:"id"`
 	PdfID            int       `json:"pdf_id" db:"pdf_id"`
@@ -10,6 +26,30 @@ type Summary struct {
 	ProcessTimeMs    int64     `json:"process_time_ms" db:"process_time_ms"`
 	LanguageDetected string    `json:"language_detected" db:"language_detected"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+
+	// coverage map-reduce (NULL kalau dokumen cukup pendek untuk diringkas sekali jalan)
+	ChunksCount  *int `json:"chunks_count" db:"chunks_count"`
+	CharsCovered *int `json:"chars_covered" db:"chars_covered"`
+	TotalChars   *int `json:"total_chars" db:"total_chars"`
+	CacheHit     bool `json:"cache_hit" db:"cache_hit"`
+	UserID       *int `json:"-" db:"user_id"`
+
+	Status       string `json:"status" db:"status"`
+	ErrorCode    string `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage string `json:"error_message,omitempty" db:"error_message"`
+	Attempts     int    `json:"attempts" db:"attempts"` //berapa kali ringkasan ini dicoba generate (retry menambah 1)
+
+	// provider + model yang menghasilkan ringkasan ini (kosong = ringkasan lama / gagal)
+	Provider string `json:"provider" db:"provider"`
+	Model    string `json:"model" db:"model"`
+}
+
+// ChunkSummary = ringkasan parsial satu chunk dokumen panjang (hasil tahap map), disimpan di summary_chunks.
+type ChunkSummary struct {
+	Index   int
+	Start   int
+	End     int
+	Summary string
 }
 
 type SummaryResponse struct {
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// status job summarization
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// tahapan proses yang dikirim ke client lewat SSE (/jobs/:id/events)
+const (
+	StageChunksAssembled  = "chunks_assembled"
+	StagePDFValidated     = "pdf_validated"
+	StageSentToSummarizer = "sent_to_summarizer" //message = provider (atau chain "python,openai") yang dipakai
+	StageChunkSummarized  = "chunk_summarized"   //dokumen panjang: satu chunk selesai diringkas, message "i/n"
+	StageProviderFallback = "provider_fallback"  //provider gagal, pindah ke provider berikutnya di chain, message "python -> openai: error"
+	StageSummaryStored    = "summary_stored"
+)
+
+type SummaryJob struct {
+	ID         int        `json:"id" db:"id"`
+	PdfID      int        `json:"pdf_id" db:"pdf_id"`
+	Style      string     `json:"style" db:"style"`
+	Provider   string     `json:"provider,omitempty" db:"provider"`
+	NoCache    bool       `json:"no_cache" db:"no_cache"`
+	Status     string     `json:"status" db:"status"`
+	Stage      string     `json:"stage,omitempty" db:"stage"`
+	Attempts   int        `json:"attempts" db:"attempts"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	SummaryID  *int       `json:"summary_id,omitempty" db:"summary_id"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+type JobEvent struct {
+	ID        int       `json:"id" db:"id"`
+	JobID     int       `json:"job_id" db:"job_id"`
+	Stage     string    `json:"stage" db:"stage"`
+	Message   string    `json:"message,omitempty" db:"message"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
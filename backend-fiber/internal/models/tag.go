@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Tag = label PDF, berlaku di satu workspace (nama unik per workspace, tidak peduli huruf besar/kecil).
+type Tag struct {
+	ID          int       `json:"id" db:"id"`
+	WorkspaceID int       `json:"workspace_id" db:"workspace_id"`
+	Name        string    `json:"name" db:"name"`
+	Color       string    `json:"color" db:"color"` //hex "#RRGGBB", boleh kosong
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	PDFCount    *int      `json:"pdf_count,omitempty"`
+}
+
+// Folder = koleksi PDF, bisa bersarang. ParentID nil = langsung di root workspace.
+type Folder struct {
+	ID          int       `json:"id" db:"id"`
+	WorkspaceID int       `json:"workspace_id" db:"workspace_id"`
+	ParentID    *int      `json:"parent_id" db:"parent_id"`
+	Name        string    `json:"name" db:"name"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	PDFCount    *int      `json:"pdf_count,omitempty"`
+}
+
+//tag = banyak label per PDF, folder = satu tempat per PDF
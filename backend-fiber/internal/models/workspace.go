@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Role anggota workspace, urut dari yang paling tinggi.
+const (
+	RoleOwner  = "owner"  //semua hak + kelola anggota + hapus PDF
+	RoleEditor = "editor" //upload, resummarize, rename, share
+	RoleViewer = "viewer" //cuma baca
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAtLeast = apakah role punya hak minimal setara min.
+func RoleAtLeast(role, min string) bool {
+	return roleRank[role] >= roleRank[min] && roleRank[role] > 0
+}
+
+type Workspace struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Personal  bool      `json:"personal" db:"personal"` //workspace pribadi yang dibuat otomatis waktu register
+	CreatedBy int       `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Role      string    `json:"role,omitempty"` //role user yang sedang login
+}
+
+type WorkspaceMember struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	Name      string    `json:"name" db:"name"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+//workspace per departemen, akses PDF dicek dari role anggota
@@ -0,0 +1,228 @@
+package pdfinfo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrEncrypted dikembalikan Validate kalau PDF dienkripsi (walaupun tanpa password user).
+var ErrEncrypted = errors.New("PDF terenkripsi / diproteksi password, tidak bisa diringkas")
+
+// ErrNoText dikembalikan Validate kalau PDF tidak punya teks (misal hasil scan tanpa OCR).
+var ErrNoText = errors.New("PDF tidak mengandung teks (kemungkinan hasil scan), tidak bisa diringkas")
+
+var headerVersionRegex = regexp.MustCompile(`%PDF-(\d\.\d)`)
+
+// Info = hasil inspeksi satu file PDF.
+type Info struct {
+	PageCount    int        `json:"page_count"`
+	Version      string     `json:"pdf_version"`
+	Title        string     `json:"title,omitempty"`
+	Author       string     `json:"author,omitempty"`
+	Subject      string     `json:"subject,omitempty"`
+	CreationDate *time.Time `json:"creation_date,omitempty"`
+	Encrypted    bool       `json:"encrypted"`
+	HasText      bool       `json:"has_text"`
+	Text         string     `json:"-"` //teks lengkap, tidak ikut dikirim ke client
+}
+
+// Validate mengecek apakah PDF layak dikirim ke AI.
+func (i *Info) Validate() error {
+	if i.Encrypted {
+		return ErrEncrypted
+	}
+	if !i.HasText {
+		return ErrNoText
+	}
+	return nil
+}
+
+// Inspect membaca jumlah halaman, versi, Info dictionary, status enkripsi, dan teks dari PDF.
+// PDF terenkripsi tetap menghasilkan Info (Encrypted=true) selama header-nya terbaca.
+func Inspect(filePath string) (info *Info, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	info = &Info{Version: readHeaderVersion(f)}
+
+	r, err := openReader(f, st.Size())
+	if err != nil {
+		if isEncryptionError(err) {
+			info.Encrypted = true
+			return info, nil
+		}
+		return nil, err
+	}
+
+	trailer := r.Trailer()
+	info.Encrypted = !trailer.Key("Encrypt").IsNull()
+	info.PageCount = r.NumPage()
+
+	// /Version di catalog menimpa versi header (PDF yang di-update incremental)
+	if v := trailer.Key("Root").Key("Version").Name(); v != "" {
+		info.Version = v
+	}
+
+	meta := trailer.Key("Info")
+	info.Title = cleanInfoString(meta.Key("Title").Text())
+	info.Author = cleanInfoString(meta.Key("Author").Text())
+	info.Subject = cleanInfoString(meta.Key("Subject").Text())
+	info.CreationDate = parsePDFDate(meta.Key("CreationDate").RawString())
+
+	text, err := plainText(r)
+	if err != nil && !info.Encrypted {
+		return nil, err
+	}
+	info.Text = text
+	info.HasText = strings.TrimSpace(text) != ""
+	return info, nil
+}
+
+// headerFixReaderAt menyamarkan 9 byte pertama jadi "%PDF-1.7\n".
+// Library pdf hanya mau header 1.0-1.7 yang langsung diikuti newline, padahal banyak PDF valid
+// (PDF 2.0, atau header yang diikuti komentar biner di baris yang sama). Offset lain tidak berubah.
+// Dipakai di atas SectionReader yang mulai dari "%PDF-", jadi byte sebelum header tidak ikut tertimpa.
+type headerFixReaderAt struct {
+	io.ReaderAt
+}
+
+var fixedHeader = []byte("%PDF-1.7\n")
+
+func (h headerFixReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := h.ReaderAt.ReadAt(p, off)
+	for i := 0; i < n; i++ {
+		pos := off + int64(i)
+		if pos >= int64(len(fixedHeader)) {
+			break
+		}
+		p[i] = fixedHeader[pos]
+	}
+	return n, err
+}
+
+// headerOffset = posisi "%PDF-" setelah spasi / NUL di awal file (sama seperti isValidPDFFileHeader di upload).
+// Offset xref dihitung dari header, jadi byte sebelumnya dilewati lewat SectionReader.
+func headerOffset(f io.ReaderAt) (int64, error) {
+	buf := make([]byte, 1024)
+	n, err := f.ReadAt(buf, 0)
+	if n == 0 && err != nil {
+		return 0, err
+	}
+	trimmed := bytes.TrimLeft(buf[:n], "\x00\t\n\r\f ")
+	if !bytes.HasPrefix(trimmed, []byte("%PDF-")) {
+		return 0, errors.New("PDF header not found")
+	}
+	return int64(n - len(trimmed)), nil
+}
+
+func openReader(f io.ReaderAt, size int64) (*pdf.Reader, error) {
+	off, err := headerOffset(f)
+	if err != nil {
+		return nil, err
+	}
+	return pdf.NewReader(headerFixReaderAt{io.NewSectionReader(f, off, size-off)}, size-off)
+}
+
+func isEncryptionError(err error) bool {
+	return err == pdf.ErrInvalidPassword || strings.Contains(err.Error(), "encryption")
+}
+
+func plainText(r *pdf.Reader) (string, error) {
+	rd, err := r.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(rd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func readHeaderVersion(f io.ReaderAt) string {
+	buf := make([]byte, 1024)
+	n, _ := f.ReadAt(buf, 0)
+	m := headerVersionRegex.FindSubmatch(bytes.TrimLeft(buf[:n], "\x00\t\n\r\f "))
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
+
+func cleanInfoString(s string) string {
+	return strings.TrimSpace(strings.Trim(s, "\x00"))
+}
+
+// parsePDFDate mengubah format tanggal PDF "D:YYYYMMDDHHmmSSOHH'mm'" jadi time.Time.
+// Bagian yang tidak ada dianggap nol, timezone kosong dianggap UTC.
+func parsePDFDate(raw string) *time.Time {
+	s := strings.TrimPrefix(strings.TrimSpace(raw), "D:")
+	if len(s) < 4 {
+		return nil
+	}
+
+	digits := func(from, to, def int) int {
+		if len(s) < to {
+			return def
+		}
+		v, err := strconv.Atoi(s[from:to])
+		if err != nil {
+			return def
+		}
+		return v
+	}
+	year := digits(0, 4, 0)
+	if year == 0 {
+		return nil
+	}
+	month := digits(4, 6, 1)
+	day := digits(6, 8, 1)
+	hour := digits(8, 10, 0)
+	minute := digits(10, 12, 0)
+	second := digits(12, 14, 0)
+
+	loc := time.UTC
+	if len(s) > 14 && (s[14] == '+' || s[14] == '-') {
+		tz := strings.ReplaceAll(s[15:], "'", "")
+		if len(tz) >= 2 {
+			tzHour, _ := strconv.Atoi(tz[:2])
+			tzMin := 0
+			if len(tz) >= 4 {
+				tzMin, _ = strconv.Atoi(tz[2:4])
+			}
+			offset := tzHour*3600 + tzMin*60
+			if s[14] == '-' {
+				offset = -offset
+			}
+			loc = time.FixedZone("", offset)
+		}
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+	return &t
+}
+
+//cek isi pdf (halaman, versi, metadata, enkripsi) sebelum bayar panggilan AI
@@ -0,0 +1,64 @@
+package pdfinfo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	sample, err := os.ReadFile("testdata/sample.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantErr     bool
+		wantVersion string
+	}{
+		{"pdf biasa", sample, false, "1.4"},
+		{"newline sebelum header", append([]byte("\r\n\n"), sample...), false, "1.4"},
+		{"NUL sebelum header", append([]byte("\x00\x00\x00\x00"), sample...), false, "1.4"},
+		{"spasi dan NUL sebelum header", append([]byte(" \t\x00\n"), sample...), false, "1.4"},
+		{"header PDF 2.0", bytes.Replace(sample, []byte("%PDF-1.4"), []byte("%PDF-2.0"), 1), false, "2.0"},
+		{"bukan pdf", []byte("hello world"), true, ""},
+		{"teks sebelum header", append([]byte("junk"), sample...), true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.pdf")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := Inspect(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Inspect: %v", err)
+			}
+			if info.PageCount != 1 || info.Version != tt.wantVersion || info.Encrypted {
+				t.Errorf("got pages=%d version=%q encrypted=%v", info.PageCount, info.Version, info.Encrypted)
+			}
+			if info.Title != "Contoh" || info.Author != "Tester" || info.CreationDate == nil || info.CreationDate.UTC().Hour() != 20 {
+				t.Errorf("got metadata title=%q author=%q date=%v", info.Title, info.Author, info.CreationDate)
+			}
+			if !strings.Contains(info.Text, "Contoh PDF untuk testing") || info.Validate() != nil {
+				t.Errorf("unexpected text %q", info.Text)
+			}
+
+			text, err := ExtractText(path)
+			if err != nil || text != info.Text {
+				t.Errorf("ExtractText = %q, %v", text, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package pdfinfo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxSearchTextRunes = batas teks yang disimpan untuk full-text search.
+// tsvector Postgres maksimal 1MB, teks sepanjang ini masih aman walaupun kosakatanya banyak.
+const maxSearchTextRunes = 300000
+
+// ExtractText membaca semua teks dari PDF di disk (urut per halaman).
+// Library pdf kadang panic kalau ketemu PDF yang aneh, jadi panic-nya diubah jadi error biasa.
+func ExtractText(filePath string) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	r, err := openReader(f, st.Size())
+	if err != nil {
+		return "", err
+	}
+	return plainText(r)
+}
+
+// SearchText menyiapkan teks PDF untuk disimpan di kolom extracted_text:
+// byte NUL dibuang (ditolak Postgres) dan dipotong ke maxSearchTextRunes.
+func SearchText(text string) string {
+	text = strings.ReplaceAll(text, "\x00", "")
+	if len(text) <= maxSearchTextRunes {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxSearchTextRunes {
+		return text
+	}
+	return string(runes[:maxSearchTextRunes])
+}
+
+//baca teks pdf langsung di go, jadi summarizer selain python ga butuh service lain
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// FolderRepository = akses tabel folders + folder_id di pdf_files.
+type FolderRepository interface {
+	// ListForUser = semua folder (flat) di workspace user (workspaceID 0) atau satu workspace,
+	// PDFCount = jumlah PDF aktif langsung di dalamnya.
+	ListForUser(ctx context.Context, userID, workspaceID int) ([]models.Folder, error)
+	// Create mengisi ID & CreatedAt. ErrDuplicate kalau nama sudah dipakai di lokasi yang sama.
+	Create(ctx context.Context, f *models.Folder, userID int) error
+	Get(ctx context.Context, id int) (*models.Folder, error)
+	// IsWithin = apakah candidate adalah folderID sendiri atau salah satu subfoldernya.
+	IsWithin(ctx context.Context, folderID, candidate int) (bool, error)
+	Update(ctx context.Context, id int, name string, parentID *int) error
+	// Contents = jumlah subfolder + PDF aktif langsung di dalam folder.
+	Contents(ctx context.Context, id int) (subfolders, pdfs int, err error)
+	Delete(ctx context.Context, id int) error
+	// SetPDFFolder memindah PDF ke folder (nil = root), mengembalikan folder lamanya.
+	SetPDFFolder(ctx context.Context, pdfID int, folderID *int) (*int, error)
+}
+
+// SQLFolderRepository: SQL-nya sama di Postgres dan SQLite kecuali FOR UPDATE.
+type SQLFolderRepository struct {
+	DB     *sql.DB
+	Driver string //postgres / sqlite, SQLite tidak kenal FOR UPDATE
+}
+
+func NewFolderRepository(db *sql.DB, driver string) *SQLFolderRepository {
+	return &SQLFolderRepository{DB: db, Driver: driver}
+}
+
+func (r *SQLFolderRepository) ListForUser(ctx context.Context, userID, workspaceID int) ([]models.Folder, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT f.id, f.workspace_id, f.parent_id, f.name, f.created_at,
+		       (SELECT COUNT(*) FROM pdf_files p WHERE p.folder_id = f.id AND p.deleted_at IS NULL)
+		FROM folders f
+		WHERE f.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND ($2 = 0 OR f.workspace_id = $2)
+		ORDER BY f.workspace_id, LOWER(f.name)
+	`, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var f models.Folder
+		var parentID sql.NullInt64
+		var count int
+		if err := rows.Scan(&f.ID, &f.WorkspaceID, &parentID, &f.Name, &f.CreatedAt, &count); err != nil {
+			return nil, err
+		}
+		f.ParentID = intPtr(parentID)
+		f.PDFCount = &count
+		folders = append(folders, f)
+	}
+	return folders, rows.Err()
+}
+
+func (r *SQLFolderRepository) Create(ctx context.Context, f *models.Folder, userID int) error {
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO folders (workspace_id, parent_id, name, created_by) VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`, f.WorkspaceID, nullable(f.ParentID), f.Name, userID).Scan(&f.ID, &f.CreatedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *SQLFolderRepository) Get(ctx context.Context, id int) (*models.Folder, error) {
+	var f models.Folder
+	var parentID sql.NullInt64
+	err := r.DB.QueryRowContext(ctx, `SELECT id, workspace_id, parent_id, name, created_at FROM folders WHERE id = $1`, id).
+		Scan(&f.ID, &f.WorkspaceID, &parentID, &f.Name, &f.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.ParentID = intPtr(parentID)
+	return &f, nil
+}
+
+func (r *SQLFolderRepository) IsWithin(ctx context.Context, folderID, candidate int) (bool, error) {
+	var within bool
+	err := r.DB.QueryRowContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT id FROM folders WHERE id = $1
+			UNION SELECT f.id FROM folders f JOIN sub ON f.parent_id = sub.id
+		)
+		SELECT EXISTS(SELECT 1 FROM sub WHERE id = $2)
+	`, folderID, candidate).Scan(&within)
+	return within, err
+}
+
+func (r *SQLFolderRepository) Update(ctx context.Context, id int, name string, parentID *int) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE folders SET name = $1, parent_id = $2 WHERE id = $3`, name, nullable(parentID), id)
+	if database.IsUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return affectedOne(res, err)
+}
+
+func (r *SQLFolderRepository) Contents(ctx context.Context, id int) (int, int, error) {
+	var subfolders, pdfs int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM folders WHERE parent_id = $1),
+		       (SELECT COUNT(*) FROM pdf_files WHERE folder_id = $1 AND deleted_at IS NULL)
+	`, id).Scan(&subfolders, &pdfs)
+	return subfolders, pdfs, err
+}
+
+func (r *SQLFolderRepository) Delete(ctx context.Context, id int) error {
+	return deleteByID(ctx, r.DB, "folders", id)
+}
+
+func (r *SQLFolderRepository) SetPDFFolder(ctx context.Context, pdfID int, folderID *int) (*int, error) {
+	// folder lama dibaca di transaksi yang sama (dikunci di Postgres; SQLite cuma satu penulis per waktu)
+	lock := " FOR UPDATE"
+	if r.Driver == "sqlite" {
+		lock = ""
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var oldFolder sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT folder_id FROM pdf_files WHERE id = $1`+lock, pdfID).Scan(&oldFolder)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE pdf_files SET folder_id = $1 WHERE id = $2`, nullable(folderID), pdfID); err != nil {
+		return nil, err
+	}
+	return intPtr(oldFolder), tx.Commit()
+}
+
+//for learn, folder bersarang: parent_id menunjuk folder lain, isi subfolder diambil pakai WITH RECURSIVE
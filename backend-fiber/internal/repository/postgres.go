@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// PostgresPdfRepository = implementasi utama (server Postgres, dipakai semua fitur termasuk search).
+type PostgresPdfRepository struct {
+	DB *sql.DB
+}
+
+func (r *PostgresPdfRepository) Get(ctx context.Context, id int) (*models.PdfFile, error) {
+	return scanPDF(r.DB.QueryRowContext(ctx, `SELECT `+pdfColumns+` FROM pdf_files WHERE id = $1`, id))
+}
+
+func (r *PostgresPdfRepository) FindByContentHash(ctx context.Context, contentSHA256 string, workspaceID int) (*models.PdfFile, error) {
+	return findByContentHash(ctx, r.DB, contentSHA256, workspaceID)
+}
+
+func (r *PostgresPdfRepository) Create(ctx context.Context, p *models.PdfFile) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx,
+		`INSERT INTO pdf_files (filename, original_filename, filepath, filesize, upload_time,
+		                        page_count, pdf_version, pdf_title, pdf_author, pdf_subject, pdf_created_at, is_encrypted,
+		                        content_sha256, allow_duplicate, user_id, workspace_id, extracted_text, text_language)
+		 VALUES ($1, $2, $3, $4, NOW(), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12, $13, $14, $15, $16, NULLIF($17, '')) RETURNING id`,
+		p.Filename, p.OriginalFilename, p.Filepath, p.Filesize,
+		nullable(p.PageCount), p.PdfVersion, p.PdfTitle, p.PdfAuthor, p.PdfSubject, p.PdfCreatedAt, p.IsEncrypted,
+		p.ContentSHA256, p.AllowDuplicate, nullable(p.UserID), p.WorkspaceID, p.ExtractedText, p.TextLanguage,
+	).Scan(&id)
+	if database.IsUniqueViolation(err) {
+		return 0, ErrDuplicate
+	}
+	return id, err
+}
+
+func (r *PostgresPdfRepository) Delete(ctx context.Context, id int) error {
+	return deletePDF(ctx, r.DB, id)
+}
+
+func (r *PostgresPdfRepository) Rename(ctx context.Context, id int, originalFilename string) (string, error) {
+	var oldName string
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE pdf_files p SET original_filename = $1
+		FROM (SELECT id, COALESCE(original_filename, filename) AS name FROM pdf_files WHERE id = $2) old
+		WHERE p.id = old.id
+		RETURNING old.name
+	`, originalFilename, id).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return oldName, err
+}
+
+func (r *PostgresPdfRepository) SoftDelete(ctx context.Context, id, userID int) (*models.PdfFile, error) {
+	var p models.PdfFile
+	var deletedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE pdf_files SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, COALESCE(original_filename, filename), deleted_at
+	`, id, userID).Scan(&p.ID, &p.OriginalFilename, &deletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.DeletedAt = timePtr(deletedAt)
+	return &p, nil
+}
+
+func (r *PostgresPdfRepository) Restore(ctx context.Context, id int) (time.Time, error) {
+	var deletedAt time.Time
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE pdf_files p SET deleted_at = NULL, deleted_by = NULL
+		FROM (SELECT id, deleted_at FROM pdf_files WHERE id = $1 FOR UPDATE) old
+		WHERE p.id = old.id AND old.deleted_at IS NOT NULL
+		RETURNING old.deleted_at
+	`, id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return deletedAt, ErrNotFound
+	}
+	if database.IsUniqueViolation(err) {
+		return deletedAt, ErrDuplicate
+	}
+	return deletedAt, err
+}
+
+func (r *PostgresPdfRepository) List(ctx context.Context, q PDFQuery) ([]models.PdfFile, error) {
+	return listPDFs(ctx, r.DB, q)
+}
+
+func (r *PostgresPdfRepository) Count(ctx context.Context, q PDFQuery) (int, error) {
+	return countPDFs(ctx, r.DB, q)
+}
+
+// PostgresSummaryRepository: latest_summary di pdf_files diisi trigger update_latest_summary.
+type PostgresSummaryRepository struct {
+	DB *sql.DB
+}
+
+func (r *PostgresSummaryRepository) Create(ctx context.Context, s *models.Summary) (int, error) {
+	summaryDefaults(s)
+	var id int
+	err := r.DB.QueryRowContext(ctx,
+		`INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, language_detected, chunks_count, chars_covered, total_chars, cache_hit, user_id,
+		                        status, error_code, error_message, attempts, provider, model)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14, NULLIF($15, ''), NULLIF($16, '')) RETURNING id`,
+		s.PdfID, s.SummaryText, s.SummaryStyle, s.ProcessTimeMs, s.LanguageDetected,
+		nullable(s.ChunksCount), nullable(s.CharsCovered), nullable(s.TotalChars), s.CacheHit, nullable(s.UserID),
+		s.Status, s.ErrorCode, s.ErrorMessage, s.Attempts, s.Provider, s.Model,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *PostgresSummaryRepository) ListByPDF(ctx context.Context, pdfID int) ([]models.Summary, error) {
+	return listSummaries(ctx, r.DB, pdfID)
+}
+
+func (r *PostgresSummaryRepository) Complete(ctx context.Context, s *models.Summary) error {
+	return completeSummary(ctx, r.DB, s)
+}
+
+func (r *PostgresSummaryRepository) CompleteTx(ctx context.Context, tx *sql.Tx, s *models.Summary) error {
+	return completeSummary(ctx, tx, s)
+}
+
+func (r *PostgresSummaryRepository) SaveChunks(ctx context.Context, summaryID int, chunks []models.ChunkSummary) error {
+	return saveChunks(ctx, r.DB, summaryID, chunks)
+}
+
+type PostgresAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+func (r *PostgresAPIKeyRepository) Touch(ctx context.Context, keyHash string) (*APIKeyOwner, error) {
+	var o APIKeyOwner
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE api_keys k SET last_used_at = NOW()
+		FROM users u
+		WHERE u.id = k.user_id AND k.key_hash = $1 AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at > NOW())
+		RETURNING k.id, u.id, u.email
+	`, keyHash).Scan(&o.KeyID, &o.UserID, &o.Email)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	return createAPIKey(ctx, r.DB, key, keyHash)
+}
+
+func (r *PostgresAPIKeyRepository) ListByUser(ctx context.Context, userID int) ([]models.APIKey, error) {
+	return listAPIKeys(ctx, r.DB, userID)
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id, userID int) error {
+	return revokeAPIKey(ctx, r.DB, id, userID)
+}
+
+//for learn, implementasi Postgres: NOW() & RETURNING langsung di query, trigger yang mengurus latest_summary
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/models"
+)
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("duplicate")  //unique index bentrok (misal isi file yang sama di workspace yang sama)
+	ErrLastOwner = errors.New("last owner") //owner terakhir workspace tidak boleh turun role / keluar
+)
+
+// PdfRepository = akses tabel pdf_files. Semua method mengabaikan PDF di trash kecuali Get.
+type PdfRepository interface {
+	Get(ctx context.Context, id int) (*models.PdfFile, error)
+	// FindByContentHash = PDF aktif dengan isi yang sama di workspace itu (record asli didahulukan), nil kalau tidak ada.
+	FindByContentHash(ctx context.Context, contentSHA256 string, workspaceID int) (*models.PdfFile, error)
+	Create(ctx context.Context, pdf *models.PdfFile) (int, error)
+	// Delete menghapus baris permanen (ringkasan & job ikut lewat CASCADE), untuk membatalkan upload yang gagal di tengah.
+	Delete(ctx context.Context, id int) error
+	// Rename mengembalikan nama lama.
+	Rename(ctx context.Context, id int, originalFilename string) (string, error)
+	// SoftDelete memindah PDF ke trash, hasilnya berisi OriginalFilename + DeletedAt.
+	SoftDelete(ctx context.Context, id, userID int) (*models.PdfFile, error)
+	// Restore mengeluarkan PDF dari trash, mengembalikan deleted_at lama. ErrDuplicate kalau isi yang sama sudah aktif lagi.
+	Restore(ctx context.Context, id int) (time.Time, error)
+	List(ctx context.Context, q PDFQuery) ([]models.PdfFile, error)
+	Count(ctx context.Context, q PDFQuery) (int, error)
+}
+
+// SummaryRepository = akses tabel summaries + summary_chunks.
+// Create juga memperbarui pdf_files.latest_summary (trigger di Postgres maupun SQLite).
+type SummaryRepository interface {
+	Create(ctx context.Context, s *models.Summary) (int, error)
+	ListByPDF(ctx context.Context, pdfID int) ([]models.Summary, error)
+	// Complete menyimpan hasil generate ke ringkasan pending (status succeeded / failed), attempts ikut bertambah.
+	Complete(ctx context.Context, s *models.Summary) error
+	// CompleteTx = Complete di dalam transaksi pemanggil (queue menutup job + ringkasannya sekaligus).
+	CompleteTx(ctx context.Context, tx *sql.Tx, s *models.Summary) error
+	SaveChunks(ctx context.Context, summaryID int, chunks []models.ChunkSummary) error
+}
+
+// APIKeyRepository = akses tabel api_keys untuk autentikasi X-API-Key.
+type APIKeyRepository interface {
+	// Touch mencari key aktif (belum dicabut / kedaluwarsa) berdasarkan hash-nya sekaligus mencatat last_used_at.
+	// ErrNotFound kalau tidak ada.
+	Touch(ctx context.Context, keyHash string) (*APIKeyOwner, error)
+	// Create menyimpan key baru (cuma hash-nya), mengisi ID & CreatedAt.
+	Create(ctx context.Context, key *models.APIKey, keyHash string) error
+	ListByUser(ctx context.Context, userID int) ([]models.APIKey, error)
+	// Revoke mencabut key milik userID (revoked_at lama dipertahankan), ErrNotFound kalau bukan miliknya.
+	Revoke(ctx context.Context, id, userID int) error
+}
+
+// APIKeyOwner = key yang valid beserta pemiliknya.
+type APIKeyOwner struct {
+	KeyID  int
+	UserID int
+	Email  string
+}
+
+// Repositories = kumpulan repository untuk satu koneksi DB.
+type Repositories struct {
+	PDFs       PdfRepository
+	Summaries  SummaryRepository
+	APIKeys    APIKeyRepository
+	Users      UserRepository
+	Workspaces WorkspaceRepository
+	Tags       TagRepository
+	Folders    FolderRepository
+	Shares     ShareRepository
+}
+
+// New memilih implementasi sesuai DB_DRIVER ("postgres" / "sqlite").
+// Users, Workspaces, Tags, Folders, Shares cuma punya satu implementasi karena SQL-nya jalan di keduanya.
+func New(db *sql.DB, driver string) Repositories {
+	repos := Repositories{
+		Users:      NewUserRepository(db),
+		Workspaces: NewWorkspaceRepository(db, driver),
+		Tags:       NewTagRepository(db),
+		Folders:    NewFolderRepository(db, driver),
+		Shares:     NewShareRepository(db),
+	}
+	if driver == "sqlite" {
+		repos.PDFs, repos.Summaries, repos.APIKeys = &SQLitePdfRepository{DB: db}, &SQLiteSummaryRepository{DB: db}, &SQLiteAPIKeyRepository{DB: db}
+	} else {
+		repos.PDFs, repos.Summaries, repos.APIKeys = &PostgresPdfRepository{DB: db}, &PostgresSummaryRepository{DB: db}, &PostgresAPIKeyRepository{DB: db}
+	}
+	return repos
+}
+
+// PDFQuery = list PDF di workspace tempat user jadi member (history, simple-pdfs).
+type PDFQuery struct {
+	UserID      int
+	WorkspaceID int //0 = semua workspace user
+	Filter      PDFFilter
+	OrderByID   bool //false = created_at terbaru dulu, true = id terbaru dulu
+	Limit       int
+	Offset      int
+}
+
+// PDFFilter = filter tag / folder untuk list PDF (history, simple-pdfs, search, /pdfs).
+type PDFFilter struct {
+	TagIDs    []int64 //PDF harus punya semua tag ini
+	FolderID  int
+	Root      bool //folder_id=root: PDF yang tidak masuk folder mana pun
+	Recursive bool //ikut isi subfolder
+}
+
+// Where = potongan SQL "AND ..." untuk filter ini. prefix = alias tabel pdf_files ("p." atau ""),
+// argN = nomor placeholder pertama yang masih kosong. SQL-nya jalan di Postgres dan SQLite.
+func (f PDFFilter) Where(prefix string, argN int) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	if len(f.TagIDs) > 0 {
+		fmt.Fprintf(&sb, ` AND %sid IN (SELECT pdf_id FROM pdf_tags WHERE tag_id IN (%s) GROUP BY pdf_id HAVING COUNT(*) = $%d)`,
+			prefix, Placeholders(argN, len(f.TagIDs)), argN+len(f.TagIDs))
+		for _, id := range f.TagIDs {
+			args = append(args, id)
+		}
+		args = append(args, len(f.TagIDs))
+		argN += len(f.TagIDs) + 1
+	}
+	switch {
+	case f.Root:
+		fmt.Fprintf(&sb, ` AND %sfolder_id IS NULL`, prefix)
+	case f.FolderID > 0 && f.Recursive:
+		fmt.Fprintf(&sb, ` AND %sfolder_id IN (
+			WITH RECURSIVE sub AS (
+				SELECT id FROM folders WHERE id = $%d
+				UNION SELECT f.id FROM folders f JOIN sub ON f.parent_id = sub.id
+			) SELECT id FROM sub)`, prefix, argN)
+		args = append(args, f.FolderID)
+	case f.FolderID > 0:
+		fmt.Fprintf(&sb, ` AND %sfolder_id = $%d`, prefix, argN)
+		args = append(args, f.FolderID)
+	}
+	return sb.String(), args
+}
+
+// Placeholders = "$3, $4, $5" untuk IN (...) dengan n nilai mulai dari $argN (pengganti ANY($n) yang cuma ada di Postgres).
+func Placeholders(argN, n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("$%d", argN+i)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer = *sql.DB atau *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+const pdfColumns = `id, filename, COALESCE(original_filename, filename), filepath, filesize, upload_time, created_at,
+	COALESCE(latest_summary, ''), page_count, COALESCE(pdf_version, ''), COALESCE(pdf_title, ''), COALESCE(pdf_author, ''),
+	COALESCE(pdf_subject, ''), pdf_created_at, is_encrypted, COALESCE(content_sha256, ''), allow_duplicate,
+	user_id, workspace_id, folder_id, deleted_at, COALESCE(summary_status, '')`
+
+func scanPDF(row rowScanner) (*models.PdfFile, error) {
+	var p models.PdfFile
+	var pageCount, userID, workspaceID, folderID sql.NullInt64
+	var pdfCreatedAt, deletedAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Filename, &p.OriginalFilename, &p.Filepath, &p.Filesize, &p.UploadTime, &p.CreatedAt,
+		&p.LatestSummary, &pageCount, &p.PdfVersion, &p.PdfTitle, &p.PdfAuthor,
+		&p.PdfSubject, &pdfCreatedAt, &p.IsEncrypted, &p.ContentSHA256, &p.AllowDuplicate,
+		&userID, &workspaceID, &folderID, &deletedAt, &p.SummaryStatus); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	p.PageCount = intPtr(pageCount)
+	p.UserID = intPtr(userID)
+	p.WorkspaceID = int(workspaceID.Int64)
+	p.FolderID = intPtr(folderID)
+	p.PdfCreatedAt = timePtr(pdfCreatedAt)
+	p.DeletedAt = timePtr(deletedAt)
+	return &p, nil
+}
+
+const summaryColumns = `id, pdf_id, summary_text, summary_style, process_time_ms, COALESCE(language_detected, ''), created_at,
+	chunks_count, chars_covered, total_chars, cache_hit, user_id, status, COALESCE(error_code, ''), COALESCE(error_message, ''), attempts,
+	COALESCE(provider, ''), COALESCE(model, '')`
+
+func scanSummary(row rowScanner) (*models.Summary, error) {
+	var s models.Summary
+	var chunksCount, charsCovered, totalChars, userID sql.NullInt64
+	if err := row.Scan(&s.ID, &s.PdfID, &s.SummaryText, &s.SummaryStyle, &s.ProcessTimeMs, &s.LanguageDetected, &s.CreatedAt,
+		&chunksCount, &charsCovered, &totalChars, &s.CacheHit, &userID, &s.Status, &s.ErrorCode, &s.ErrorMessage, &s.Attempts,
+		&s.Provider, &s.Model); err != nil {
+		return nil, err
+	}
+	s.ChunksCount = intPtr(chunksCount)
+	s.CharsCovered = intPtr(charsCovered)
+	s.TotalChars = intPtr(totalChars)
+	s.UserID = intPtr(userID)
+	return &s, nil
+}
+
+// listPDFs + countPDFs dipakai kedua implementasi, SQL-nya sama di Postgres dan SQLite.
+func listPDFs(ctx context.Context, db *sql.DB, q PDFQuery) ([]models.PdfFile, error) {
+	order := "created_at DESC"
+	if q.OrderByID {
+		order = "id DESC"
+	}
+	filterSQL, filterArgs := q.Filter.Where("", 5)
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+pdfColumns+`
+		FROM pdf_files
+		WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $3)
+		  AND ($4 = 0 OR workspace_id = $4) AND deleted_at IS NULL`+filterSQL+`
+		ORDER BY `+order+`
+		LIMIT $1 OFFSET $2`,
+		append([]interface{}{q.Limit, q.Offset, q.UserID, q.WorkspaceID}, filterArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pdfs []models.PdfFile
+	for rows.Next() {
+		p, err := scanPDF(rows)
+		if err != nil {
+			return nil, err
+		}
+		pdfs = append(pdfs, *p)
+	}
+	return pdfs, rows.Err()
+}
+
+func countPDFs(ctx context.Context, db *sql.DB, q PDFQuery) (int, error) {
+	var total int
+	filterSQL, filterArgs := q.Filter.Where("", 3)
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM pdf_files
+		WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND ($2 = 0 OR workspace_id = $2) AND deleted_at IS NULL`+filterSQL,
+		append([]interface{}{q.UserID, q.WorkspaceID}, filterArgs...)...).Scan(&total)
+	return total, err
+}
+
+func findByContentHash(ctx context.Context, db *sql.DB, contentSHA256 string, workspaceID int) (*models.PdfFile, error) {
+	p, err := scanPDF(db.QueryRowContext(ctx, `
+		SELECT `+pdfColumns+` FROM pdf_files
+		WHERE content_sha256 = $1 AND workspace_id = $2 AND deleted_at IS NULL
+		ORDER BY allow_duplicate, id
+		LIMIT 1
+	`, contentSHA256, workspaceID))
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	return p, err
+}
+
+func deletePDF(ctx context.Context, db *sql.DB, id int) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM pdf_files WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// deleteByID = DELETE satu baris berdasarkan id, ErrNotFound kalau tidak ada. table selalu konstanta dari kode.
+func deleteByID(ctx context.Context, db execer, table string, id int) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM `+table+` WHERE id = $1`, id)
+	return affectedOne(res, err)
+}
+
+// affectedOne mengubah UPDATE / DELETE yang tidak mengenai baris apa pun jadi ErrNotFound.
+func affectedOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func listSummaries(ctx context.Context, db *sql.DB, pdfID int) ([]models.Summary, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+summaryColumns+` FROM summaries WHERE pdf_id = $1 ORDER BY created_at DESC, id DESC`, pdfID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.Summary
+	for rows.Next() {
+		s, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, *s)
+	}
+	return summaries, rows.Err()
+}
+
+// completeSummary = Complete untuk kedua implementasi. Trigger latest_summary jalan karena kolom status ikut di-SET.
+func completeSummary(ctx context.Context, db execer, s *models.Summary) error {
+	res, err := db.ExecContext(ctx, `
+		UPDATE summaries
+		SET summary_text = $2, process_time_ms = $3, language_detected = $4, chunks_count = $5, chars_covered = $6,
+		    total_chars = $7, cache_hit = $8, status = $9, error_code = NULLIF($10, ''), error_message = NULLIF($11, ''),
+		    provider = NULLIF($12, ''), model = NULLIF($13, ''), attempts = attempts + 1
+		WHERE id = $1`,
+		s.ID, s.SummaryText, s.ProcessTimeMs, s.LanguageDetected, nullable(s.ChunksCount), nullable(s.CharsCovered),
+		nullable(s.TotalChars), s.CacheHit, s.Status, s.ErrorCode, s.ErrorMessage, s.Provider, s.Model,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// summaryDefaults: Create tanpa status = ringkasan yang langsung berhasil dalam satu percobaan.
+func summaryDefaults(s *models.Summary) {
+	if s.Status == "" {
+		s.Status = models.SummarySucceeded
+	}
+	if s.Attempts == 0 && s.Status != models.SummaryPending {
+		s.Attempts = 1
+	}
+}
+
+func saveChunks(ctx context.Context, db *sql.DB, summaryID int, chunks []models.ChunkSummary) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, chunk := range chunks {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO summary_chunks (summary_id, chunk_index, start_offset, end_offset, summary_text) VALUES ($1, $2, $3, $4, $5)`,
+			summaryID, chunk.Index, chunk.Start, chunk.End, chunk.Summary,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func intPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+func timePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+// nullable mengubah pointer kosong jadi NULL waktu insert.
+func nullable(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+//for learn, repository = satu-satunya tempat SQL pdf_files / summaries, handler cukup panggil method-nya
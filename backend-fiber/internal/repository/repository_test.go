@@ -0,0 +1,500 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+	"pdf-backend-fiber/internal/models"
+)
+
+// Test di sini jalan di SQLite asli (dbtest), supaya query yang cuma jalan di Postgres langsung ketahuan.
+
+var ctx = context.Background()
+
+func newTestRepos(t *testing.T) (Repositories, *sql.DB) {
+	t.Helper()
+	db := dbtest.Open(t)
+	return New(db, "sqlite"), db
+}
+
+func pdfIDs(pdfs []models.PdfFile) []int {
+	ids := []int{}
+	for _, p := range pdfs {
+		ids = append(ids, p.ID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestPdfRepositoryDedupAndTrash(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+
+	newPDF := func(name string, allowDuplicate bool) *models.PdfFile {
+		return &models.PdfFile{Filename: name, OriginalFilename: name, Filepath: name, Filesize: 10,
+			ContentSHA256: "same", AllowDuplicate: allowDuplicate, UserID: &userID, WorkspaceID: wsID}
+	}
+	original, err := repos.PDFs.Create(ctx, newPDF("a.pdf", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.PDFs.Create(ctx, newPDF("b.pdf", false)); err != ErrDuplicate {
+		t.Fatalf("second create err = %v, want ErrDuplicate", err)
+	}
+	forced, err := repos.PDFs.Create(ctx, newPDF("c.pdf", true))
+	if err != nil {
+		t.Fatalf("create with allow_duplicate: %v", err)
+	}
+	if p, err := repos.PDFs.FindByContentHash(ctx, "same", wsID); err != nil || p == nil || p.ID != original {
+		t.Fatalf("FindByContentHash = %+v, %v, want original %d", p, err, original)
+	}
+
+	deleted, err := repos.PDFs.SoftDelete(ctx, original, userID)
+	if err != nil || deleted.DeletedAt == nil || deleted.OriginalFilename != "a.pdf" {
+		t.Fatalf("SoftDelete = %+v, %v", deleted, err)
+	}
+	if _, err := repos.PDFs.SoftDelete(ctx, original, userID); err != ErrNotFound {
+		t.Fatalf("second SoftDelete err = %v, want ErrNotFound", err)
+	}
+	list, err := repos.PDFs.List(ctx, PDFQuery{UserID: userID, Limit: 10})
+	if err != nil || !reflect.DeepEqual(pdfIDs(list), []int{forced}) {
+		t.Fatalf("List after trash = %v, %v, want [%d]", pdfIDs(list), err, forced)
+	}
+	if p, _ := repos.PDFs.FindByContentHash(ctx, "same", wsID); p == nil || p.ID != forced {
+		t.Fatalf("FindByContentHash after trash = %+v, want %d", p, forced)
+	}
+
+	// isi yang sama diupload ulang selagi yang lama di trash, restore jadi bentrok
+	again, err := repos.PDFs.Create(ctx, newPDF("d.pdf", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.PDFs.Restore(ctx, original); err != ErrDuplicate {
+		t.Fatalf("Restore err = %v, want ErrDuplicate", err)
+	}
+	if err := repos.PDFs.Delete(ctx, again); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.PDFs.Restore(ctx, original); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if err := repos.PDFs.Delete(ctx, again); err != ErrNotFound {
+		t.Fatalf("second Delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPDFFilter(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+	otherUser, otherWS := dbtest.User(t, db, "x@y.z")
+
+	parent := &models.Folder{WorkspaceID: wsID, Name: "A"}
+	if err := repos.Folders.Create(ctx, parent, userID); err != nil {
+		t.Fatal(err)
+	}
+	child := &models.Folder{WorkspaceID: wsID, ParentID: &parent.ID, Name: "B"}
+	if err := repos.Folders.Create(ctx, child, userID); err != nil {
+		t.Fatal(err)
+	}
+	legal, hr := &models.Tag{WorkspaceID: wsID, Name: "legal"}, &models.Tag{WorkspaceID: wsID, Name: "hr"}
+	for _, tag := range []*models.Tag{legal, hr} {
+		if err := repos.Tags.Create(ctx, tag, userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inRoot := dbtest.PDF(t, db, wsID, userID, "root.pdf")
+	inParent := dbtest.PDF(t, db, wsID, userID, "parent.pdf")
+	inChild := dbtest.PDF(t, db, wsID, userID, "child.pdf")
+	dbtest.PDF(t, db, otherWS, otherUser, "other.pdf")
+	for pdfID, folder := range map[int]*int{inParent: &parent.ID, inChild: &child.ID} {
+		if _, err := repos.Folders.SetPDFFolder(ctx, pdfID, folder); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for pdfID, tags := range map[int][]int64{inRoot: {int64(legal.ID)}, inChild: {int64(legal.ID), int64(hr.ID)}} {
+		if _, err := repos.Tags.SetPDFTags(ctx, pdfID, tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter PDFFilter
+		want   []int
+	}{
+		{"tanpa filter", PDFFilter{}, []int{inRoot, inParent, inChild}},
+		{"satu tag", PDFFilter{TagIDs: []int64{int64(legal.ID)}}, []int{inRoot, inChild}},
+		{"semua tag harus ada", PDFFilter{TagIDs: []int64{int64(legal.ID), int64(hr.ID)}}, []int{inChild}},
+		{"root", PDFFilter{Root: true}, []int{inRoot}},
+		{"folder langsung", PDFFilter{FolderID: parent.ID}, []int{inParent}},
+		{"folder + subfolder", PDFFilter{FolderID: parent.ID, Recursive: true}, []int{inParent, inChild}},
+		{"tag + folder", PDFFilter{TagIDs: []int64{int64(legal.ID)}, FolderID: parent.ID, Recursive: true}, []int{inChild}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := PDFQuery{UserID: userID, Filter: tt.filter, Limit: 10}
+			list, err := repos.PDFs.List(ctx, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pdfIDs(list); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("List = %v, want %v", got, tt.want)
+			}
+			if n, err := repos.PDFs.Count(ctx, q); err != nil || n != len(tt.want) {
+				t.Fatalf("Count = %d, %v, want %d", n, err, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSummaryRepositoryComplete(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+	pdfID := dbtest.PDF(t, db, wsID, userID, "a.pdf")
+
+	id, err := repos.Summaries.Create(ctx, &models.Summary{PdfID: pdfID, SummaryStyle: "standard", Status: models.SummaryPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// latest_summary cuma diisi ringkasan yang berhasil (trigger)
+	latest := func() string {
+		var summary string
+		if err := db.QueryRow(`SELECT COALESCE(latest_summary, '') FROM pdf_files WHERE id = $1`, pdfID).Scan(&summary); err != nil {
+			t.Fatal(err)
+		}
+		return summary
+	}
+	if summary := latest(); summary != "" {
+		t.Fatalf("latest_summary after pending create = %q", summary)
+	}
+
+	err = repos.Summaries.Complete(ctx, &models.Summary{ID: id, SummaryText: "hasil", LanguageDetected: "id", Status: models.SummarySucceeded, Provider: "extractive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := latest(); summary != "hasil" {
+		t.Fatalf("latest_summary after complete = %q", summary)
+	}
+	list, err := repos.Summaries.ListByPDF(ctx, pdfID)
+	if err != nil || len(list) != 1 || list[0].Attempts != 1 || list[0].Provider != "extractive" {
+		t.Fatalf("ListByPDF = %+v, %v", list, err)
+	}
+	if err := repos.Summaries.Complete(ctx, &models.Summary{ID: id + 100, Status: models.SummaryFailed}); err != ErrNotFound {
+		t.Fatalf("Complete unknown id err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, _ := dbtest.User(t, db, "a@b.c")
+	otherUser, _ := dbtest.User(t, db, "x@y.z")
+
+	newKey := func(hash string, expiresAt *time.Time) *models.APIKey {
+		k := &models.APIKey{UserID: userID, Name: hash, Prefix: "pdfk_" + hash, ExpiresAt: expiresAt}
+		if err := repos.APIKeys.Create(ctx, k, hash); err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	newKey("active", nil)
+	newKey("later", &future)
+	newKey("expired", &past)
+	revoked := newKey("revoked", nil)
+	if err := repos.APIKeys.Revoke(ctx, revoked.ID, otherUser); err != ErrNotFound {
+		t.Fatalf("Revoke by other user err = %v, want ErrNotFound", err)
+	}
+	if err := repos.APIKeys.Revoke(ctx, revoked.ID, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		hash string
+		want error
+	}{
+		{"active", nil},
+		{"later", nil},
+		{"expired", ErrNotFound},
+		{"revoked", ErrNotFound},
+		{"unknown", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hash, func(t *testing.T) {
+			o, err := repos.APIKeys.Touch(ctx, tt.hash)
+			if err != tt.want {
+				t.Fatalf("Touch err = %v, want %v", err, tt.want)
+			}
+			if err == nil && (o.UserID != userID || o.Email != "a@b.c") {
+				t.Fatalf("Touch = %+v", o)
+			}
+		})
+	}
+
+	keys, err := repos.APIKeys.ListByUser(ctx, userID)
+	if err != nil || len(keys) != 4 {
+		t.Fatalf("ListByUser = %d keys, %v", len(keys), err)
+	}
+	for _, k := range keys {
+		if used := k.Name == "active" || k.Name == "later"; used != (k.LastUsedAt != nil) {
+			t.Errorf("key %s last_used_at = %v", k.Name, k.LastUsedAt)
+		}
+		if (k.Name == "revoked") != (k.RevokedAt != nil) {
+			t.Errorf("key %s revoked_at = %v", k.Name, k.RevokedAt)
+		}
+	}
+}
+
+func TestUserAndWorkspaceRepository(t *testing.T) {
+	repos, db := newTestRepos(t)
+
+	user := &models.User{Email: "a@b.c", Name: "A"}
+	wsID, err := repos.Users.Create(ctx, user, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Users.Create(ctx, &models.User{Email: "A@B.C"}, "hash"); err != ErrDuplicate {
+		t.Fatalf("Create same email err = %v, want ErrDuplicate", err)
+	}
+	if u, err := repos.Users.GetByEmail(ctx, "A@b.C"); err != nil || u.ID != user.ID || u.PasswordHash != "hash" {
+		t.Fatalf("GetByEmail = %+v, %v", u, err)
+	}
+	if id, err := repos.Workspaces.PersonalID(ctx, user.ID); err != nil || id != wsID {
+		t.Fatalf("PersonalID = %d, %v, want %d", id, err, wsID)
+	}
+
+	team, err := repos.Workspaces.Create(ctx, "Tim", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherUser, _ := dbtest.User(t, db, "x@y.z")
+	if err := repos.Workspaces.AddMember(ctx, team.ID, otherUser, models.RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Workspaces.AddMember(ctx, team.ID, otherUser, models.RoleEditor); err != ErrDuplicate {
+		t.Fatalf("AddMember twice err = %v, want ErrDuplicate", err)
+	}
+	if role, err := repos.Workspaces.Role(ctx, team.ID, otherUser); err != nil || role != models.RoleViewer {
+		t.Fatalf("Role = %q, %v", role, err)
+	}
+	if role, err := repos.Workspaces.Role(ctx, wsID, otherUser); err != nil || role != "" {
+		t.Fatalf("Role in someone else's workspace = %q, %v", role, err)
+	}
+
+	pdfID := dbtest.PDF(t, db, wsID, user.ID, "a.pdf")
+	if _, _, err := repos.Workspaces.PDFRole(ctx, pdfID, otherUser, false); err != ErrNotFound {
+		t.Fatalf("PDFRole non-member err = %v, want ErrNotFound", err)
+	}
+	if _, _, err := repos.Workspaces.PDFRole(ctx, pdfID, user.ID, true); err != ErrNotFound {
+		t.Fatalf("PDFRole trashed=true on active PDF err = %v, want ErrNotFound", err)
+	}
+	if _, _, err := repos.Workspaces.ItemRole(ctx, "users", 1, user.ID); err == nil {
+		t.Fatal("ItemRole accepted a table outside tags / folders")
+	}
+
+	// pindah ke workspace yang sudah punya isi sama = ErrDuplicate, tag & folder lepas kalau berhasil
+	dupID := dbtest.PDF(t, db, team.ID, user.ID, "a.pdf")
+	if _, err := repos.Workspaces.MovePDF(ctx, pdfID, team.ID); err != ErrDuplicate {
+		t.Fatalf("MovePDF into duplicate err = %v, want ErrDuplicate", err)
+	}
+	if err := repos.PDFs.Delete(ctx, dupID); err != nil {
+		t.Fatal(err)
+	}
+	tag := &models.Tag{WorkspaceID: wsID, Name: "legal"}
+	if err := repos.Tags.Create(ctx, tag, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Tags.SetPDFTags(ctx, pdfID, []int64{int64(tag.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	move, err := repos.Workspaces.MovePDF(ctx, pdfID, team.ID)
+	if err != nil || !reflect.DeepEqual(move.RemovedTags, []int64{int64(tag.ID)}) {
+		t.Fatalf("MovePDF = %+v, %v", move, err)
+	}
+	if ws, role, err := repos.Workspaces.PDFRole(ctx, pdfID, otherUser, false); err != nil || ws != team.ID || role != models.RoleViewer {
+		t.Fatalf("PDFRole after move = %d %q %v", ws, role, err)
+	}
+}
+
+// dua owner saling menurunkan / mengeluarkan bersamaan: tepat satu yang berhasil, workspace tetap punya owner.
+func TestWorkspaceLastOwnerConcurrent(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		repos, db := newTestRepos(t)
+		a, _ := dbtest.User(t, db, "a@b.c")
+		b, _ := dbtest.User(t, db, "b@b.c")
+		team, err := repos.Workspaces.Create(ctx, "Tim", a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dbtest.Member(t, db, team.ID, b, models.RoleOwner)
+
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for i, userID := range []int{a, b} {
+			wg.Add(1)
+			go func(i, userID int) {
+				defer wg.Done()
+				if remove {
+					_, errs[i] = repos.Workspaces.RemoveMember(ctx, team.ID, userID)
+				} else {
+					_, errs[i] = repos.Workspaces.SetMemberRole(ctx, team.ID, userID, models.RoleEditor)
+				}
+			}(i, userID)
+		}
+		wg.Wait()
+
+		if (errs[0] == nil) == (errs[1] == nil) || (errs[0] != ErrLastOwner && errs[1] != ErrLastOwner) {
+			t.Fatalf("remove=%v errs = %v, want exactly one ErrLastOwner", remove, errs)
+		}
+		var owners int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = 'owner'`, team.ID).Scan(&owners); err != nil {
+			t.Fatal(err)
+		}
+		if owners != 1 {
+			t.Fatalf("remove=%v owners = %d, want 1", remove, owners)
+		}
+	}
+
+	repos, db := newTestRepos(t)
+	a, wsID := dbtest.User(t, db, "a@b.c")
+	if _, err := repos.Workspaces.SetMemberRole(ctx, wsID, a+1, models.RoleViewer); err != ErrNotFound {
+		t.Fatalf("SetMemberRole non-member err = %v, want ErrNotFound", err)
+	}
+	if old, err := repos.Workspaces.SetMemberRole(ctx, wsID, a, models.RoleOwner); err != nil || old != models.RoleOwner {
+		t.Fatalf("SetMemberRole owner->owner = %q, %v", old, err)
+	}
+}
+
+func TestTagRepositoryBulkUpdate(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+	team, err := repos.Workspaces.Create(ctx, "Tim", userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine := dbtest.PDF(t, db, wsID, userID, "a.pdf")
+	theirs := dbtest.PDF(t, db, team.ID, userID, "b.pdf")
+	tag := &models.Tag{WorkspaceID: wsID, Name: "legal"}
+	if err := repos.Tags.Create(ctx, tag, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Tags.Create(ctx, &models.Tag{WorkspaceID: wsID, Name: "LEGAL"}, userID); err != ErrDuplicate {
+		t.Fatalf("Create same name err = %v, want ErrDuplicate", err)
+	}
+
+	// tag workspace pribadi tidak ikut terpasang ke PDF di workspace tim
+	changes, err := repos.Tags.BulkUpdate(ctx, []int64{int64(mine), int64(theirs)}, []int64{int64(tag.ID)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[mine] == nil || !reflect.DeepEqual(changes[mine].Added, []int64{int64(tag.ID)}) {
+		t.Fatalf("BulkUpdate add = %+v", changes)
+	}
+	if changes, _ := repos.Tags.BulkUpdate(ctx, []int64{int64(mine)}, []int64{int64(tag.ID)}, nil); len(changes) != 0 {
+		t.Fatalf("BulkUpdate adding existing tag reported changes: %+v", changes)
+	}
+	byPDF, err := repos.Tags.ForPDFs(ctx, []int{mine, theirs})
+	if err != nil || len(byPDF[mine]) != 1 || len(byPDF[theirs]) != 0 {
+		t.Fatalf("ForPDFs = %+v, %v", byPDF, err)
+	}
+
+	name, pdfCount, err := repos.Tags.Delete(ctx, tag.ID)
+	if err != nil || name != "legal" || pdfCount != 1 {
+		t.Fatalf("Delete = %q %d %v", name, pdfCount, err)
+	}
+	if byPDF, _ := repos.Tags.ForPDFs(ctx, []int{mine}); len(byPDF[mine]) != 0 {
+		t.Fatalf("pdf_tags not cascaded: %+v", byPDF)
+	}
+}
+
+func TestFolderRepository(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+
+	a := &models.Folder{WorkspaceID: wsID, Name: "A"}
+	if err := repos.Folders.Create(ctx, a, userID); err != nil {
+		t.Fatal(err)
+	}
+	b := &models.Folder{WorkspaceID: wsID, ParentID: &a.ID, Name: "B"}
+	if err := repos.Folders.Create(ctx, b, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Folders.Create(ctx, &models.Folder{WorkspaceID: wsID, ParentID: &a.ID, Name: "b"}, userID); err != ErrDuplicate {
+		t.Fatalf("Create sibling with same name err = %v, want ErrDuplicate", err)
+	}
+	if err := repos.Folders.Create(ctx, &models.Folder{WorkspaceID: wsID, Name: "B"}, userID); err != nil {
+		t.Fatalf("same name in another parent: %v", err)
+	}
+
+	for _, tt := range []struct {
+		folder, candidate int
+		want              bool
+	}{{a.ID, a.ID, true}, {a.ID, b.ID, true}, {b.ID, a.ID, false}} {
+		if got, err := repos.Folders.IsWithin(ctx, tt.folder, tt.candidate); err != nil || got != tt.want {
+			t.Errorf("IsWithin(%d, %d) = %v, %v, want %v", tt.folder, tt.candidate, got, err, tt.want)
+		}
+	}
+
+	pdfID := dbtest.PDF(t, db, wsID, userID, "a.pdf")
+	if old, err := repos.Folders.SetPDFFolder(ctx, pdfID, &b.ID); err != nil || old != nil {
+		t.Fatalf("SetPDFFolder = %v, %v", old, err)
+	}
+	if subfolders, pdfs, err := repos.Folders.Contents(ctx, a.ID); err != nil || subfolders != 1 || pdfs != 0 {
+		t.Fatalf("Contents(A) = %d %d %v", subfolders, pdfs, err)
+	}
+	if old, err := repos.Folders.SetPDFFolder(ctx, pdfID, nil); err != nil || old == nil || *old != b.ID {
+		t.Fatalf("SetPDFFolder(root) old = %v, %v", old, err)
+	}
+	if err := repos.Folders.Update(ctx, b.ID, "B2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := repos.Folders.Get(ctx, b.ID); err != nil || f.Name != "B2" || f.ParentID != nil {
+		t.Fatalf("Get after update = %+v, %v", f, err)
+	}
+}
+
+func TestShareRepository(t *testing.T) {
+	repos, db := newTestRepos(t)
+	userID, wsID := dbtest.User(t, db, "a@b.c")
+	pdfID := dbtest.PDF(t, db, wsID, userID, "a.pdf")
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if _, err := repos.Shares.Create(ctx, "link1", pdfID, "summary", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Shares.CountView(ctx, "link1"); err != nil {
+		t.Fatal(err)
+	}
+	l, err := repos.Shares.Get(ctx, "link1", true)
+	if err != nil || l.PdfID != pdfID || l.ViewCount != 1 || l.LastViewedAt == nil || !l.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("Get = %+v, %v", l, err)
+	}
+
+	first, err := repos.Shares.Revoke(ctx, "link1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repos.Shares.Revoke(ctx, "link1"); err != nil || !again.Equal(first) {
+		t.Fatalf("second Revoke = %v, %v, want the first revoked_at %v", again, err, first)
+	}
+	if _, err := repos.Shares.Revoke(ctx, "nope"); err != ErrNotFound {
+		t.Fatalf("Revoke unknown err = %v, want ErrNotFound", err)
+	}
+
+	// PDF di trash: link tidak ketemu dari sisi publik, tapi masih bisa dicabut / dilihat pemiliknya
+	if _, err := repos.PDFs.SoftDelete(ctx, pdfID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Shares.Get(ctx, "link1", true); err != ErrNotFound {
+		t.Fatalf("Get activePDF on trashed PDF err = %v, want ErrNotFound", err)
+	}
+	if _, err := repos.Shares.Get(ctx, "link1", false); err != nil {
+		t.Fatalf("Get on trashed PDF: %v", err)
+	}
+}
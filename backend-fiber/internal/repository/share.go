@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ShareRepository = akses tabel share_links (link read-only publik).
+type ShareRepository interface {
+	// Create menyimpan link baru, mengembalikan created_at.
+	Create(ctx context.Context, linkID string, pdfID int, scope string, expiresAt time.Time) (time.Time, error)
+	// ListByPDF = semua link satu PDF (termasuk yang dicabut / kedaluwarsa), terbaru dulu.
+	ListByPDF(ctx context.Context, pdfID int) ([]ShareLink, error)
+	// Get = link berdasarkan link_id. activePDF = abaikan link yang PDF-nya ada di trash.
+	Get(ctx context.Context, linkID string, activePDF bool) (*ShareLink, error)
+	// Revoke mengisi revoked_at (yang lama dipertahankan kalau sudah dicabut), mengembalikan revoked_at.
+	Revoke(ctx context.Context, linkID string) (time.Time, error)
+	CountView(ctx context.Context, linkID string) error
+}
+
+// ShareLink = baris share_links.
+type ShareLink struct {
+	LinkID       string
+	PdfID        int
+	Scope        string
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+	ViewCount    int
+	LastViewedAt *time.Time
+	CreatedAt    time.Time
+}
+
+// SQLShareRepository: SQL-nya sama di Postgres dan SQLite.
+type SQLShareRepository struct {
+	DB *sql.DB
+}
+
+func NewShareRepository(db *sql.DB) *SQLShareRepository {
+	return &SQLShareRepository{DB: db}
+}
+
+func (r *SQLShareRepository) Create(ctx context.Context, linkID string, pdfID int, scope string, expiresAt time.Time) (time.Time, error) {
+	var createdAt time.Time
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO share_links (link_id, pdf_id, scope, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, linkID, pdfID, scope, expiresAt).Scan(&createdAt)
+	return createdAt, err
+}
+
+const shareColumns = `s.link_id, s.pdf_id, s.scope, s.expires_at, s.revoked_at, s.view_count, s.last_viewed_at, s.created_at`
+
+func scanShareLink(row rowScanner) (*ShareLink, error) {
+	var l ShareLink
+	var revokedAt, lastViewedAt sql.NullTime
+	if err := row.Scan(&l.LinkID, &l.PdfID, &l.Scope, &l.ExpiresAt, &revokedAt, &l.ViewCount, &lastViewedAt, &l.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	l.RevokedAt = timePtr(revokedAt)
+	l.LastViewedAt = timePtr(lastViewedAt)
+	return &l, nil
+}
+
+func (r *SQLShareRepository) ListByPDF(ctx context.Context, pdfID int) ([]ShareLink, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+shareColumns+` FROM share_links s WHERE s.pdf_id = $1 ORDER BY s.created_at DESC`, pdfID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []ShareLink
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	return links, rows.Err()
+}
+
+func (r *SQLShareRepository) Get(ctx context.Context, linkID string, activePDF bool) (*ShareLink, error) {
+	query := `SELECT ` + shareColumns + ` FROM share_links s WHERE s.link_id = $1`
+	if activePDF {
+		query = `SELECT ` + shareColumns + ` FROM share_links s
+			JOIN pdf_files p ON p.id = s.pdf_id
+			WHERE s.link_id = $1 AND p.deleted_at IS NULL`
+	}
+	return scanShareLink(r.DB.QueryRowContext(ctx, query, linkID))
+}
+
+func (r *SQLShareRepository) Revoke(ctx context.Context, linkID string) (time.Time, error) {
+	var revokedAt time.Time
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE share_links SET revoked_at = COALESCE(revoked_at, $2) WHERE link_id = $1
+		RETURNING revoked_at
+	`, linkID, time.Now().UTC()).Scan(&revokedAt)
+	if err == sql.ErrNoRows {
+		return revokedAt, ErrNotFound
+	}
+	return revokedAt, err
+}
+
+func (r *SQLShareRepository) CountView(ctx context.Context, linkID string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE share_links SET view_count = view_count + 1, last_viewed_at = NOW() WHERE link_id = $1
+	`, linkID)
+	return err
+}
+
+//for learn, share link: token = id.expired.signature, isi pdf/scope tetap diambil dari DB biar bisa dicabut
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// SQLitePdfRepository = implementasi file SQLite (DB_DRIVER=sqlite) untuk deploy satu binary tanpa server Postgres.
+// Waktu diisi dari Go, bukan NOW() di SQL: kolom DATETIME SQLite cuma teks, formatnya harus bisa dibaca balik driver.
+type SQLitePdfRepository struct {
+	DB *sql.DB
+}
+
+func (r *SQLitePdfRepository) Get(ctx context.Context, id int) (*models.PdfFile, error) {
+	return scanPDF(r.DB.QueryRowContext(ctx, `SELECT `+pdfColumns+` FROM pdf_files WHERE id = $1`, id))
+}
+
+func (r *SQLitePdfRepository) FindByContentHash(ctx context.Context, contentSHA256 string, workspaceID int) (*models.PdfFile, error) {
+	return findByContentHash(ctx, r.DB, contentSHA256, workspaceID)
+}
+
+func (r *SQLitePdfRepository) Create(ctx context.Context, p *models.PdfFile) (int, error) {
+	now := time.Now().UTC()
+	res, err := r.DB.ExecContext(ctx,
+		`INSERT INTO pdf_files (filename, original_filename, filepath, filesize, upload_time, created_at,
+		                        page_count, pdf_version, pdf_title, pdf_author, pdf_subject, pdf_created_at, is_encrypted,
+		                        content_sha256, allow_duplicate, user_id, workspace_id, extracted_text, text_language)
+		 VALUES ($1, $2, $3, $4, $5, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13, $14, $15, $16, $17, NULLIF($18, ''))`,
+		p.Filename, p.OriginalFilename, p.Filepath, p.Filesize, now,
+		nullable(p.PageCount), p.PdfVersion, p.PdfTitle, p.PdfAuthor, p.PdfSubject, p.PdfCreatedAt, p.IsEncrypted,
+		p.ContentSHA256, p.AllowDuplicate, nullable(p.UserID), p.WorkspaceID, p.ExtractedText, p.TextLanguage,
+	)
+	if database.IsUniqueViolation(err) {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func (r *SQLitePdfRepository) Delete(ctx context.Context, id int) error {
+	return deletePDF(ctx, r.DB, id)
+}
+
+func (r *SQLitePdfRepository) Rename(ctx context.Context, id int, originalFilename string) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(original_filename, filename) FROM pdf_files WHERE id = $1`, id).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE pdf_files SET original_filename = $1 WHERE id = $2`, originalFilename, id); err != nil {
+		return "", err
+	}
+	return oldName, tx.Commit()
+}
+
+func (r *SQLitePdfRepository) SoftDelete(ctx context.Context, id, userID int) (*models.PdfFile, error) {
+	p := models.PdfFile{ID: id}
+	deletedAt := time.Now().UTC()
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE pdf_files SET deleted_at = $3, deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL
+		RETURNING COALESCE(original_filename, filename)
+	`, id, userID, deletedAt).Scan(&p.OriginalFilename)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.DeletedAt = &deletedAt
+	return &p, nil
+}
+
+func (r *SQLitePdfRepository) Restore(ctx context.Context, id int) (time.Time, error) {
+	var deletedAt time.Time
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return deletedAt, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `SELECT deleted_at FROM pdf_files WHERE id = $1 AND deleted_at IS NOT NULL`, id).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return deletedAt, ErrNotFound
+	}
+	if err != nil {
+		return deletedAt, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE pdf_files SET deleted_at = NULL, deleted_by = NULL WHERE id = $1`, id)
+	if database.IsUniqueViolation(err) {
+		return deletedAt, ErrDuplicate
+	}
+	if err != nil {
+		return deletedAt, err
+	}
+	return deletedAt, tx.Commit()
+}
+
+func (r *SQLitePdfRepository) List(ctx context.Context, q PDFQuery) ([]models.PdfFile, error) {
+	return listPDFs(ctx, r.DB, q)
+}
+
+func (r *SQLitePdfRepository) Count(ctx context.Context, q PDFQuery) (int, error) {
+	return countPDFs(ctx, r.DB, q)
+}
+
+// SQLiteSummaryRepository: latest_summary diisi trigger trigger_update_latest_summary (migrations/sqlite).
+type SQLiteSummaryRepository struct {
+	DB *sql.DB
+}
+
+func (r *SQLiteSummaryRepository) Create(ctx context.Context, s *models.Summary) (int, error) {
+	summaryDefaults(s)
+	res, err := r.DB.ExecContext(ctx,
+		`INSERT INTO summaries (pdf_id, summary_text, summary_style, process_time_ms, language_detected, chunks_count, chars_covered, total_chars, cache_hit, user_id,
+		                        status, error_code, error_message, attempts, provider, model, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14, NULLIF($15, ''), NULLIF($16, ''), $17)`,
+		s.PdfID, s.SummaryText, s.SummaryStyle, s.ProcessTimeMs, s.LanguageDetected,
+		nullable(s.ChunksCount), nullable(s.CharsCovered), nullable(s.TotalChars), s.CacheHit, nullable(s.UserID),
+		s.Status, s.ErrorCode, s.ErrorMessage, s.Attempts, s.Provider, s.Model, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func (r *SQLiteSummaryRepository) ListByPDF(ctx context.Context, pdfID int) ([]models.Summary, error) {
+	return listSummaries(ctx, r.DB, pdfID)
+}
+
+func (r *SQLiteSummaryRepository) Complete(ctx context.Context, s *models.Summary) error {
+	return completeSummary(ctx, r.DB, s)
+}
+
+func (r *SQLiteSummaryRepository) CompleteTx(ctx context.Context, tx *sql.Tx, s *models.Summary) error {
+	return completeSummary(ctx, tx, s)
+}
+
+func (r *SQLiteSummaryRepository) SaveChunks(ctx context.Context, summaryID int, chunks []models.ChunkSummary) error {
+	return saveChunks(ctx, r.DB, summaryID, chunks)
+}
+
+// SQLiteAPIKeyRepository: SQLite tidak kenal UPDATE ... FROM dengan alias, email user diambil terpisah.
+type SQLiteAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+func (r *SQLiteAPIKeyRepository) Touch(ctx context.Context, keyHash string) (*APIKeyOwner, error) {
+	var o APIKeyOwner
+	now := time.Now().UTC()
+	err := r.DB.QueryRowContext(ctx, `
+		UPDATE api_keys SET last_used_at = $2
+		WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		RETURNING id, user_id
+	`, keyHash, now).Scan(&o.KeyID, &o.UserID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = r.DB.QueryRowContext(ctx, `SELECT email FROM users WHERE id = $1`, o.UserID).Scan(&o.Email)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *SQLiteAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	return createAPIKey(ctx, r.DB, key, keyHash)
+}
+
+func (r *SQLiteAPIKeyRepository) ListByUser(ctx context.Context, userID int) ([]models.APIKey, error) {
+	return listAPIKeys(ctx, r.DB, userID)
+}
+
+func (r *SQLiteAPIKeyRepository) Revoke(ctx context.Context, id, userID int) error {
+	return revokeAPIKey(ctx, r.DB, id, userID)
+}
+
+//for learn, implementasi SQLite: id dari LastInsertId, waktu dari Go, sisanya SQL yang sama dengan Postgres
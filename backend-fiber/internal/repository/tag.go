@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// TagRepository = akses tabel tags + pdf_tags.
+type TagRepository interface {
+	// ListForUser = tag di semua workspace user (workspaceID 0) atau satu workspace, PDFCount = jumlah PDF aktif.
+	ListForUser(ctx context.Context, userID, workspaceID int) ([]models.Tag, error)
+	// Create mengisi ID & CreatedAt. ErrDuplicate kalau nama sudah dipakai di workspace itu.
+	Create(ctx context.Context, t *models.Tag, userID int) error
+	Get(ctx context.Context, id int) (*models.Tag, error)
+	Update(ctx context.Context, id int, name, color string) error
+	// Delete mengembalikan nama + jumlah PDF yang terlepas (pdf_tags ikut terhapus lewat cascade).
+	Delete(ctx context.Context, id int) (string, int, error)
+	// CountInWorkspace = berapa dari ids yang ada di workspace itu.
+	CountInWorkspace(ctx context.Context, workspaceID int, ids []int64) (int, error)
+	// CountForUser = berapa dari ids yang ada di workspace tempat user jadi anggota.
+	CountForUser(ctx context.Context, userID int, ids []int64) (int, error)
+	// ForPDFs = tag banyak PDF sekaligus (satu query), key = pdf id.
+	ForPDFs(ctx context.Context, pdfIDs []int) (map[int][]models.Tag, error)
+	// SetPDFTags mengganti semua tag satu PDF, mengembalikan yang ditambah / dilepas (urut id).
+	SetPDFTags(ctx context.Context, pdfID int, tagIDs []int64) (*TagChange, error)
+	// BulkUpdate menambah / melepas tag di banyak PDF. Tag cuma dipasang ke PDF di workspace yang sama.
+	// Hasilnya cuma berisi PDF yang benar-benar berubah.
+	BulkUpdate(ctx context.Context, pdfIDs, add, remove []int64) (map[int]*TagChange, error)
+}
+
+// TagChange = tag yang ditambah / dilepas di satu PDF.
+type TagChange struct {
+	Added   []int64
+	Removed []int64
+}
+
+// SQLTagRepository: selisih tag dihitung di Go dan IN (...) pakai Placeholders, jadi SQL-nya jalan di Postgres & SQLite.
+type SQLTagRepository struct {
+	DB *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *SQLTagRepository {
+	return &SQLTagRepository{DB: db}
+}
+
+func (r *SQLTagRepository) ListForUser(ctx context.Context, userID, workspaceID int) ([]models.Tag, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT t.id, t.workspace_id, t.name, t.color, t.created_at,
+		       (SELECT COUNT(*) FROM pdf_tags pt JOIN pdf_files p ON p.id = pt.pdf_id
+		        WHERE pt.tag_id = t.id AND p.deleted_at IS NULL)
+		FROM tags t
+		WHERE t.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND ($2 = 0 OR t.workspace_id = $2)
+		ORDER BY t.workspace_id, LOWER(t.name)
+	`, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var t models.Tag
+		var count int
+		if err := rows.Scan(&t.ID, &t.WorkspaceID, &t.Name, &t.Color, &t.CreatedAt, &count); err != nil {
+			return nil, err
+		}
+		t.PDFCount = &count
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *SQLTagRepository) Create(ctx context.Context, t *models.Tag, userID int) error {
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO tags (workspace_id, name, color, created_by) VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`, t.WorkspaceID, t.Name, t.Color, userID).Scan(&t.ID, &t.CreatedAt)
+	if database.IsUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *SQLTagRepository) Get(ctx context.Context, id int) (*models.Tag, error) {
+	var t models.Tag
+	err := r.DB.QueryRowContext(ctx, `SELECT id, workspace_id, name, color, created_at FROM tags WHERE id = $1`, id).
+		Scan(&t.ID, &t.WorkspaceID, &t.Name, &t.Color, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *SQLTagRepository) Update(ctx context.Context, id int, name, color string) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE tags SET name = $1, color = $2 WHERE id = $3`, name, color, id)
+	if database.IsUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return affectedOne(res, err)
+}
+
+func (r *SQLTagRepository) Delete(ctx context.Context, id int) (string, int, error) {
+	// nama + jumlah PDF dibaca sebelum DELETE (pdf_tags ikut terhapus lewat cascade)
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback()
+
+	var name string
+	var pdfCount int
+	err = tx.QueryRowContext(ctx, `SELECT name, (SELECT COUNT(*) FROM pdf_tags WHERE tag_id = $1) FROM tags WHERE id = $1`, id).
+		Scan(&name, &pdfCount)
+	if err == sql.ErrNoRows {
+		return "", 0, ErrNotFound
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	if err := deleteByID(ctx, tx, "tags", id); err != nil {
+		return "", 0, err
+	}
+	return name, pdfCount, tx.Commit()
+}
+
+func (r *SQLTagRepository) CountInWorkspace(ctx context.Context, workspaceID int, ids []int64) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var n int
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM tags WHERE workspace_id = $1 AND id IN (`+Placeholders(2, len(ids))+`)`,
+		append([]interface{}{workspaceID}, idArgs(ids)...)...).Scan(&n)
+	return n, err
+}
+
+func (r *SQLTagRepository) CountForUser(ctx context.Context, userID int, ids []int64) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var n int
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM tags
+		WHERE workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)
+		  AND id IN (`+Placeholders(2, len(ids))+`)
+	`, append([]interface{}{userID}, idArgs(ids)...)...).Scan(&n)
+	return n, err
+}
+
+func (r *SQLTagRepository) ForPDFs(ctx context.Context, pdfIDs []int) (map[int][]models.Tag, error) {
+	result := map[int][]models.Tag{}
+	if len(pdfIDs) == 0 {
+		return result, nil
+	}
+	ids := make([]interface{}, len(pdfIDs))
+	for i, id := range pdfIDs {
+		ids[i] = id
+	}
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT pt.pdf_id, t.id, t.workspace_id, t.name, t.color, t.created_at
+		FROM pdf_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.pdf_id IN (`+Placeholders(1, len(ids))+`)
+		ORDER BY LOWER(t.name)
+	`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pdfID int
+		var t models.Tag
+		if err := rows.Scan(&pdfID, &t.ID, &t.WorkspaceID, &t.Name, &t.Color, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		result[pdfID] = append(result[pdfID], t)
+	}
+	return result, rows.Err()
+}
+
+func (r *SQLTagRepository) SetPDFTags(ctx context.Context, pdfID int, tagIDs []int64) (*TagChange, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current := map[int64]bool{}
+	rows, err := tx.QueryContext(ctx, `SELECT tag_id FROM pdf_tags WHERE pdf_id = $1`, pdfID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[id] = true
+	}
+	rows.Close()
+
+	wanted := map[int64]bool{}
+	change := &TagChange{Added: []int64{}, Removed: []int64{}}
+	for _, id := range tagIDs {
+		wanted[id] = true
+		if !current[id] {
+			change.Added = append(change.Added, id)
+		}
+	}
+	for id := range current {
+		if !wanted[id] {
+			change.Removed = append(change.Removed, id)
+		}
+	}
+	sort.Slice(change.Added, func(i, j int) bool { return change.Added[i] < change.Added[j] })
+	sort.Slice(change.Removed, func(i, j int) bool { return change.Removed[i] < change.Removed[j] })
+
+	for _, id := range change.Removed {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pdf_tags WHERE pdf_id = $1 AND tag_id = $2`, pdfID, id); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range change.Added {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO pdf_tags (pdf_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, pdfID, id); err != nil {
+			return nil, err
+		}
+	}
+	return change, tx.Commit()
+}
+
+func (r *SQLTagRepository) BulkUpdate(ctx context.Context, pdfIDs, add, remove []int64) (map[int]*TagChange, error) {
+	changes := map[int]*TagChange{}
+	if len(pdfIDs) == 0 {
+		return changes, nil
+	}
+	get := func(pdfID int) *TagChange {
+		if changes[pdfID] == nil {
+			changes[pdfID] = &TagChange{Added: []int64{}, Removed: []int64{}}
+		}
+		return changes[pdfID]
+	}
+	// scanTagIDs membaca RETURNING tag_id ke Added / Removed milik pdfID
+	scanTagIDs := func(rows *sql.Rows, pdfID int, added bool) error {
+		defer rows.Close()
+		for rows.Next() {
+			var tagID int64
+			if err := rows.Scan(&tagID); err != nil {
+				return err
+			}
+			if ch := get(pdfID); added {
+				ch.Added = append(ch.Added, tagID)
+			} else {
+				ch.Removed = append(ch.Removed, tagID)
+			}
+		}
+		return rows.Err()
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// satu PDF per statement: IN (...) + RETURNING jalan di Postgres & SQLite,
+	// dan pasangan yang workspace-nya beda otomatis tidak ter-insert lewat join
+	for _, pdfID := range pdfIDs {
+		if len(add) > 0 {
+			rows, err := tx.QueryContext(ctx, `
+				INSERT INTO pdf_tags (pdf_id, tag_id)
+				SELECT p.id, t.id FROM pdf_files p JOIN tags t ON t.workspace_id = p.workspace_id
+				WHERE p.id = $1 AND t.id IN (`+Placeholders(2, len(add))+`)
+				ON CONFLICT DO NOTHING
+				RETURNING tag_id
+			`, append([]interface{}{pdfID}, idArgs(add)...)...)
+			if err != nil {
+				return nil, err
+			}
+			if err := scanTagIDs(rows, int(pdfID), true); err != nil {
+				return nil, err
+			}
+		}
+		if len(remove) > 0 {
+			rows, err := tx.QueryContext(ctx, `
+				DELETE FROM pdf_tags WHERE pdf_id = $1 AND tag_id IN (`+Placeholders(2, len(remove))+`)
+				RETURNING tag_id
+			`, append([]interface{}{pdfID}, idArgs(remove)...)...)
+			if err != nil {
+				return nil, err
+			}
+			if err := scanTagIDs(rows, int(pdfID), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changes, tx.Commit()
+}
+
+// idArgs = []int64 jadi argumen query untuk IN (...) dari Placeholders.
+func idArgs(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+//for learn, tag many-to-many: tabel pdf_tags cuma berisi pasangan (pdf_id, tag_id)
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// UserRepository = akses tabel users (register, login, /auth/me, cari anggota baru).
+type UserRepository interface {
+	// Create membuat user + workspace pribadinya dalam satu transaksi, mengisi ID & CreatedAt user.
+	// ErrDuplicate kalau email sudah terdaftar.
+	Create(ctx context.Context, user *models.User, passwordHash string) (workspaceID int, err error)
+	Get(ctx context.Context, id int) (*models.User, error)
+	// GetByEmail tidak peduli huruf besar/kecil, PasswordHash ikut diisi.
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// SQLUserRepository: SQL-nya sama di Postgres dan SQLite.
+type SQLUserRepository struct {
+	DB *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *SQLUserRepository {
+	return &SQLUserRepository{DB: db}
+}
+
+func (r *SQLUserRepository) Create(ctx context.Context, user *models.User, passwordHash string) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (email, name, password_hash) VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, user.Email, user.Name, passwordHash).Scan(&user.ID, &user.CreatedAt)
+	if database.IsUniqueViolation(err) {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+	workspaceID, err := createPersonalWorkspace(ctx, tx, *user)
+	if err != nil {
+		return 0, err
+	}
+	return workspaceID, tx.Commit()
+}
+
+func (r *SQLUserRepository) Get(ctx context.Context, id int) (*models.User, error) {
+	var u models.User
+	err := r.DB.QueryRowContext(ctx, `SELECT id, email, name, created_at FROM users WHERE id = $1`, id).
+		Scan(&u.ID, &u.Email, &u.Name, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *SQLUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var u models.User
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT id, email, name, password_hash, created_at FROM users WHERE LOWER(email) = LOWER($1)
+	`, email).Scan(&u.ID, &u.Email, &u.Name, &u.PasswordHash, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// createAPIKey, listAPIKeys, revokeAPIKey dipakai kedua implementasi APIKeyRepository.
+func createAPIKey(ctx context.Context, db *sql.DB, key *models.APIKey, keyHash string) error {
+	return db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, expires_at) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, key.UserID, key.Name, key.Prefix, keyHash, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+}
+
+func listAPIKeys(ctx context.Context, db *sql.DB, userID int) ([]models.APIKey, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, user_id, name, prefix, created_at, last_used_at, expires_at, revoked_at
+		FROM api_keys WHERE user_id = $1 ORDER BY id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		var lastUsedAt, expiresAt, revokedAt sql.NullTime
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.CreatedAt, &lastUsedAt, &expiresAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		k.LastUsedAt = timePtr(lastUsedAt)
+		k.ExpiresAt = timePtr(expiresAt)
+		k.RevokedAt = timePtr(revokedAt)
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func revokeAPIKey(ctx context.Context, db *sql.DB, id, userID int) error {
+	res, err := db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	return affectedOne(res, err)
+}
+
+//for learn, user repository: register = user + workspace pribadi dalam satu transaksi
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pdf-backend-fiber/internal/database"
+	"pdf-backend-fiber/internal/models"
+)
+
+// WorkspaceRepository = akses tabel workspaces + workspace_members, termasuk cek role untuk semua handler.
+type WorkspaceRepository interface {
+	// Role = role user di workspace, "" kalau bukan anggota.
+	Role(ctx context.Context, workspaceID, userID int) (string, error)
+	// PDFRole = workspace PDF + role user di sana. ErrNotFound kalau PDF tidak ada atau user bukan anggota.
+	// trashed = cari PDF yang ada di trash, selain itu PDF di trash dianggap tidak ada.
+	PDFRole(ctx context.Context, pdfID, userID int, trashed bool) (int, string, error)
+	// ItemRole = versi PDFRole untuk baris tags / folders.
+	ItemRole(ctx context.Context, table string, id, userID int) (int, string, error)
+	PersonalID(ctx context.Context, userID int) (int, error)
+
+	ListForUser(ctx context.Context, userID int) ([]WorkspaceItem, error)
+	// Create membuat workspace baru, pembuatnya langsung jadi owner.
+	Create(ctx context.Context, name string, userID int) (*models.Workspace, error)
+	// GetForUser = workspace + role user di sana, ErrNotFound kalau bukan anggota.
+	GetForUser(ctx context.Context, workspaceID, userID int) (*models.Workspace, error)
+	// Rename mengembalikan nama lama.
+	Rename(ctx context.Context, workspaceID int, name string) (string, error)
+	// CountPDFs ikut menghitung PDF di trash.
+	CountPDFs(ctx context.Context, workspaceID int) (int, error)
+	Delete(ctx context.Context, workspaceID int) error
+
+	Members(ctx context.Context, workspaceID int) ([]models.WorkspaceMember, error)
+	// AddMember: ErrDuplicate kalau user sudah jadi anggota.
+	AddMember(ctx context.Context, workspaceID, userID int, role string) error
+	// SetMemberRole / RemoveMember mengembalikan role lama. Cek owner terakhir + perubahannya ada di satu transaksi:
+	// ErrNotFound kalau bukan anggota, ErrLastOwner kalau yang diubah owner terakhir.
+	SetMemberRole(ctx context.Context, workspaceID, userID int, role string) (string, error)
+	RemoveMember(ctx context.Context, workspaceID, userID int) (string, error)
+
+	// MovePDF memindah PDF ke workspace lain sekaligus melepas folder + tag-nya (berlaku per workspace).
+	// ErrDuplicate kalau isi yang sama sudah ada di workspace tujuan.
+	MovePDF(ctx context.Context, pdfID, workspaceID int) (*PDFMove, error)
+}
+
+// WorkspaceItem = baris list workspace: workspace + role user + jumlah PDF aktif.
+type WorkspaceItem struct {
+	Workspace models.Workspace
+	PDFCount  int
+}
+
+// PDFMove = yang dilepas waktu PDF pindah workspace, untuk audit log.
+type PDFMove struct {
+	OldFolderID *int
+	RemovedTags []int64
+}
+
+// SQLWorkspaceRepository: SQL-nya sama di Postgres dan SQLite (RETURNING, ON CONFLICT), jadi cukup satu implementasi.
+type SQLWorkspaceRepository struct {
+	DB     *sql.DB
+	Driver string //postgres / sqlite, SQLite tidak kenal FOR UPDATE
+}
+
+func NewWorkspaceRepository(db *sql.DB, driver string) *SQLWorkspaceRepository {
+	return &SQLWorkspaceRepository{DB: db, Driver: driver}
+}
+
+func (r *SQLWorkspaceRepository) Role(ctx context.Context, workspaceID, userID int) (string, error) {
+	var role string
+	err := r.DB.QueryRowContext(ctx, `SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *SQLWorkspaceRepository) PDFRole(ctx context.Context, pdfID, userID int, trashed bool) (int, string, error) {
+	var workspaceID int
+	var role string
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT p.workspace_id, m.role FROM pdf_files p
+		JOIN workspace_members m ON m.workspace_id = p.workspace_id AND m.user_id = $2
+		WHERE p.id = $1 AND (p.deleted_at IS NOT NULL) = $3
+	`, pdfID, userID, trashed).Scan(&workspaceID, &role)
+	if err == sql.ErrNoRows {
+		return 0, "", ErrNotFound
+	}
+	return workspaceID, role, err
+}
+
+// itemTables = tabel yang boleh dipakai ItemRole (nama tabel masuk ke SQL, jangan dari input user).
+var itemTables = map[string]bool{"tags": true, "folders": true}
+
+func (r *SQLWorkspaceRepository) ItemRole(ctx context.Context, table string, id, userID int) (int, string, error) {
+	if !itemTables[table] {
+		return 0, "", fmt.Errorf("ItemRole: unknown table %q", table)
+	}
+	var workspaceID int
+	var role string
+	err := r.DB.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT t.workspace_id, m.role FROM %s t
+		JOIN workspace_members m ON m.workspace_id = t.workspace_id AND m.user_id = $2
+		WHERE t.id = $1
+	`, table), id, userID).Scan(&workspaceID, &role)
+	if err == sql.ErrNoRows {
+		return 0, "", ErrNotFound
+	}
+	return workspaceID, role, err
+}
+
+func (r *SQLWorkspaceRepository) PersonalID(ctx context.Context, userID int) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, `SELECT id FROM workspaces WHERE personal AND created_by = $1 ORDER BY id LIMIT 1`, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	return id, err
+}
+
+func (r *SQLWorkspaceRepository) ListForUser(ctx context.Context, userID int) ([]WorkspaceItem, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT w.id, w.name, w.personal, COALESCE(w.created_by, 0), w.created_at, m.role,
+		       (SELECT COUNT(*) FROM pdf_files p WHERE p.workspace_id = w.id AND p.deleted_at IS NULL) AS pdf_count
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.personal DESC, w.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []WorkspaceItem
+	for rows.Next() {
+		var it WorkspaceItem
+		w := &it.Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.Personal, &w.CreatedBy, &w.CreatedAt, &w.Role, &it.PDFCount); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *SQLWorkspaceRepository) Create(ctx context.Context, name string, userID int) (*models.Workspace, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w := models.Workspace{Name: name, CreatedBy: userID, Role: models.RoleOwner}
+	if err := tx.QueryRowContext(ctx, `INSERT INTO workspaces (name, created_by) VALUES ($1, $2) RETURNING id, created_at`,
+		name, userID).Scan(&w.ID, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'owner')`,
+		w.ID, userID); err != nil {
+		return nil, err
+	}
+	return &w, tx.Commit()
+}
+
+// createPersonalWorkspace dipakai UserRepository.Create, di transaksi yang sama dengan INSERT user.
+func createPersonalWorkspace(ctx context.Context, tx *sql.Tx, user models.User) (int, error) {
+	name := user.Name
+	if name == "" {
+		name = user.Email
+	}
+	var id int
+	if err := tx.QueryRowContext(ctx, `INSERT INTO workspaces (name, personal, created_by) VALUES ($1, TRUE, $2) RETURNING id`,
+		name, user.ID).Scan(&id); err != nil {
+		return 0, err
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'owner')`, id, user.ID)
+	return id, err
+}
+
+func (r *SQLWorkspaceRepository) GetForUser(ctx context.Context, workspaceID, userID int) (*models.Workspace, error) {
+	var w models.Workspace
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT w.id, w.name, w.personal, COALESCE(w.created_by, 0), w.created_at, m.role
+		FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.id = $1
+	`, workspaceID, userID).Scan(&w.ID, &w.Name, &w.Personal, &w.CreatedBy, &w.CreatedAt, &w.Role)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *SQLWorkspaceRepository) Rename(ctx context.Context, workspaceID int, name string) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	err = tx.QueryRowContext(ctx, `SELECT name FROM workspaces WHERE id = $1`, workspaceID).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE workspaces SET name = $1 WHERE id = $2`, name, workspaceID); err != nil {
+		return "", err
+	}
+	return oldName, tx.Commit()
+}
+
+func (r *SQLWorkspaceRepository) CountPDFs(ctx context.Context, workspaceID int) (int, error) {
+	var n int
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM pdf_files WHERE workspace_id = $1`, workspaceID).Scan(&n)
+	return n, err
+}
+
+func (r *SQLWorkspaceRepository) Delete(ctx context.Context, workspaceID int) error {
+	return deleteByID(ctx, r.DB, "workspaces", workspaceID)
+}
+
+func (r *SQLWorkspaceRepository) Members(ctx context.Context, workspaceID int) ([]models.WorkspaceMember, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT u.id, u.email, u.name, m.role, m.created_at
+		FROM workspace_members m JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at
+	`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		var m models.WorkspaceMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *SQLWorkspaceRepository) AddMember(ctx context.Context, workspaceID, userID int, role string) error {
+	res, err := r.DB.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, workspaceID, userID, role)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+func (r *SQLWorkspaceRepository) SetMemberRole(ctx context.Context, workspaceID, userID int, role string) (string, error) {
+	return r.changeMember(ctx, workspaceID, userID, role)
+}
+
+func (r *SQLWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID int) (string, error) {
+	return r.changeMember(ctx, workspaceID, userID, "")
+}
+
+// changeMember mengganti role anggota (role "" = dikeluarkan).
+// Semua baris owner dikunci dulu (FOR UPDATE di Postgres; transaksi SQLite sudah immediate = satu penulis),
+// jadi dua owner yang saling menurunkan role bersamaan tidak bisa membuat workspace tanpa owner.
+func (r *SQLWorkspaceRepository) changeMember(ctx context.Context, workspaceID, userID int, role string) (string, error) {
+	lock := " FOR UPDATE"
+	if r.Driver == "sqlite" {
+		lock = ""
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM workspace_members WHERE workspace_id = $1 AND role = 'owner'`+lock, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	owners := 0
+	for rows.Next() {
+		owners++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`+lock,
+		workspaceID, userID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if current == models.RoleOwner && role != models.RoleOwner && owners <= 1 {
+		return current, ErrLastOwner
+	}
+
+	if role == "" {
+		_, err = tx.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE workspace_members SET role = $1 WHERE workspace_id = $2 AND user_id = $3`,
+			role, workspaceID, userID)
+	}
+	if err != nil {
+		return current, err
+	}
+	return current, tx.Commit()
+}
+
+func (r *SQLWorkspaceRepository) MovePDF(ctx context.Context, pdfID, workspaceID int) (*PDFMove, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var oldFolderID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT folder_id FROM pdf_files WHERE id = $1`, pdfID).Scan(&oldFolderID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE pdf_files SET workspace_id = $1, folder_id = NULL WHERE id = $2`, workspaceID, pdfID)
+	if database.IsUniqueViolation(err) {
+		return nil, ErrDuplicate
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	move := &PDFMove{OldFolderID: intPtr(oldFolderID), RemovedTags: []int64{}}
+	rows, err := tx.QueryContext(ctx, `SELECT tag_id FROM pdf_tags WHERE pdf_id = $1 ORDER BY tag_id`, pdfID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var tagID int64
+		if err := rows.Scan(&tagID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		move.RemovedTags = append(move.RemovedTags, tagID)
+	}
+	rows.Close()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pdf_tags WHERE pdf_id = $1`, pdfID); err != nil {
+		return nil, err
+	}
+	return move, tx.Commit()
+}
+
+//for learn, workspace repository: semua cek role (PDFRole, ItemRole) lewat sini supaya aturan aksesnya satu tempat
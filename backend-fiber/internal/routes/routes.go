@@ -5,36 +5,120 @@ import (
 
 	"pdf-backend-fiber/internal/config"
 	"pdf-backend-fiber/internal/handlers"
+	"pdf-backend-fiber/internal/janitor"
+	"pdf-backend-fiber/internal/jobs"
+	"pdf-backend-fiber/internal/storage"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func Setup(app *fiber.App, db *sql.DB, cfg config.Config) {
+func Setup(app *fiber.App, db *sql.DB, cfg config.Config, queue *jobs.Queue, j *janitor.Janitor, store storage.Storage) {
 	// Initialize handlers
-	uploadHandler := handlers.NewUploadHandler(db, cfg)
-	pdfHandler := handlers.NewPdfHandler(db, cfg)
-	healthHandler := handlers.NewHealthHandler(db)
-	exportHandler := handlers.NewExportHandler()
+	uploadHandler := handlers.NewUploadHandler(db, cfg, queue, store)
+	pdfHandler := handlers.NewPdfHandler(db, cfg, queue, store)
+	healthHandler := handlers.NewHealthHandler(db, queue.Summarizers)
+	exportHandler := handlers.NewExportHandler(db)
+	jobHandler := handlers.NewJobHandler(db)
+	adminHandler := handlers.NewAdminHandler(cfg, j)
+	shareHandler := handlers.NewShareHandler(db, cfg, pdfHandler)
+	authHandler := handlers.NewAuthHandler(db, cfg)
+	workspaceHandler := handlers.NewWorkspaceHandler(db, cfg.DBDriver)
+	auditHandler := handlers.NewAuditHandler(db)
+	searchHandler := handlers.NewSearchHandler(db, cfg.DBDriver)
+	tagHandler := handlers.NewTagHandler(db)
+	folderHandler := handlers.NewFolderHandler(db, cfg.DBDriver)
+
+	// Public routes (tanpa login)
+	app.Get("/health", healthHandler.Health)
+	app.Get("/test-db", healthHandler.TestDB)
+	app.Post("/auth/register", authHandler.Register)
+	app.Post("/auth/login", authHandler.Login)
+	app.Get("/share/:token", shareHandler.ViewShareLink)
+
+	// Admin routes (session chunk upload + janitor), pakai X-Admin-Token bukan login user
+	admin := app.Group("/admin", adminHandler.RequireToken)
+	admin.Get("/uploads", adminHandler.ListUploadSessions)
+	admin.Delete("/uploads/:id", adminHandler.PurgeUploadSession)
+	admin.Post("/uploads/sweep", adminHandler.SweepUploadSessions)
+	admin.Get("/janitor/metrics", adminHandler.JanitorMetrics)
+
+	// semua route di bawah ini wajib login (JWT / API key). Urutan penting: route di atas tidak kena middleware ini
+	app.Use(authHandler.RequireAuth)
+
+	app.Get("/auth/me", authHandler.Me)
+	app.Get("/auth/api-keys", authHandler.ListAPIKeys)
+	app.Post("/auth/api-keys", authHandler.CreateAPIKey)
+	app.Delete("/auth/api-keys/:id", authHandler.RevokeAPIKey)
+
+	// Workspace + anggota (role owner / editor / viewer)
+	app.Get("/workspaces", workspaceHandler.ListWorkspaces)
+	app.Post("/workspaces", workspaceHandler.CreateWorkspace)
+	app.Get("/workspaces/:id", workspaceHandler.GetWorkspace)
+	app.Put("/workspaces/:id", workspaceHandler.RenameWorkspace)
+	app.Delete("/workspaces/:id", workspaceHandler.DeleteWorkspace)
+	app.Post("/workspaces/:id/members", workspaceHandler.AddMember)
+	app.Put("/workspaces/:id/members/:user_id", workspaceHandler.UpdateMember)
+	app.Delete("/workspaces/:id/members/:user_id", workspaceHandler.RemoveMember)
 
-	// Routes
 	app.Post("/upload/init", uploadHandler.InitChunkUpload)
 	app.Post("/upload/chunk", uploadHandler.UploadChunk)
 	app.Get("/upload/status", uploadHandler.UploadStatus)
 	app.Post("/upload/complete", uploadHandler.CompleteChunkUpload)
+
+	// tus 1.0 resumable upload (core + creation, checksum, termination, expiration)
+	tus := app.Group("/files", uploadHandler.TusMiddleware)
+	tus.Options("/", uploadHandler.TusOptions)
+	tus.Options("/:id", uploadHandler.TusOptions)
+	tus.Post("/", uploadHandler.TusCreate)
+	tus.Head("/:id", uploadHandler.TusHead)
+	tus.Patch("/:id", uploadHandler.TusPatch)
+	tus.Delete("/:id", uploadHandler.TusDelete)
 	app.Get("/pdf/:id", pdfHandler.GetPDF)
-	app.Delete("/pdf/:id", pdfHandler.DeletePDF)
+	app.Get("/pdf/:id/file", pdfHandler.GetPDFFile)
+	app.Delete("/pdf/:id", pdfHandler.DeletePDF) //soft delete, masuk trash
+	app.Post("/pdf/:id/restore", pdfHandler.RestorePDF)
+	app.Get("/trash", pdfHandler.ListTrash)
+	app.Delete("/trash/:id", pdfHandler.PurgePDF)
+	app.Get("/pdfs", pdfHandler.ListPDFs) //listing dengan filter, sort, cursor
 	app.Get("/history", pdfHandler.GetHistory)
-	app.Get("/health", healthHandler.Health)
-	app.Get("/test-db", healthHandler.TestDB)
+	app.Get("/search", searchHandler.Search)
 	app.Get("/simple-pdfs", pdfHandler.SimplePDFs)
 	app.Get("/simple-pdf/:id", pdfHandler.SimplePDFByID)
 	app.Put("/update-pdf/:id", pdfHandler.UpdatePDF)
 	app.Post("/resummarize/:id", pdfHandler.Resummarize)
+	app.Post("/pdf/:id/retry", pdfHandler.RetrySummary) //ulang ringkasan yang gagal saja
 	app.Get("/summaries/:id", pdfHandler.GetSummaries)
+	app.Post("/pdf/:id/move", workspaceHandler.MovePDF)
+
+	// Tag & folder (per workspace)
+	app.Get("/tags", tagHandler.ListTags)
+	app.Post("/tags", tagHandler.CreateTag)
+	app.Post("/tags/bulk", tagHandler.BulkTag)
+	app.Put("/tags/:id", tagHandler.UpdateTag)
+	app.Delete("/tags/:id", tagHandler.DeleteTag)
+	app.Put("/pdf/:id/tags", tagHandler.SetPDFTags)
+	app.Get("/folders", folderHandler.ListFolders)
+	app.Post("/folders", folderHandler.CreateFolder)
+	app.Put("/folders/:id", folderHandler.UpdateFolder)
+	app.Delete("/folders/:id", folderHandler.DeleteFolder)
+	app.Put("/pdf/:id/folder", folderHandler.SetPDFFolder)
+
+	// Share link (buat / list / cabut); endpoint publiknya ada di atas
+	app.Post("/pdf/:id/share", shareHandler.CreateShareLink)
+	app.Get("/pdf/:id/shares", shareHandler.ListShareLinks)
+	app.Delete("/shares/:id", shareHandler.RevokeShareLink)
+
+	// Summary job routes (status + SSE progress)
+	app.Get("/jobs/:id", jobHandler.GetJob)
+	app.Get("/jobs/:id/events", jobHandler.JobEvents)
 
 	// Export routes (CSV & JSON)
 	app.Post("/export/csv", exportHandler.ExportCSV)
 	app.Post("/export/json", exportHandler.ExportJSON)
+
+	// Audit log (read-only)
+	app.Get("/audit", auditHandler.ListAudit)
+	app.Get("/audit/export", auditHandler.ExportAuditCSV)
 }
 
 //Kita membuat handler sekali
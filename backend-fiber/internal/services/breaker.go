@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen = panggilan ditolak tanpa menghubungi service karena circuit breaker sedang open.
+var ErrCircuitOpen = errors.New("AI service unavailable (circuit breaker open)")
+
+// state circuit breaker
+const (
+	BreakerClosed   = "closed"    //normal, semua panggilan diteruskan
+	BreakerOpen     = "open"      //service dianggap mati, panggilan langsung gagal sampai cooldown lewat
+	BreakerHalfOpen = "half_open" //cooldown lewat, satu panggilan percobaan diteruskan
+)
+
+// CircuitBreaker menghitung kegagalan berturut-turut ke satu service. Setelah Threshold kali gagal,
+// panggilan berikutnya langsung ErrCircuitOpen selama Cooldown supaya worker tidak menunggu timeout satu per satu.
+type CircuitBreaker struct {
+	Name      string
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool //half_open: percobaan sedang jalan, panggilan lain tetap ditolak
+	lastErr  string
+}
+
+// BreakerStatus = snapshot circuit breaker untuk /health.
+type BreakerStatus struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Failures  int        `json:"consecutive_failures"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"` //kapan panggilan percobaan berikutnya diizinkan
+	LastError string     `json:"last_error,omitempty"`
+}
+
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	return &CircuitBreaker{Name: name, Threshold: threshold, Cooldown: cooldown, state: BreakerClosed}
+}
+
+// Allow dipanggil sebelum setiap percobaan; error = jangan hubungi service.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success = service menjawab (termasuk 4xx: service hidup, request-nya yang salah).
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+	b.lastErr = ""
+}
+
+// Release = percobaan selesai tanpa hasil yang bisa dinilai (misal request dibatalkan client), state tidak berubah.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Failure = timeout / error jaringan / 5xx.
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.Threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := BreakerStatus{Name: b.Name, State: b.state, Failures: b.failures, LastError: b.lastErr}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.Cooldown)
+		st.OpenedAt, st.RetryAt = &openedAt, &retryAt
+	}
+	return st
+}
+
+//circuit breaker = sekring: kalau AI service mati, berhenti mencoba sebentar daripada semua request menunggu timeout
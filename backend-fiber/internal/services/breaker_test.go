@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	// langkah yang dijalankan berurutan; "cooldown" memundurkan openedAt supaya tidak perlu sleep
+	type step struct {
+		op        string //allow, success, failure, release, cooldown
+		wantErr   error  //hasil Allow
+		wantState string
+	}
+	boom := errors.New("boom")
+	tripped := []step{
+		{"allow", nil, BreakerClosed},
+		{"failure", nil, BreakerClosed},
+		{"allow", nil, BreakerClosed},
+		{"failure", nil, BreakerOpen},
+		{"allow", ErrCircuitOpen, BreakerOpen},
+		{"cooldown", nil, BreakerOpen},
+		{"allow", nil, BreakerHalfOpen}, //probe
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"probe berhasil menutup breaker", append(tripped[:len(tripped):len(tripped)],
+			step{"allow", ErrCircuitOpen, BreakerHalfOpen}, //cuma satu probe sekaligus
+			step{"success", nil, BreakerClosed},
+			step{"allow", nil, BreakerClosed},
+			step{"allow", nil, BreakerClosed},
+		)},
+		{"probe gagal langsung open lagi", append(tripped[:len(tripped):len(tripped)],
+			step{"failure", nil, BreakerOpen},
+			step{"allow", ErrCircuitOpen, BreakerOpen},
+			step{"cooldown", nil, BreakerOpen},
+			step{"allow", nil, BreakerHalfOpen},
+		)},
+		{"probe dibatalkan, probe berikutnya boleh", append(tripped[:len(tripped):len(tripped)],
+			step{"release", nil, BreakerHalfOpen},
+			step{"allow", nil, BreakerHalfOpen},
+			step{"allow", ErrCircuitOpen, BreakerHalfOpen},
+		)},
+		{"sukses mereset hitungan gagal", []step{
+			{"failure", nil, BreakerClosed},
+			{"success", nil, BreakerClosed},
+			{"failure", nil, BreakerClosed},
+			{"allow", nil, BreakerClosed},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewCircuitBreaker("test", 2, time.Hour)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "allow":
+					if err := b.Allow(); err != s.wantErr {
+						t.Fatalf("step %d: Allow() = %v, want %v", i, err, s.wantErr)
+					}
+				case "success":
+					b.Success()
+				case "failure":
+					b.Failure(boom)
+				case "release":
+					b.Release()
+				case "cooldown":
+					b.mu.Lock()
+					b.openedAt = time.Now().Add(-2 * b.Cooldown)
+					b.mu.Unlock()
+				}
+				if got := b.Status().State; got != s.wantState {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, s.op, got, s.wantState)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// CacheKey = identitas satu hasil ringkasan: isi file + style + backend + model + versi prompt.
+type CacheKey struct {
+	ContentSHA256 string
+	Style         string
+	Provider      string
+	Model         string
+	PromptVersion string
+}
+
+// SummaryCache menyimpan hasil ringkasan di tabel summary_cache.
+type SummaryCache struct {
+	DB *sql.DB
+}
+
+func NewSummaryCache(db *sql.DB) *SummaryCache {
+	return &SummaryCache{DB: db}
+}
+
+func (c *SummaryCache) Get(key CacheKey) (*SummaryResult, bool) {
+	var result SummaryResult
+	var language, model sql.NullString
+	var chunksCount, charsCovered, totalChars sql.NullInt64
+	err := c.DB.QueryRow(`
+		UPDATE summary_cache SET hits = hits + 1, last_hit_at = NOW()
+		WHERE content_sha256 = $1 AND style = $2 AND provider = $3 AND model = $4 AND prompt_version = $5
+		RETURNING summary_text, language_detected, result_model, chunks_count, chars_covered, total_chars
+	`, key.ContentSHA256, key.Style, key.Provider, key.Model, key.PromptVersion).Scan(
+		&result.Summary, &language, &model, &chunksCount, &charsCovered, &totalChars)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Summary cache lookup failed: %v", err)
+		}
+		return nil, false
+	}
+
+	result.Language = language.String
+	result.Style = key.Style
+	result.Provider = key.Provider
+	result.Model = model.String
+	result.ChunksCount = int(chunksCount.Int64)
+	result.CharsCovered = int(charsCovered.Int64)
+	result.TotalChars = int(totalChars.Int64)
+	result.CacheHit = true
+	return &result, true
+}
+
+// Put menyimpan (atau menimpa) hasil untuk key ini. Gagal simpan cuma di-log, ringkasannya tetap dipakai.
+func (c *SummaryCache) Put(key CacheKey, result *SummaryResult) {
+	_, err := c.DB.Exec(`
+		INSERT INTO summary_cache (content_sha256, style, provider, model, prompt_version,
+		                           summary_text, language_detected, result_model, chunks_count, chars_covered, total_chars)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (content_sha256, style, provider, model, prompt_version) DO UPDATE SET
+			summary_text = EXCLUDED.summary_text,
+			language_detected = EXCLUDED.language_detected,
+			result_model = EXCLUDED.result_model,
+			chunks_count = EXCLUDED.chunks_count,
+			chars_covered = EXCLUDED.chars_covered,
+			total_chars = EXCLUDED.total_chars,
+			created_at = NOW()
+	`, key.ContentSHA256, key.Style, key.Provider, key.Model, key.PromptVersion,
+		result.Summary, result.Language, result.Model, result.ChunksCount, result.CharsCovered, result.TotalChars)
+	if err != nil {
+		log.Printf("Summary cache store failed: %v", err)
+	}
+}
+
+// CachedSummarizer mengecek summary_cache dulu sebelum memanggil summarizer di dalamnya.
+// Hasil mock dari Python tidak disimpan, biar tidak nyangkut setelah GEMINI_API_KEY dipasang.
+type CachedSummarizer struct {
+	Base  Summarizer
+	Cache *SummaryCache
+}
+
+func NewCachedSummarizer(base Summarizer, cache *SummaryCache) *CachedSummarizer {
+	return &CachedSummarizer{Base: base, Cache: cache}
+}
+
+func (s *CachedSummarizer) Name() string {
+	return s.Base.Name()
+}
+
+func (s *CachedSummarizer) ModelName() string {
+	if mn, ok := s.Base.(ModelNamer); ok {
+		return mn.ModelName()
+	}
+	return ""
+}
+
+func (s *CachedSummarizer) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	contentHash := req.ContentSHA256
+	if contentHash == "" {
+		h, err := HashFile(req.FilePath)
+		if err != nil {
+			return s.Base.Summarize(ctx, req) //tanpa hash tidak bisa pakai cache
+		}
+		contentHash = h
+	}
+	key := CacheKey{
+		ContentSHA256: contentHash,
+		Style:         req.Style,
+		Provider:      s.Name(),
+		Model:         s.ModelName(),
+		PromptVersion: PromptVersion,
+	}
+
+	if !req.NoCache {
+		start := time.Now()
+		if result, ok := s.Cache.Get(key); ok {
+			result.DurationMs = time.Since(start).Milliseconds()
+			return result, nil
+		}
+	}
+
+	result, err := s.Base.Summarize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if result.Model != "mock" && result.Summary != "" {
+		s.Cache.Put(key, result)
+	}
+	return result, nil
+}
+
+// HashFile menghitung SHA-256 isi file (hex), sama dengan kolom pdf_files.content_sha256.
+func HashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+//cache hasil ringkasan, resummarize file + style yang sama tidak perlu panggil AI lagi
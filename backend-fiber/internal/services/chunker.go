@@ -0,0 +1,132 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TextChunk = potongan teks dokumen. Start/End adalah offset karakter (rune) di teks asli.
+type TextChunk struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+type textUnit struct {
+	start, end int
+	heading    bool
+}
+
+var headingPrefixRegex = regexp.MustCompile(`^(\d+(\.\d+)*\.?|[IVXLC]+\.|[A-Z]\.|BAB|Bab|CHAPTER|Chapter|Pasal|PASAL|Bagian|BAGIAN)\s`)
+
+// isHeading menebak apakah satu baris adalah judul bagian (section).
+func isHeading(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || len([]rune(line)) > 80 {
+		return false
+	}
+	if strings.HasSuffix(line, ":") || headingPrefixRegex.MatchString(line) {
+		return true
+	}
+	letters, upper := 0, 0
+	for _, r := range line {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 3 && upper == letters //baris HURUF BESAR semua
+}
+
+// splitUnits memecah teks per baris; baris yang lebih panjang dari size dipecah lagi per kalimat / dipotong paksa.
+func splitUnits(runes []rune, size int) []textUnit {
+	var units []textUnit
+	lineStart := 0
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && runes[i] != '\n' {
+			continue
+		}
+		end := i
+		if i < len(runes) {
+			end = i + 1 //newline ikut ke baris ini
+		}
+		if end > lineStart {
+			heading := isHeading(string(runes[lineStart:end]))
+			for _, u := range splitLongUnit(runes, lineStart, end, size) {
+				u.heading = heading && u.start == lineStart
+				units = append(units, u)
+			}
+		}
+		lineStart = end
+	}
+	return units
+}
+
+func splitLongUnit(runes []rune, start, end, size int) []textUnit {
+	var units []textUnit
+	for end-start > size {
+		cut := start + size
+		// cari akhir kalimat terdekat sebelum batas, biar tidak motong di tengah kalimat
+		for j := cut - 1; j > start+size/2; j-- {
+			if (runes[j] == '.' || runes[j] == '!' || runes[j] == '?') && j+1 < end && unicode.IsSpace(runes[j+1]) {
+				cut = j + 1
+				break
+			}
+		}
+		units = append(units, textUnit{start: start, end: cut})
+		start = cut
+	}
+	return append(units, textUnit{start: start, end: end})
+}
+
+// SplitText membagi teks jadi chunk maksimal size karakter yang saling overlap.
+// Batas chunk diusahakan jatuh sebelum judul bagian (section-aware) dan di batas baris;
+// overlap diambil dari baris-baris terakhir chunk sebelumnya (maksimal overlap karakter).
+func SplitText(text string, size, overlap int) []TextChunk {
+	runes := []rune(text)
+	if size <= 0 {
+		size = len(runes)
+	}
+	units := splitUnits(runes, size)
+
+	var chunks []TextChunk
+	i := 0
+	for i < len(units) {
+		start := units[i].start
+		j := i
+		for j < len(units) {
+			u := units[j]
+			if j > i && u.end-start > size {
+				break
+			}
+			// judul bagian baru: tutup chunk kalau isinya sudah lumayan (>= setengah size)
+			if j > i && u.heading && units[j-1].end-start >= size/2 {
+				break
+			}
+			j++
+		}
+		end := units[j-1].end
+
+		chunkText := strings.TrimSpace(string(runes[start:end]))
+		if chunkText != "" {
+			chunks = append(chunks, TextChunk{Index: len(chunks), Start: start, End: end, Text: chunkText})
+		}
+		if j >= len(units) {
+			break
+		}
+
+		// mundur beberapa baris untuk overlap, tapi tetap maju minimal satu baris
+		k := j
+		for k-1 > i && end-units[k-1].start <= overlap {
+			k--
+		}
+		i = k
+	}
+	return chunks
+}
+
+//pemotong teks dokumen panjang untuk map-reduce summarization
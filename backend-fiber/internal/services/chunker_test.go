@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	// 40 baris pendek (masing-masing 19 karakter + newline = 800 karakter)
+	var lines strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&lines, "baris nomor %02d isi.\n", i)
+	}
+	// satu paragraf panjang tanpa newline, harus dipecah per kalimat
+	paragraph := strings.Repeat("Kalimat ini cukup panjang untuk dipotong. ", 30)
+	// judul bagian di tengah: chunk baru mulai di judul kalau chunk sekarang sudah >= setengah size
+	sections := strings.Repeat("isi bagian pertama.\n", 6) + "BAB 2 Metode\n" + strings.Repeat("isi bagian kedua.\n", 6)
+
+	tests := []struct {
+		name        string
+		text        string
+		size        int
+		overlap     int
+		wantChunks  int    //0 = tidak dicek
+		startsWith  string //chunk kedua harus diawali teks ini ("" = tidak dicek)
+		wantOverlap bool   //chunk berurutan harus overlap
+	}{
+		{"teks pendek satu chunk", "halo dunia", 100, 10, 1, "", false},
+		{"size 0 = satu chunk", lines.String(), 0, 0, 1, "", false},
+		{"tanpa overlap", lines.String(), 200, 0, 4, "", false},
+		{"dengan overlap", lines.String(), 200, 50, 0, "", true},
+		{"overlap lebih besar dari size tetap maju", lines.String(), 100, 500, 0, "", true},
+		{"paragraf panjang", paragraph, 300, 0, 0, "", false},
+		{"potong sebelum judul", sections, 240, 0, 2, "BAB 2 Metode", false},
+		{"teks kosong", "", 100, 10, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runes := []rune(tt.text)
+			chunks := SplitText(tt.text, tt.size, tt.overlap)
+			if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			if len(runes) == 0 {
+				if len(chunks) != 0 {
+					t.Fatalf("got %d chunks for empty text", len(chunks))
+				}
+				return
+			}
+
+			// coverage: chunk pertama dari awal, terakhir sampai akhir, tidak ada celah di antaranya
+			if chunks[0].Start != 0 {
+				t.Errorf("first chunk starts at %d", chunks[0].Start)
+			}
+			if last := chunks[len(chunks)-1]; last.End != len(runes) {
+				t.Errorf("last chunk ends at %d, want %d", last.End, len(runes))
+			}
+			for i, c := range chunks {
+				if c.Index != i {
+					t.Errorf("chunk %d has Index %d", i, c.Index)
+				}
+				if tt.size > 0 && c.End-c.Start > tt.size {
+					t.Errorf("chunk %d is %d chars, size %d", i, c.End-c.Start, tt.size)
+				}
+				if c.Text != strings.TrimSpace(string(runes[c.Start:c.End])) {
+					t.Errorf("chunk %d text does not match its offsets", i)
+				}
+				if i == 0 {
+					continue
+				}
+				prev := chunks[i-1]
+				if c.Start <= prev.Start {
+					t.Errorf("chunk %d does not advance (start %d, previous %d)", i, c.Start, prev.Start)
+				}
+				if c.Start > prev.End {
+					t.Errorf("gap between chunk %d and %d: %d..%d", i-1, i, prev.End, c.Start)
+				}
+				shared := prev.End - c.Start
+				if tt.wantOverlap && shared <= 0 {
+					t.Errorf("chunk %d does not overlap the previous one", i)
+				}
+				if !tt.wantOverlap && shared != 0 {
+					t.Errorf("chunk %d overlaps %d chars without overlap", i, shared)
+				}
+				if tt.overlap < tt.size && shared > tt.overlap {
+					t.Errorf("chunk %d overlaps %d chars, max %d", i, shared, tt.overlap)
+				}
+			}
+			if tt.startsWith != "" && (len(chunks) < 2 || !strings.HasPrefix(chunks[1].Text, tt.startsWith)) {
+				t.Errorf("second chunk should start with %q", tt.startsWith)
+			}
+		})
+	}
+}
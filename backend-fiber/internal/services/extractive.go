@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/pdfinfo"
+)
+
+// jumlah kalimat yang diambil per style
+var extractiveSentenceCount = map[string]int{
+	"executive": 3,
+	"standard":  5,
+	"bullets":   7,
+	"detailed":  10,
+}
+
+var sentenceSplitRegex = regexp.MustCompile(`[^.!?\n]+[.!?]*`)
+
+// ExtractiveSummarizer meringkas tanpa AI: ambil kalimat dengan skor frekuensi kata tertinggi.
+// Hasilnya deterministik (input sama = output sama), cocok untuk offline & test.
+type ExtractiveSummarizer struct{}
+
+func NewExtractiveSummarizer() *ExtractiveSummarizer {
+	return &ExtractiveSummarizer{}
+}
+
+func (e *ExtractiveSummarizer) Name() string {
+	return "extractive"
+}
+
+func (e *ExtractiveSummarizer) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	text, err := pdfinfo.ExtractText(req.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, ErrNoText
+	}
+	return e.SummarizeText(ctx, text, req.Style, "")
+}
+
+func (e *ExtractiveSummarizer) SummarizeText(ctx context.Context, text, style, language string) (*SummaryResult, error) {
+	start := time.Now()
+	if language == "" {
+		language = DetectLanguage(text)
+	}
+	return &SummaryResult{
+		Summary:    SummarizeExtractive(text, style),
+		Language:   language,
+		Style:      style,
+		Provider:   e.Name(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// SummarizeExtractive memilih kalimat terpenting dari teks lalu menyusunnya sesuai style.
+func SummarizeExtractive(text, style string) string {
+	var sentences []string
+	seen := map[string]bool{}
+	for _, s := range sentenceSplitRegex.FindAllString(text, -1) {
+		s = strings.Join(strings.Fields(s), " ")
+		key := strings.Join(tokenize(s), " ")
+		if len(tokenize(s)) < 4 || seen[key] { //kalimat terlalu pendek biasanya judul/nomor halaman
+			continue
+		}
+		seen[key] = true
+		sentences = append(sentences, s)
+	}
+	if len(sentences) == 0 {
+		return strings.TrimSpace(truncateRunes(text, 500))
+	}
+
+	// frekuensi kata (tanpa stopword) sebagai bobot
+	freq := map[string]int{}
+	for _, s := range sentences {
+		for _, w := range tokenize(s) {
+			if len(w) > 2 && !indonesianStopwords[w] && !englishStopwords[w] {
+				freq[w]++
+			}
+		}
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, len(sentences))
+	for i, s := range sentences {
+		words := tokenize(s)
+		total := 0
+		for _, w := range words {
+			total += freq[w]
+		}
+		scores[i] = scored{index: i, score: float64(total) / float64(len(words))}
+	}
+	sort.SliceStable(scores, func(a, b int) bool {
+		if scores[a].score != scores[b].score {
+			return scores[a].score > scores[b].score
+		}
+		return scores[a].index < scores[b].index
+	})
+
+	n, ok := extractiveSentenceCount[style]
+	if !ok {
+		n = extractiveSentenceCount["standard"]
+	}
+	if n > len(scores) {
+		n = len(scores)
+	}
+	picked := make([]int, 0, n)
+	for _, s := range scores[:n] {
+		picked = append(picked, s.index)
+	}
+	sort.Ints(picked) //kembalikan ke urutan asli dokumen
+
+	var b strings.Builder
+	for i, idx := range picked {
+		if style == "bullets" {
+			b.WriteString("• " + sentences[idx] + "\n")
+			continue
+		}
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(sentences[idx])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+//summarizer ekstraktif go murni, jalan tanpa service AI sama sekali
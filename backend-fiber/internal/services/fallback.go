@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// FallbackStep = satu provider di chain beserta batas waktunya (0 = tanpa batas tambahan).
+type FallbackStep struct {
+	Summarizer Summarizer
+	Timeout    time.Duration
+}
+
+// FallbackSummarizer mencoba provider sesuai urutan SUMMARIZER_CHAIN sampai ada yang berhasil,
+// misal Gemini (python) kena rate limit / mati lalu jatuh ke model lokal (openai) atau extractive.
+// Provider yang benar-benar menghasilkan ringkasan ada di SummaryResult.Provider / Model.
+type FallbackSummarizer struct {
+	Steps []FallbackStep
+}
+
+func NewFallbackSummarizer(steps ...FallbackStep) *FallbackSummarizer {
+	return &FallbackSummarizer{Steps: steps}
+}
+
+func (f *FallbackSummarizer) Name() string {
+	names := make([]string, len(f.Steps))
+	for i, s := range f.Steps {
+		names[i] = s.Summarizer.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// ProviderError = kegagalan satu provider di chain.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+// FallbackError = semua provider di chain gagal. Unwrap = error provider terakhir (dipakai ErrorCode).
+type FallbackError struct {
+	Errors []ProviderError
+}
+
+func (e *FallbackError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, pe := range e.Errors {
+		parts[i] = pe.Provider + ": " + pe.Err.Error()
+	}
+	return fmt.Sprintf("all %d providers failed (%s)", len(e.Errors), strings.Join(parts, "; "))
+}
+
+func (e *FallbackError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[len(e.Errors)-1].Err
+}
+
+func (f *FallbackSummarizer) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	if len(f.Steps) == 1 {
+		return f.try(ctx, f.Steps[0], req) //tanpa fallback, error dikembalikan apa adanya
+	}
+
+	var failed []ProviderError
+	for i, step := range f.Steps {
+		result, err := f.try(ctx, step, req)
+		if err == nil {
+			return result, nil
+		}
+		failed = append(failed, ProviderError{Provider: step.Summarizer.Name(), Err: err})
+
+		// PDF tanpa teks / request dibatalkan client: provider lain juga pasti gagal
+		if errors.Is(err, ErrNoText) || ctx.Err() != nil || i == len(f.Steps)-1 {
+			break
+		}
+		next := f.Steps[i+1].Summarizer.Name()
+		log.Printf("Summarizer %s failed (%v), falling back to %s", step.Summarizer.Name(), err, next)
+		if req.Fallback != nil {
+			req.Fallback(step.Summarizer.Name(), next, err)
+		}
+	}
+	return nil, &FallbackError{Errors: failed}
+}
+
+func (f *FallbackSummarizer) try(ctx context.Context, step FallbackStep, req SummaryRequest) (*SummaryResult, error) {
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+	return step.Summarizer.Summarize(ctx, req)
+}
+
+//chain provider summary: gagal di satu provider, coba provider berikutnya
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSummarizer mengembalikan err, atau hasil dengan Provider = name dan Model = model.
+// delay > 0 = tunggu selama itu (atau sampai ctx selesai) sebelum menjawab.
+type fakeSummarizer struct {
+	name, model string
+	err         error
+	delay       time.Duration
+	calls       int
+}
+
+func (f *fakeSummarizer) Name() string { return f.name }
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	f.calls++
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &SummaryResult{Summary: "ringkasan " + f.name, Provider: f.name, Model: f.model}, nil
+}
+
+func TestFallbackSummarizer(t *testing.T) {
+	down := &ServiceError{Kind: ErrKindTransport, Err: errors.New("connection refused")}
+	rejected := &ServiceError{Kind: ErrKindClient, Err: errors.New("400 bad request")}
+
+	tests := []struct {
+		name         string
+		steps        []*fakeSummarizer
+		timeouts     []time.Duration //per step, kosong = tanpa batas
+		wantProvider string          //"" = harus gagal
+		wantModel    string
+		wantCode     string   //ErrorCode kalau gagal
+		wantCalls    []int    //berapa kali tiap step dipanggil
+		wantFallback []string //"from->to" yang dilaporkan lewat req.Fallback
+	}{
+		{
+			name:         "primary gagal, secondary berhasil",
+			steps:        []*fakeSummarizer{{name: "python", err: down}, {name: "openai", model: "llama3"}},
+			wantProvider: "openai", wantModel: "llama3",
+			wantCalls:    []int{1, 1},
+			wantFallback: []string{"python->openai"},
+		},
+		{
+			name:         "primary berhasil, secondary tidak dipanggil",
+			steps:        []*fakeSummarizer{{name: "python", model: "gemini"}, {name: "openai"}},
+			wantProvider: "python", wantModel: "gemini",
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:         "jatuh sampai provider terakhir",
+			steps:        []*fakeSummarizer{{name: "python", err: down}, {name: "openai", err: rejected}, {name: "extractive"}},
+			wantProvider: "extractive",
+			wantCalls:    []int{1, 1, 1},
+			wantFallback: []string{"python->openai", "openai->extractive"},
+		},
+		{
+			name:         "semua gagal, kode dari provider terakhir",
+			steps:        []*fakeSummarizer{{name: "python", err: down}, {name: "openai", err: rejected}},
+			wantCode:     ErrCodeRejected,
+			wantCalls:    []int{1, 1},
+			wantFallback: []string{"python->openai"},
+		},
+		{
+			name:      "PDF tanpa teks tidak dicoba ke provider lain",
+			steps:     []*fakeSummarizer{{name: "python", err: ErrNoText}, {name: "openai"}},
+			wantCode:  ErrCodeNoText,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:         "timeout per provider pindah ke berikutnya",
+			steps:        []*fakeSummarizer{{name: "python", delay: time.Minute}, {name: "openai", model: "llama3"}},
+			timeouts:     []time.Duration{20 * time.Millisecond, 0},
+			wantProvider: "openai", wantModel: "llama3",
+			wantCalls:    []int{1, 1},
+			wantFallback: []string{"python->openai"},
+		},
+		{
+			name:      "satu provider, error apa adanya",
+			steps:     []*fakeSummarizer{{name: "python", err: down}},
+			wantCode:  ErrCodeUnavailable,
+			wantCalls: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := make([]FallbackStep, len(tt.steps))
+			for i, s := range tt.steps {
+				steps[i] = FallbackStep{Summarizer: s}
+				if i < len(tt.timeouts) {
+					steps[i].Timeout = tt.timeouts[i]
+				}
+			}
+			var fallbacks []string
+			result, err := NewFallbackSummarizer(steps...).Summarize(context.Background(), SummaryRequest{
+				Fallback: func(from, to string, err error) { fallbacks = append(fallbacks, from+"->"+to) },
+			})
+
+			if tt.wantProvider != "" {
+				if err != nil {
+					t.Fatalf("Summarize: %v", err)
+				}
+				if result.Provider != tt.wantProvider || result.Model != tt.wantModel {
+					t.Fatalf("provider/model = %s/%s, want %s/%s", result.Provider, result.Model, tt.wantProvider, tt.wantModel)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("Summarize succeeded with %s, want error", result.Provider)
+				}
+				if got := ErrorCode(err); got != tt.wantCode {
+					t.Fatalf("ErrorCode = %s, want %s (err %v)", got, tt.wantCode, err)
+				}
+				var fe *FallbackError
+				if errors.As(err, &fe) != (len(tt.steps) > 1) {
+					t.Fatalf("err = %T, FallbackError only expected with more than one provider", err)
+				}
+			}
+			for i, s := range tt.steps {
+				if s.calls != tt.wantCalls[i] {
+					t.Errorf("%s called %d times, want %d", s.name, s.calls, tt.wantCalls[i])
+				}
+			}
+			if len(fallbacks) != len(tt.wantFallback) {
+				t.Fatalf("fallbacks = %v, want %v", fallbacks, tt.wantFallback)
+			}
+			for i := range fallbacks {
+				if fallbacks[i] != tt.wantFallback[i] {
+					t.Fatalf("fallbacks = %v, want %v", fallbacks, tt.wantFallback)
+				}
+			}
+		})
+	}
+}
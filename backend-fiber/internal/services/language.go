@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopword pendek yang paling sering muncul, cukup buat bedain Indonesia vs Inggris
+var (
+	indonesianStopwords = map[string]bool{
+		"yang": true, "dan": true, "di": true, "ke": true, "dari": true, "ini": true, "itu": true,
+		"dengan": true, "untuk": true, "pada": true, "adalah": true, "dalam": true, "tidak": true,
+		"akan": true, "juga": true, "atau": true, "oleh": true, "sebagai": true, "karena": true, "kami": true,
+	}
+	englishStopwords = map[string]bool{
+		"the": true, "and": true, "of": true, "to": true, "in": true, "is": true, "that": true,
+		"for": true, "with": true, "on": true, "as": true, "are": true, "this": true, "by": true,
+		"be": true, "was": true, "it": true, "from": true, "or": true, "which": true,
+	}
+)
+
+// tokenize memecah teks jadi kata huruf kecil (angka & tanda baca dibuang).
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// DetectLanguage menebak bahasa teks: "id" atau "en" (default "en", sama seperti Python service).
+func DetectLanguage(text string) string {
+	text = truncateRunes(text, 5000)
+	var id, en int
+	for _, w := range tokenize(text) {
+		if indonesianStopwords[w] {
+			id++
+		}
+		if englishStopwords[w] {
+			en++
+		}
+	}
+	if id > en {
+		return "id"
+	}
+	return "en"
+}
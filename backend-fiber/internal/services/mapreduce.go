@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/pdfinfo"
+)
+
+const (
+	defaultChunkChars   = 4000
+	defaultOverlapChars = 400
+	mapStyle            = "standard" //style ringkasan parsial per chunk, style asli dipakai di tahap reduce
+)
+
+// MapReduceSummarizer membungkus summarizer lain:
+// teks diekstrak di Go, dibagi per chunk (SplitText), tiap chunk diringkas (map),
+// lalu ringkasan-ringkasan itu diringkas lagi jadi satu (reduce).
+// Kalau summarizer di dalamnya tidak bisa meringkas teks, atau teks gagal diekstrak, fallback ke ringkas file biasa.
+type MapReduceSummarizer struct {
+	Base         Summarizer
+	ChunkChars   int
+	OverlapChars int
+}
+
+func NewMapReduceSummarizer(base Summarizer, chunkChars, overlapChars int) *MapReduceSummarizer {
+	if chunkChars <= 0 {
+		chunkChars = defaultChunkChars
+	}
+	// chunk lebih besar dari batas prompt akan terpotong diam-diam di buildSummarizePrompt
+	if chunkChars > maxPromptChars {
+		chunkChars = maxPromptChars
+	}
+	// overlap harus lebih kecil dari chunk, kalau tidak valid pakai 10% ukuran chunk
+	if overlapChars < 0 || overlapChars >= chunkChars {
+		overlapChars = chunkChars / 10
+	}
+	return &MapReduceSummarizer{Base: base, ChunkChars: chunkChars, OverlapChars: overlapChars}
+}
+
+func (m *MapReduceSummarizer) Name() string {
+	return m.Base.Name()
+}
+
+func (m *MapReduceSummarizer) ModelName() string {
+	if mn, ok := m.Base.(ModelNamer); ok {
+		return mn.ModelName()
+	}
+	return ""
+}
+
+func (m *MapReduceSummarizer) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	ts, ok := m.Base.(TextSummarizer)
+	if !ok {
+		return m.Base.Summarize(ctx, req)
+	}
+
+	text, err := pdfinfo.ExtractText(req.FilePath)
+	if err != nil || strings.TrimSpace(text) == "" {
+		log.Printf("Map-reduce: text extraction failed for %s (%v), summarizing file directly", req.FilePath, err)
+		return m.Base.Summarize(ctx, req)
+	}
+
+	start := time.Now()
+	language := DetectLanguage(text)
+	chunks := SplitText(text, m.ChunkChars, m.OverlapChars)
+	totalChars := utf8.RuneCountInString(text)
+
+	// dokumen pendek: cukup satu panggilan
+	if len(chunks) == 1 {
+		result, err := ts.SummarizeText(ctx, chunks[0].Text, req.Style, language)
+		if err != nil {
+			return nil, err
+		}
+		result.ChunksCount = 1
+		result.CharsCovered = totalChars
+		result.TotalChars = totalChars
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+
+	// map: ringkas tiap chunk; chunk yang gagal dilewati, coverage-nya tidak dihitung
+	var partials []models.ChunkSummary
+	var lastErr error
+	for i, chunk := range chunks {
+		result, err := ts.SummarizeText(ctx, chunk.Text, mapStyle, language)
+		if err != nil {
+			log.Printf("Map-reduce: chunk %d/%d failed: %v", i+1, len(chunks), err)
+			if errors.Is(err, ErrCircuitOpen) || ctx.Err() != nil {
+				return nil, err //service mati / waktu habis, chunk berikutnya pasti gagal juga
+			}
+			lastErr = err
+		} else {
+			partials = append(partials, models.ChunkSummary{Index: chunk.Index, Start: chunk.Start, End: chunk.End, Summary: result.Summary})
+		}
+		if req.Progress != nil {
+			req.Progress(i+1, len(chunks))
+		}
+	}
+	if len(partials) == 0 {
+		return nil, fmt.Errorf("all %d chunks failed: %w", len(chunks), lastErr)
+	}
+
+	// reduce: gabungan ringkasan yang masih kepanjangan diringkas lagi per kelompok
+	texts := make([]string, len(partials))
+	for i, p := range partials {
+		texts[i] = p.Summary
+	}
+	for len(texts) > 1 && utf8.RuneCountInString(strings.Join(texts, "\n\n")) > m.ChunkChars {
+		next, err := m.reduceGroups(ctx, ts, texts, language)
+		if err != nil {
+			return nil, err
+		}
+		if len(next) >= len(texts) {
+			break //tidak bisa dipadatkan lagi, biarkan prompt yang memotong
+		}
+		texts = next
+	}
+
+	result, err := ts.SummarizeText(ctx, strings.Join(texts, "\n\n"), req.Style, language)
+	if err != nil {
+		return nil, err
+	}
+	result.Chunks = partials
+	result.ChunksCount = len(partials)
+	result.CharsCovered = coveredChars(partials)
+	result.TotalChars = totalChars
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// reduceGroups mengelompokkan ringkasan sampai maksimal ChunkChars per kelompok lalu meringkas tiap kelompok.
+func (m *MapReduceSummarizer) reduceGroups(ctx context.Context, ts TextSummarizer, texts []string, language string) ([]string, error) {
+	var groups [][]string
+	var current []string
+	currentLen := 0
+	for _, t := range texts {
+		n := utf8.RuneCountInString(t)
+		if len(current) > 0 && currentLen+n > m.ChunkChars {
+			groups = append(groups, current)
+			current, currentLen = nil, 0
+		}
+		current = append(current, t)
+		currentLen += n
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	out := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if len(g) == 1 {
+			out = append(out, g[0])
+			continue
+		}
+		result, err := ts.SummarizeText(ctx, strings.Join(g, "\n\n"), mapStyle, language)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, result.Summary)
+	}
+	return out, nil
+}
+
+// coveredChars menghitung jumlah karakter unik yang tercakup chunk (overlap tidak dihitung dua kali).
+func coveredChars(partials []models.ChunkSummary) int {
+	total, reached := 0, 0
+	for _, p := range partials { //partials urut berdasarkan Start
+		start := p.Start
+		if start < reached {
+			start = reached
+		}
+		if p.End > start {
+			total += p.End - start
+			reached = p.End
+		}
+	}
+	return total
+}
+
+//map-reduce summarization: dokumen panjang diringkas per bagian dulu baru digabung
@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestNewMapReduceSummarizerLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunk       int
+		overlap     int
+		wantChunk   int
+		wantOverlap int
+	}{
+		{"default", 0, 400, defaultChunkChars, 400},
+		{"valid", 2000, 100, 2000, 100},
+		{"chunk di atas batas prompt", 8000, 400, maxPromptChars, 400},
+		{"overlap sama dengan chunk", 1000, 1000, 1000, 100},
+		{"overlap lebih besar dari chunk", 300, 400, 300, 30},
+		{"overlap negatif", 2000, -1, 2000, 200},
+		{"overlap dicek setelah chunk dipotong", 9000, 6000, maxPromptChars, maxPromptChars / 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMapReduceSummarizer(nil, tt.chunk, tt.overlap)
+			if m.ChunkChars != tt.wantChunk || m.OverlapChars != tt.wantOverlap {
+				t.Errorf("got chunk=%d overlap=%d, want %d/%d", m.ChunkChars, m.OverlapChars, tt.wantChunk, tt.wantOverlap)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/pdfinfo"
+)
+
+// OpenAIClient memanggil endpoint /chat/completions yang kompatibel OpenAI
+// (OpenAI sendiri, llama.cpp server, Ollama, vLLM, dll). Teks PDF diekstrak di Go.
+type OpenAIClient struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Model:   model,
+	}
+}
+
+func (c *OpenAIClient) Name() string {
+	return "openai"
+}
+
+func (c *OpenAIClient) ModelName() string {
+	return c.Model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (c *OpenAIClient) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
+	text, err := pdfinfo.ExtractText(req.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, ErrNoText
+	}
+	return c.SummarizeText(ctx, text, req.Style, DetectLanguage(text))
+}
+
+func (c *OpenAIClient) SummarizeText(ctx context.Context, text, style, language string) (*SummaryResult, error) {
+	start := time.Now()
+	if language == "" {
+		language = DetectLanguage(text)
+	}
+
+	body, err := json.Marshal(chatCompletionRequest{
+		Model: c.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: "You are a helpful assistant that summarizes documents."},
+			{Role: "user", Content: buildSummarizePrompt(text, language, style)},
+		},
+		Temperature: 0.3, //sama dengan config gemini di python
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	resp, err := (&http.Client{}).Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(b, &completion); err != nil {
+		return nil, fmt.Errorf("invalid chat completion response (status %d)", resp.StatusCode)
+	}
+	if completion.Error != nil {
+		return nil, fmt.Errorf("chat completion error: %s", completion.Error.Message)
+	}
+	if resp.StatusCode >= 300 || len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("chat completion failed (status %d)", resp.StatusCode)
+	}
+
+	return &SummaryResult{
+		Summary:    strings.TrimSpace(completion.Choices[0].Message.Content),
+		Language:   language,
+		Style:      style,
+		Provider:   c.Name(),
+		Model:      c.Model,
+		DurationMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+//summarizer lewat server openai-compatible, bisa pakai model lokal (ollama/llama.cpp)
@@ -0,0 +1,63 @@
+package services
+
+import "fmt"
+
+// PromptVersion ikut jadi key cache ringkasan (summary_cache).
+// Naikkan setiap kali prompt / instruksi style / cara chunking berubah supaya hasil lama tidak dipakai lagi.
+const PromptVersion = "v2"
+
+// maxPromptChars sama dengan batas di Python service (text[:5000]) biar hasilnya sebanding
+const maxPromptChars = 5000
+
+// instruksi per style, disalin dari get_summarize_prompt di pdf-ai-summarizer/main.py
+var styleInstructions = map[string]map[string]string{
+	"standard": {
+		"id": "Ringkasan harus jelas, singkat, dan terstruktur dalam paragraf-paragraf.\nSorot ide-ide kunci dan jelaskan poin-poin penting.",
+		"en": "The summary should be clear, concise, and well-structured in paragraphs.\nHighlight key ideas and explain important points.",
+	},
+	"executive": {
+		"id": "Buatkan ringkasan eksekutif yang fokus pada:\n- Apa masalahnya?\n- Solusi/rekomendasi utama\n- Impact atau hasil yang diharapkan\n\nGunakan bahasa yang ringkas dan actionable, cocok untuk decision makers.",
+		"en": "Create an executive summary focusing on:\n- What is the main issue?\n- Key solutions/recommendations\n- Expected impact or results\n\nUse concise, actionable language suitable for decision makers.",
+	},
+	"bullets": {
+		"id": "Format ringkasan sebagai poin-poin (bullet points) yang mudah dicerna:\n- Gunakan format bullet (•) atau dash (-) untuk setiap poin utama\n- Setiap poin maksimal 1-2 baris\n- WAJIB gunakan format bullet points, JANGAN paragraf",
+		"en": "Format the summary as bullet points that are easy to digest:\n- Use bullet (•) or dash (-) format for each main point\n- Each point should be 1-2 lines maximum\n- MUST use bullet point format, NOT paragraphs",
+	},
+	"detailed": {
+		"id": "Buatkan ringkasan detail yang mencakup:\n- Latar belakang/konteks\n- Poin-poin utama dengan penjelasan mendalam\n- Nuansa dan detail penting\n- Kesimpulan dan implikasi",
+		"en": "Create a detailed summary that includes:\n- Background/context\n- Main points with deep explanation\n- Important nuances and details\n- Conclusions and implications",
+	},
+}
+
+func buildSummarizePrompt(text, language, style string) string {
+	lang := "en"
+	if language == "id" {
+		lang = "id"
+	}
+	instructions, ok := styleInstructions[style]
+	if !ok {
+		instructions = styleInstructions["standard"]
+	}
+	text = truncateRunes(text, maxPromptChars)
+
+	if lang == "id" {
+		return fmt.Sprintf("Buatkan ringkasan dari dokumen berikut dalam bahasa Indonesia.\n\nInstruksi format:\n%s\n\n"+
+			"PENTING: Gunakan format Markdown bold (**kata**) untuk menyorot kata kunci, nama penting, atau poin utama.\n\nDokumen:\n%s",
+			instructions[lang], text)
+	}
+	return fmt.Sprintf("Please summarize the following document in English.\n\nFormat instructions:\n%s\n\n"+
+		"IMPORTANT: Use Markdown bold (**word**) to highlight key terms, important names, or main points.\n\nDocument:\n%s",
+		instructions[lang], text)
+}
+
+// truncateRunes memotong teks per karakter (bukan per byte) supaya huruf non-ASCII tidak terpotong setengah.
+func truncateRunes(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n])
+}
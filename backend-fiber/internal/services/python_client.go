@@ -2,78 +2,292 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"pdf-backend-fiber/internal/config"
 )
 
+// maxResponseBytes = batas body respons python yang dibaca (ringkasan + error message).
+const maxResponseBytes = 10 << 20
+
+// jenis kegagalan panggilan ke service AI
+const (
+	ErrKindClient    = "client_error"    //4xx: request-nya salah, diulang pun percuma (kecuali 408 / 429)
+	ErrKindServer    = "server_error"    //5xx: service error, boleh di-retry
+	ErrKindTransport = "transport_error" //koneksi ditolak / putus, boleh di-retry
+	ErrKindTimeout   = "timeout"         //satu percobaan melewati PYTHON_TIMEOUT, boleh di-retry
+)
+
+// ServiceError = panggilan ke service AI gagal, Kind menentukan boleh di-retry atau tidak.
+type ServiceError struct {
+	Service    string
+	Kind       string
+	StatusCode int    //0 untuk transport / timeout
+	Body       string //potongan body respons error
+	Err        error
+}
+
+func (e *ServiceError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s service returned status %d: %s", e.Service, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("%s service %s: %v", e.Service, e.Kind, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ServiceError) Retryable() bool {
+	if e.Kind == ErrKindClient {
+		return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
 type PythonClient struct {
 	BaseURL string
+	HTTP    *http.Client
+
+	Timeout      time.Duration //per percobaan
+	TotalTimeout time.Duration //semua percobaan + jeda retry
+	MaxRetries   int
+	Backoff      time.Duration
+	Breaker      *CircuitBreaker
 }
 
-func NewPythonClient(baseURL string) *PythonClient {
+func NewPythonClient(cfg config.Config) *PythonClient {
 	return &PythonClient{
-		BaseURL: baseURL,
+		BaseURL:      cfg.PythonAPI,
+		HTTP:         &http.Client{}, //timeout lewat context per percobaan
+		Timeout:      cfg.PythonTimeout,
+		TotalTimeout: cfg.PythonTotalTimeout,
+		MaxRetries:   cfg.PythonMaxRetries,
+		Backoff:      cfg.PythonRetryBackoff,
+		Breaker:      NewCircuitBreaker("python", cfg.BreakerThreshold, cfg.BreakerCooldown),
 	}
 }
 
-func (c *PythonClient) Summarize(filePath string, style string) (string, int64, error) {
+func (c *PythonClient) Name() string {
+	return "python"
+}
+
+func (c *PythonClient) Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error) {
 	start := time.Now() //untuk menghitung waktu prosesnya
+	body, contentType, err := multipartFile(req.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.post(ctx, c.summarizeURL(req.Style), contentType, body)
+	if err != nil {
+		return nil, err
+	}
 
-	requestURL := c.BaseURL //untuk mempersiapkan request
-	if style != "" {        //untuk menambahkan query style ke request
-		u, err := url.Parse(c.BaseURL)
-		if err == nil {
-			q := u.Query()
-			q.Set("style", style)
-			u.RawQuery = q.Encode()
-			requestURL = u.String()
-		}
+	result := &SummaryResult{Style: req.Style, Language: "unknown", Provider: c.Name(), DurationMs: time.Since(start).Milliseconds()}
+	var response SummaryResult
+	if err := json.Unmarshal(b, &response); err == nil {
+		result.Summary = response.Summary
+		result.Language = response.Language
+		result.Model = response.Provider //gemini / mock
+	} else {
+		result.Summary = string(b) //bukan json, simpan apa adanya
 	}
+	return result, nil
+}
 
-	file, err := os.Open(filePath) //untuk membuka file, ambil file dari disk utk dikirim ke python
+// summarizeURL = BaseURL (/summarize) + ?style=
+func (c *PythonClient) summarizeURL(style string) string {
+	if style == "" {
+		return c.BaseURL
+	}
+	u, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return "", 0, err
+		return c.BaseURL
 	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}             //untuk menyimpan body request
-	writer := multipart.NewWriter(body) //untuk membuat writer
+	q := u.Query()
+	q.Set("style", style)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
 
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath)) //untuk membuat file form, aplod
+// textURL = endpoint /summarize-text di service yang sama dengan BaseURL (/summarize)
+func (c *PythonClient) textURL() string {
+	u, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return "", 0, err
+		return strings.TrimSuffix(c.BaseURL, "/summarize") + "/summarize-text"
 	}
+	u.Path = strings.TrimSuffix(u.Path, "/summarize") + "/summarize-text"
+	u.RawQuery = ""
+	return u.String()
+}
 
-	_, err = io.Copy(part, file) //untuk menyalin file ke part
+// SummarizeText mengirim teks yang sudah diekstrak di Go ke /summarize-text (dipakai map-reduce).
+func (c *PythonClient) SummarizeText(ctx context.Context, text, style, language string) (*SummaryResult, error) {
+	start := time.Now()
+
+	body, err := json.Marshal(map[string]string{"text": text, "style": style, "language": language})
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.post(ctx, c.textURL(), "application/json", body)
 	if err != nil {
-		return "", 0, err
+		return nil, err
+	}
+
+	var result SummaryResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("invalid python response: %w", err)
+	}
+	if result.Language == "" {
+		result.Language = language
 	}
+	result.Model = result.Provider //gemini / mock
+	result.Provider = c.Name()
+	result.DurationMs = time.Since(start).Milliseconds()
+	return &result, nil
+}
+
+// post mengirim request dengan retry (backoff eksponensial + jitter) untuk error yang boleh diulang,
+// dibatasi TotalTimeout dan circuit breaker. Body disimpan sebagai []byte supaya bisa dikirim ulang.
+func (c *PythonClient) post(ctx context.Context, requestURL, contentType string, body []byte) ([]byte, error) {
+	if c.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.TotalTimeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := c.Breaker.Allow(); err != nil {
+			if lastErr != nil {
+				return nil, lastErr //breaker baru saja open karena percobaan sebelumnya, laporkan penyebab aslinya
+			}
+			return nil, err
+		}
+		b, err := c.attempt(ctx, requestURL, contentType, body)
+		if err == nil {
+			c.Breaker.Success()
+			return b, nil
+		}
+
+		var se *ServiceError
+		if !errors.As(err, &se) {
+			c.Breaker.Release() //dibatalkan pemanggil, bukan salah service
+			return nil, err
+		}
+		if !se.Retryable() {
+			c.Breaker.Success() //4xx: service-nya hidup
+			return nil, err
+		}
+		c.Breaker.Failure(err)
+		lastErr = err
+		if attempt >= c.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
 
-	_ = writer.Close()
+		wait := c.backoff(attempt)
+		log.Printf("Python service attempt %d/%d failed: %v, retrying in %s", attempt+1, c.MaxRetries+1, err, wait)
+		select {
+		case <-ctx.Done():
+			return nil, err //total timeout habis waktu menunggu, yang dilaporkan error terakhir
+		case <-time.After(wait):
+		}
+	}
+}
 
-	req, err := http.NewRequest(http.MethodPost, requestURL, body) //untuk membuat request ke piton
+// attempt = satu percobaan dengan batas waktu Timeout.
+func (c *PythonClient) attempt(ctx context.Context, requestURL, contentType string, body []byte) ([]byte, error) {
+	attemptCtx := ctx
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, requestURL, bytes.NewReader(body))
 	if err != nil {
-		return "", 0, err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType()) //untuk menambahkan header
+	req.Header.Set("Content-Type", contentType)
 
-	resp, err := (&http.Client{}).Do(req) //kirim request ke python
+	resp, err := c.HTTP.Do(req) //kirim request ke python
 	if err != nil {
-		return "", 0, err
+		if ctx.Err() == context.Canceled {
+			return nil, ctx.Err()
+		}
+		if attemptCtx.Err() == context.DeadlineExceeded {
+			return nil, &ServiceError{Service: c.Name(), Kind: ErrKindTimeout, Err: context.DeadlineExceeded}
+		}
+		return nil, &ServiceError{Service: c.Name(), Kind: ErrKindTransport, Err: err}
 	}
 	defer resp.Body.Close()
 
-	b, err := io.ReadAll(resp.Body) //untuk membaca response dari python
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
 	if err != nil {
-		return "", 0, err
+		if attemptCtx.Err() == context.DeadlineExceeded {
+			return nil, &ServiceError{Service: c.Name(), Kind: ErrKindTimeout, Err: context.DeadlineExceeded}
+		}
+		return nil, &ServiceError{Service: c.Name(), Kind: ErrKindTransport, Err: err}
+	}
+
+	// body error (misal traceback FastAPI 500) jangan sampai tersimpan sebagai ringkasan
+	if resp.StatusCode >= 300 {
+		kind := ErrKindClient
+		if resp.StatusCode >= 500 {
+			kind = ErrKindServer
+		}
+		msg := strings.TrimSpace(string(b))
+		if len(msg) > 500 {
+			msg = msg[:500] + "..."
+		}
+		return nil, &ServiceError{Service: c.Name(), Kind: kind, StatusCode: resp.StatusCode, Body: msg}
 	}
+	return b, nil
+}
+
+// backoff = Backoff * 2^attempt, separuhnya acak (jitter) supaya worker yang gagal bareng tidak retry bareng.
+func (c *PythonClient) backoff(attempt int) time.Duration {
+	d := c.Backoff << attempt
+	if d <= 0 || d > time.Minute {
+		d = time.Minute
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
 
-	return string(b), time.Since(start).Milliseconds(), nil //kembalikan response, waktu proses, dan error
+// multipartFile membaca file PDF jadi body multipart (field "file").
+func multipartFile(filePath string) ([]byte, string, error) {
+	file, err := os.Open(filePath) //ambil file dari disk utk dikirim ke python
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
 }
 
-//buat komunikasi ke python ya intinya
+//buat komunikasi ke python ya intinya: timeout, retry, circuit breaker ada di post()
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/models"
+)
+
+// ErrNoText = PDF tidak punya teks yang bisa diekstrak (hasil scan tanpa OCR).
+var ErrNoText = errors.New("PDF tidak mengandung teks")
+
+// Kode error ringkasan gagal (kolom summaries.error_code), supaya client bisa bedakan
+// "coba lagi nanti" (timeout / provider_error / provider_unavailable) dari "percuma diulang" (no_text / provider_rejected).
+const (
+	ErrCodeTimeout       = "timeout"
+	ErrCodeNoText        = "no_text"
+	ErrCodeProviderError = "provider_error"
+	ErrCodeUnavailable   = "provider_unavailable" //service tidak bisa dihubungi / circuit breaker open
+	ErrCodeRejected      = "provider_rejected"    //service menolak request (4xx)
+)
+
+// ErrorCode mengelompokkan error dari Summarize jadi kode di atas.
+func ErrorCode(err error) string {
+	var netErr net.Error
+	var svcErr *ServiceError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, ErrNoText):
+		return ErrCodeNoText
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrCodeUnavailable
+	case errors.As(err, &svcErr):
+		switch svcErr.Kind {
+		case ErrKindTimeout:
+			return ErrCodeTimeout
+		case ErrKindTransport:
+			return ErrCodeUnavailable
+		case ErrKindClient:
+			return ErrCodeRejected
+		}
+		return ErrCodeProviderError
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrCodeTimeout
+	case errors.As(err, &opErr): //openai: server tidak bisa dihubungi
+		return ErrCodeUnavailable
+	}
+	return ErrCodeProviderError
+}
+
+// Summarizer adalah backend yang bisa meringkas satu file PDF.
+// Implementasinya: PythonClient (FastAPI), OpenAIClient (server OpenAI-compatible), ExtractiveSummarizer (Go murni).
+type Summarizer interface {
+	Name() string
+	Summarize(ctx context.Context, req SummaryRequest) (*SummaryResult, error)
+}
+
+// TextSummarizer = summarizer yang juga bisa meringkas teks mentah (bukan file).
+// Ini yang dipakai map-reduce untuk meringkas per chunk lalu meringkas gabungan ringkasannya.
+type TextSummarizer interface {
+	Summarizer
+	SummarizeText(ctx context.Context, text, style, language string) (*SummaryResult, error)
+}
+
+type SummaryRequest struct {
+	FilePath string
+	Style    string
+
+	// ContentSHA256 (opsional) = hash isi file dari pdf_files, kalau kosong dihitung dari file. Dipakai key cache.
+	ContentSHA256 string
+	NoCache       bool //true = selalu generate ulang, hasil baru tetap disimpan ke cache
+
+	// Progress (opsional) dipanggil setiap satu chunk selesai diringkas
+	Progress func(done, total int)
+	// Fallback (opsional) dipanggil waktu provider gagal dan chain pindah ke provider berikutnya
+	Fallback func(from, to string, err error)
+}
+
+type SummaryResult struct {
+	Summary    string `json:"summary"`
+	Language   string `json:"detected_language"`
+	Style      string `json:"style"`
+	Provider   string `json:"provider"`
+	Model      string `json:"-"` //model yang dipakai backend (misal nama model openai, atau gemini/mock dari python)
+	DurationMs int64  `json:"-"`
+	CacheHit   bool   `json:"-"`
+
+	// diisi oleh map-reduce
+	Chunks       []models.ChunkSummary `json:"-"`
+	ChunksCount  int                   `json:"-"` //jumlah chunk yang berhasil diringkas
+	CharsCovered int                   `json:"-"` //jumlah karakter dokumen yang ikut diringkas
+	TotalChars   int                   `json:"-"` //panjang teks hasil ekstraksi
+}
+
+// ModelNamer = summarizer yang tahu model apa yang dipakai sebelum dipanggil (ikut jadi key cache).
+type ModelNamer interface {
+	ModelName() string
+}
+
+// Registry menyimpan semua summarizer yang tersedia, dipilih per request atau pakai chain default dari config.
+// Setiap summarizer dibungkus MapReduceSummarizer supaya dokumen panjang tidak terpotong,
+// lalu CachedSummarizer (kalau db tersedia) supaya file + style + model yang sama tidak diringkas ulang.
+type Registry struct {
+	Default string
+	Chain   []string //urutan fallback kalau provider tidak dipilih, Chain[0] = Default
+	items   map[string]Summarizer
+
+	timeouts map[string]time.Duration //batas waktu satu dokumen per provider (semua chunk + retry), 0 = tanpa batas
+
+	cache        *SummaryCache
+	chunkChars   int
+	overlapChars int
+
+	breakers []*CircuitBreaker //circuit breaker tiap service AI remote, dilaporkan di /health
+}
+
+func NewRegistry(db *sql.DB, cfg config.Config) *Registry {
+	r := &Registry{
+		Default:      strings.ToLower(strings.TrimSpace(cfg.Summarizer)),
+		items:        map[string]Summarizer{},
+		timeouts:     map[string]time.Duration{},
+		chunkChars:   cfg.ChunkChars,
+		overlapChars: cfg.ChunkOverlapChars,
+	}
+	if db != nil {
+		r.cache = NewSummaryCache(db)
+	}
+	python := NewPythonClient(cfg)
+	r.breakers = append(r.breakers, python.Breaker)
+	r.Register(python)
+	r.timeouts["python"] = cfg.PythonSummaryTimeout //PYTHON_TOTAL_TIMEOUT berlaku per panggilan (per chunk), di post()
+	r.Register(NewOpenAIClient(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.OpenAIModel))
+	r.timeouts["openai"] = cfg.OpenAITimeout
+	r.Register(NewExtractiveSummarizer())
+
+	if _, ok := r.items[r.Default]; !ok {
+		r.Default = "python"
+	}
+
+	// chain: default dulu, lalu SUMMARIZER_CHAIN (nama yang tidak dikenal / dobel dilewati)
+	r.Chain = []string{r.Default}
+	for _, name := range cfg.SummarizerChain {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := r.items[name]; !ok {
+			if name != "" {
+				log.Printf("SUMMARIZER_CHAIN: unknown summarizer %q skipped", name)
+			}
+			continue
+		}
+		if !containsString(r.Chain, name) {
+			r.Chain = append(r.Chain, name)
+		}
+	}
+	return r
+}
+
+func (r *Registry) Register(s Summarizer) {
+	var wrapped Summarizer = NewMapReduceSummarizer(s, r.chunkChars, r.overlapChars)
+	if r.cache != nil {
+		wrapped = NewCachedSummarizer(wrapped, r.cache)
+	}
+	r.items[s.Name()] = wrapped
+}
+
+// Get mengembalikan summarizer sesuai nama (tanpa fallback, yang diminta ya yang dipakai); nama kosong = chain default.
+// Keduanya kena timeout per provider.
+func (r *Registry) Get(name string) (Summarizer, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	names := r.Chain
+	if name != "" {
+		if _, ok := r.items[name]; !ok {
+			return nil, fmt.Errorf("unknown summarizer %q (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		names = []string{name}
+	}
+	steps := make([]FallbackStep, len(names))
+	for i, n := range names {
+		steps[i] = FallbackStep{Summarizer: r.items[n], Timeout: r.timeouts[n]}
+	}
+	return NewFallbackSummarizer(steps...), nil
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.items))
+	for name := range r.items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Breakers = status circuit breaker semua service AI remote.
+func (r *Registry) Breakers() []BreakerStatus {
+	out := make([]BreakerStatus, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		out = append(out, b.Status())
+	}
+	return out
+}
+
+//kontrak summarizer, biar handler ga terikat ke satu backend AI
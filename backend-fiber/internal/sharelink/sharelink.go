@@ -0,0 +1,111 @@
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scope = apa saja yang boleh dibaca lewat share link.
+const (
+	ScopeSummary = "summary" //ringkasan terbaru saja
+	ScopeHistory = "history" //semua ringkasan
+	ScopeFile    = "file"    //file PDF asli
+)
+
+var (
+	ErrMalformed = errors.New("share link malformed")
+	ErrSignature = errors.New("share link signature invalid")
+	ErrExpired   = errors.New("share link expired")
+)
+
+func ValidScope(scope string) bool {
+	return scope == ScopeSummary || scope == ScopeHistory || scope == ScopeFile
+}
+
+// Signer membuat dan memeriksa token share link: "<link_id>.<expires_unix>.<signature>".
+// Signature = HMAC-SHA256 atas link_id, pdf_id, scope dan expires, jadi pdf_id / scope dari database
+// ikut dicek dan token tidak bisa dipakai untuk PDF lain.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner memakai SHARE_SECRET. Kalau kosong dibuat kunci random (cuma cocok untuk development).
+func NewSigner(secret string) *Signer {
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+		log.Println("Warning: SHARE_SECRET kosong, share link tidak berlaku lagi setelah server restart")
+		return &Signer{secret: key}
+	}
+	return &Signer{secret: []byte(secret)}
+}
+
+// NewLinkID membuat id acak untuk baris share_links.
+func NewLinkID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Signer) mac(linkID string, pdfID int, scope string, expires int64) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(m, "%s|%d|%s|%d", linkID, pdfID, scope, expires)
+	return m.Sum(nil)
+}
+
+// Sign menghasilkan token untuk URL /share/<token>.
+func (s *Signer) Sign(linkID string, pdfID int, scope string, expiresAt time.Time) string {
+	exp := expiresAt.Unix()
+	sig := base64.RawURLEncoding.EncodeToString(s.mac(linkID, pdfID, scope, exp))
+	return fmt.Sprintf("%s.%d.%s", linkID, exp, sig)
+}
+
+// Token = isi token yang sudah dipecah, belum diverifikasi.
+type Token struct {
+	LinkID    string
+	ExpiresAt time.Time
+	sig       []byte
+}
+
+// Parse memecah token. Verifikasi dilakukan setelah pdf_id & scope diambil dari database.
+func Parse(token string) (Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return Token{}, ErrMalformed
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Token{}, ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Token{}, ErrMalformed
+	}
+	return Token{LinkID: parts[0], ExpiresAt: time.Unix(exp, 0), sig: sig}, nil
+}
+
+// Verify mengecek signature dan masa berlaku token.
+func (s *Signer) Verify(t Token, pdfID int, scope string) error {
+	if !hmac.Equal(t.sig, s.mac(t.LinkID, pdfID, scope, t.ExpiresAt.Unix())) {
+		return ErrSignature
+	}
+	if time.Now().After(t.ExpiresAt) {
+		return ErrExpired
+	}
+	return nil
+}
+
+//link share bertanda tangan HMAC, orang luar bisa baca ringkasan tanpa akun sampai link kedaluwarsa / dicabut
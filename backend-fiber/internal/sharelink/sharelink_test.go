@@ -0,0 +1,57 @@
+package sharelink
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	s := NewSigner("test-secret")
+	future := time.Now().Add(time.Hour)
+	valid := s.Sign("abc123", 7, ScopeSummary, future)
+
+	// ganti satu bagian token, sisanya tetap
+	replacePart := func(token string, i int, v string) string {
+		parts := strings.Split(token, ".")
+		parts[i] = v
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name     string
+		signer   *Signer
+		token    string
+		pdfID    int
+		scope    string
+		parseErr error
+		want     error
+	}{
+		{"valid", s, valid, 7, ScopeSummary, nil, nil},
+		{"expired", s, s.Sign("abc123", 7, ScopeSummary, time.Now().Add(-time.Minute)), 7, ScopeSummary, nil, ErrExpired},
+		{"pdf lain", s, valid, 8, ScopeSummary, nil, ErrSignature},
+		{"scope lain", s, valid, 7, ScopeFile, nil, ErrSignature},
+		{"secret lain", NewSigner("other-secret"), valid, 7, ScopeSummary, nil, ErrSignature},
+		{"link id diganti", s, replacePart(valid, 0, "xyz789"), 7, ScopeSummary, nil, ErrSignature},
+		{"expiry diperpanjang", s, replacePart(valid, 1, "9999999999"), 7, ScopeSummary, nil, ErrSignature},
+		{"signature diganti", s, replacePart(valid, 2, "AAAA"), 7, ScopeSummary, nil, ErrSignature},
+		{"signature bukan base64", s, replacePart(valid, 2, "!!"), 7, ScopeSummary, ErrMalformed, nil},
+		{"expiry bukan angka", s, replacePart(valid, 1, "besok"), 7, ScopeSummary, ErrMalformed, nil},
+		{"bagian kurang", s, "abc123.123", 7, ScopeSummary, ErrMalformed, nil},
+		{"link id kosong", s, replacePart(valid, 0, ""), 7, ScopeSummary, ErrMalformed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := Parse(tt.token)
+			if err != tt.parseErr {
+				t.Fatalf("Parse(%q) err = %v, want %v", tt.token, err, tt.parseErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := tt.signer.Verify(tok, tt.pdfID, tt.scope); err != tt.want {
+				t.Fatalf("Verify err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
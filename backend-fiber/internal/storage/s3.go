@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"pdf-backend-fiber/internal/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage menyimpan file di object storage S3-compatible (AWS S3, MinIO, dll).
+type S3Storage struct {
+	Client *minio.Client
+	Bucket string
+	Prefix string //opsional, misal "pdf/" biar bucket bisa dipakai bareng
+}
+
+// NewS3 membuat client dari config dan memastikan bucket-nya ada.
+func NewS3(cfg config.Config) (*S3Storage, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &S3Storage{Client: client, Bucket: cfg.S3Bucket, Prefix: cfg.S3Prefix}
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, s.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket %s: %w", s.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, s.Bucket, minio.MakeBucketOptions{Region: cfg.S3Region}); err != nil {
+			return nil, fmt.Errorf("create bucket %s: %w", s.Bucket, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *S3Storage) Name() string {
+	return "s3"
+}
+
+func (s *S3Storage) objectName(key string) string {
+	return s.Prefix + key
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(ctx, s.Bucket, s.objectName(key), r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Open mengembalikan *minio.Object (bisa Seek, jadi Range request tetap jalan).
+// Stat dipanggil dulu karena GetObject baru error saat dibaca.
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	obj, err := s.Client.GetObject(ctx, s.Bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	info, err := s.Client.StatObject(ctx, s.Bucket, s.objectName(key), minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Key: key, Size: info.Size, ModTime: info.LastModified, ETag: strings.Trim(info.ETag, `"`)}, nil
+}
+
+// Delete: S3 tidak error kalau key tidak ada, jadi dicek dulu biar perilakunya sama dengan LocalStorage.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if _, err := s.Stat(ctx, key); err != nil {
+		return err
+	}
+	return s.Client.RemoveObject(ctx, s.Bucket, s.objectName(key), minio.RemoveObjectOptions{})
+}
+
+//storage S3/MinIO, dipakai kalau backend dijalankan lebih dari satu replica
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+)
+
+// ErrNotFound dikembalikan semua backend kalau key tidak ada.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ObjectInfo = info singkat satu file yang tersimpan.
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+	ETag    string
+}
+
+// Storage = tempat menyimpan file PDF. pdf_files.filepath berisi key di storage ini, bukan path lokal,
+// jadi beberapa replica backend bisa pakai storage yang sama (S3/MinIO).
+type Storage interface {
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// localPather = storage yang file-nya memang sudah ada di disk lokal (LocalStorage), jadi tidak perlu dicopy.
+type localPather interface {
+	LocalPath(key string) (string, error)
+}
+
+// New memilih backend sesuai STORAGE_BACKEND (local / s3).
+func New(cfg config.Config) (Storage, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.StorageBackend)) {
+	case "", "local":
+		return NewLocal(cfg.UploadDir), nil
+	case "s3", "minio":
+		return NewS3(cfg)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q (available: local, s3)", cfg.StorageBackend)
+}
+
+// LocalCopy menyediakan file di disk lokal untuk key ini (parser PDF & summarizer butuh path file).
+// Storage lokal langsung pakai path aslinya; storage lain didownload ke file sementara yang dihapus lewat cleanup.
+func LocalCopy(ctx context.Context, s Storage, key string) (string, func(), error) {
+	if lp, ok := s.(localPather); ok {
+		path, err := lp.LocalPath(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return "", nil, ErrNotFound
+			}
+			return "", nil, err
+		}
+		return path, func() {}, nil
+	}
+
+	src, err := s.Open(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp("", "pdf-*.pdf")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+//abstraksi penyimpanan file pdf, biar ga terikat ke folder uploads di satu server
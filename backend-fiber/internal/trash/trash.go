@@ -0,0 +1,135 @@
+package trash
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"pdf-backend-fiber/internal/config"
+	"pdf-backend-fiber/internal/models"
+	"pdf-backend-fiber/internal/storage"
+)
+
+// Purger menghapus permanen PDF yang sudah di trash lebih lama dari Retention (baris DB, ringkasan, file di storage).
+type Purger struct {
+	DB        *sql.DB
+	Storage   storage.Storage
+	Retention time.Duration
+	Interval  time.Duration
+
+	mu sync.Mutex //satu purge dalam satu waktu
+}
+
+func New(db *sql.DB, cfg config.Config, store storage.Storage) *Purger {
+	return &Purger{
+		DB:        db,
+		Storage:   store,
+		Retention: cfg.TrashRetention,
+		Interval:  cfg.TrashPurgeInterval,
+	}
+}
+
+// Start menjalankan purge sekali di awal lalu berkala setiap Interval.
+func (p *Purger) Start() {
+	go func() {
+		for {
+			if n, err := p.PurgeExpired(context.Background()); err != nil {
+				log.Printf("trash: purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("trash: %d PDF dihapus permanen (lebih dari %s di trash)", n, p.Retention)
+			}
+			time.Sleep(p.Interval)
+		}
+	}()
+}
+
+// PurgeExpired menghapus semua PDF yang deleted_at-nya sudah lewat masa retensi.
+func (p *Purger) PurgeExpired(ctx context.Context) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT id FROM pdf_files WHERE deleted_at IS NOT NULL AND deleted_at < $1 ORDER BY deleted_at
+	`, time.Now().Add(-p.Retention))
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	purged := 0
+	for _, id := range ids {
+		snapshot, err := Purge(ctx, p.DB, p.Storage, id)
+		if err != nil {
+			log.Printf("trash: failed to purge PDF %d: %v", id, err)
+			continue
+		}
+		p.recordSystemAudit(id, snapshot)
+		purged++
+	}
+	return purged, nil
+}
+
+// Snapshot = data PDF yang dihapus permanen, untuk audit log.
+type Snapshot struct {
+	OriginalFilename string    `json:"original_filename"`
+	Filesize         int64     `json:"filesize"`
+	ContentSHA256    string    `json:"content_sha256"`
+	StorageKey       string    `json:"storage_key"`
+	WorkspaceID      int       `json:"workspace_id"`
+	DeletedAt        time.Time `json:"deleted_at"`
+}
+
+// Purge menghapus permanen satu PDF yang sudah di trash. Summaries, share link, job ikut terhapus (ON DELETE CASCADE).
+// File di storage cuma dihapus kalau tidak dipakai baris pdf_files lain. sql.ErrNoRows = PDF tidak ada di trash.
+func Purge(ctx context.Context, db *sql.DB, store storage.Storage, pdfID int) (Snapshot, error) {
+	var s Snapshot
+	var contentHash sql.NullString
+	var workspaceID sql.NullInt64
+	err := db.QueryRowContext(ctx, `
+		DELETE FROM pdf_files WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING COALESCE(original_filename, filename), filesize, content_sha256, filepath, workspace_id, deleted_at
+	`, pdfID).Scan(&s.OriginalFilename, &s.Filesize, &contentHash, &s.StorageKey, &workspaceID, &s.DeletedAt)
+	if err != nil {
+		return s, err
+	}
+	s.ContentSHA256 = contentHash.String
+	s.WorkspaceID = int(workspaceID.Int64)
+
+	var stillUsed bool
+	_ = db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pdf_files WHERE filepath = $1)`, s.StorageKey).Scan(&stillUsed)
+	if !stillUsed {
+		if err := store.Delete(ctx, s.StorageKey); err != nil && err != storage.ErrNotFound {
+			log.Printf("trash: failed to delete file %s: %v", s.StorageKey, err)
+		}
+	}
+	return s, nil
+}
+
+// recordSystemAudit = event purge otomatis (tanpa actor user).
+func (p *Purger) recordSystemAudit(pdfID int, s Snapshot) {
+	before, _ := json.Marshal(s)
+	var workspaceID interface{}
+	if s.WorkspaceID > 0 {
+		workspaceID = s.WorkspaceID
+	}
+	_, err := p.DB.Exec(`
+		INSERT INTO audit_events (auth_method, action, target_type, target_id, workspace_id, before)
+		VALUES ('system', $1, $2, $3, $4, $5)
+	`, models.AuditPDFPurge, models.TargetPDF, strconv.Itoa(pdfID), workspaceID, string(before))
+	if err != nil {
+		log.Printf("trash: failed to write audit event for PDF %d: %v", pdfID, err)
+	}
+}
+
+//for learn, soft delete: DELETE /pdf/:id cuma isi deleted_at, yang benar-benar hapus baris + file itu purger ini
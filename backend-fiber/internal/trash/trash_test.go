@@ -0,0 +1,68 @@
+package trash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pdf-backend-fiber/internal/database/dbtest"
+	"pdf-backend-fiber/internal/storage"
+)
+
+func TestPurgeExpired(t *testing.T) {
+	db := dbtest.Open(t)
+	userID, workspaceID := dbtest.User(t, db, "a@b.c")
+	dir := t.TempDir()
+	p := &Purger{DB: db, Storage: storage.NewLocal(dir), Retention: 24 * time.Hour}
+
+	tests := []struct {
+		name       string
+		deletedAgo time.Duration //0 = tidak di trash
+		wantPurged bool
+	}{
+		{"aktif", 0, false},
+		{"baru dihapus", time.Hour, false},
+		{"hampir lewat retensi", 23 * time.Hour, false},
+		{"lewat retensi", 25 * time.Hour, true},
+		{"sudah lama", 30 * 24 * time.Hour, true},
+	}
+	ids := make([]int, len(tests))
+	for i, tt := range tests {
+		name := tt.name + ".pdf"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("%PDF-1.4\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = dbtest.PDF(t, db, workspaceID, userID, name)
+		if tt.deletedAgo > 0 {
+			if _, err := db.Exec(`UPDATE pdf_files SET deleted_at = $1 WHERE id = $2`, time.Now().Add(-tt.deletedAgo).UTC(), ids[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	n, err := p.PurgeExpired(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("PurgeExpired = %d, %v, want 2", n, err)
+	}
+	for i, tt := range tests {
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pdf_files WHERE id = $1)`, ids[i]).Scan(&exists); err != nil {
+			t.Fatal(err)
+		}
+		_, statErr := os.Stat(filepath.Join(dir, tt.name+".pdf"))
+		if exists == tt.wantPurged || os.IsNotExist(statErr) != tt.wantPurged {
+			t.Errorf("%s: row exists=%v file err=%v, want purged=%v", tt.name, exists, statErr, tt.wantPurged)
+		}
+	}
+
+	// purge otomatis dicatat sebagai event system
+	var events int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE auth_method = 'system' AND action = 'pdf.purge'`).Scan(&events); err != nil {
+		t.Fatal(err)
+	}
+	if events != 2 {
+		t.Errorf("system purge events = %d, want 2", events)
+	}
+}
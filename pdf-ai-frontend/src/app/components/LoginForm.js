@@ -0,0 +1,63 @@
+"use client";
+
+import { useState } from "react";
+import { login } from "./auth";
+
+export default function LoginForm({ onLogin }) {
+  const [mode, setMode] = useState("login");
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [name, setName] = useState("");
+  const [error, setError] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError("");
+    setLoading(true);
+    try {
+      const user = await login(mode, { email, password, name });
+      onLogin(user);
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const inputStyle = {
+    width: "100%",
+    padding: "0.75rem",
+    marginBottom: "0.75rem",
+    borderRadius: "8px",
+    border: "1px solid rgba(96, 165, 250, 0.3)",
+    background: "rgba(15, 23, 42, 0.6)",
+    color: "#e2e8f0",
+  };
+
+  return (
+    <div style={{ display: "flex", minHeight: "100vh", alignItems: "center", justifyContent: "center", background: "linear-gradient(135deg, #0a1929 0%, #1a2332 100%)" }}>
+      <form onSubmit={handleSubmit} style={{ width: "100%", maxWidth: 360, padding: "2rem", borderRadius: 12, background: "rgba(30, 41, 59, 0.9)", color: "#e2e8f0" }}>
+        <h1 style={{ marginBottom: "0.25rem" }}>PDF ARAI</h1>
+        <p style={{ marginBottom: "1.5rem", opacity: 0.7 }}>{mode === "login" ? "Masuk ke akun kamu" : "Buat akun baru"}</p>
+
+        {mode === "register" && (
+          <input style={inputStyle} placeholder="Nama" value={name} onChange={(e) => setName(e.target.value)} />
+        )}
+        <input style={inputStyle} type="email" placeholder="Email" value={email} onChange={(e) => setEmail(e.target.value)} required />
+        <input style={inputStyle} type="password" placeholder="Password (min. 8 karakter)" value={password} onChange={(e) => setPassword(e.target.value)} required />
+
+        {error && <p style={{ color: "#f87171", marginBottom: "0.75rem" }}>{error}</p>}
+
+        <button type="submit" disabled={loading} style={{ width: "100%", padding: "0.75rem", borderRadius: 8, border: "none", background: "#3b82f6", color: "white", cursor: "pointer" }}>
+          {loading ? "Memproses..." : mode === "login" ? "Login" : "Register"}
+        </button>
+        <p style={{ marginTop: "1rem", textAlign: "center", fontSize: "0.9rem" }}>
+          <a href="#" onClick={(e) => { e.preventDefault(); setMode(mode === "login" ? "register" : "login"); setError(""); }} style={{ color: "#60a5fa" }}>
+            {mode === "login" ? "Belum punya akun? Register" : "Sudah punya akun? Login"}
+          </a>
+        </p>
+      </form>
+    </div>
+  );
+}
@@ -1,6 +1,7 @@
 "use client";
 import { useState, useEffect, useMemo } from "react";
 import styles from "./PdfManager.module.css";
+import { goFetch, withAccessToken } from "./auth";
 
 const GO_API_BASE_URL = process.env.NEXT_PUBLIC_GO_API_BASE_URL || "http://localhost:8080"; //ambil url klo env gd pake 8080
 
@@ -23,7 +24,7 @@ export default function PdfManager() { //state, fungsi, logika data
   const fetchPdfList = async () => { //ambil daftar pdf ke be
     setLoading(true);
     try {
-      const response = await fetch(`${GO_API_BASE_URL}/simple-pdfs`); 
+      const response = await goFetch(`${GO_API_BASE_URL}/simple-pdfs`); 
       if (!response.ok) throw new Error("Failed to fetch PDF list");
       const data = await response.json();
       setPdfList(data.pdfs || []);
@@ -37,11 +38,11 @@ export default function PdfManager() { //state, fungsi, logika data
 
   const handleViewDetails = async (pdfId) => {
     try {
-      const response = await fetch(`${GO_API_BASE_URL}/simple-pdf/${pdfId}`);
+      const response = await goFetch(`${GO_API_BASE_URL}/simple-pdf/${pdfId}`);
       if (!response.ok) throw new Error("Failed to fetch PDF details");
       const data = await response.json();
 
-      const summariesResponse = await fetch(`${GO_API_BASE_URL}/summaries/${pdfId}`);
+      const summariesResponse = await goFetch(`${GO_API_BASE_URL}/summaries/${pdfId}`);
       if (summariesResponse.ok) {
         const summariesData = await summariesResponse.json();
         data.summaries = summariesData.summaries || [];
@@ -58,7 +59,7 @@ export default function PdfManager() { //state, fungsi, logika data
 
   const handleUpdate = async (pdfId) => {
     try {
-      const response = await fetch(`${GO_API_BASE_URL}/update-pdf/${pdfId}`, {
+      const response = await goFetch(`${GO_API_BASE_URL}/update-pdf/${pdfId}`, {
         method: "PUT",
         headers: {
           "Content-Type": "application/json",
@@ -81,7 +82,7 @@ export default function PdfManager() { //state, fungsi, logika data
 
   const handleResummarize = async (pdfId, style) => {
     try {
-      const response = await fetch(`${GO_API_BASE_URL}/resummarize/${pdfId}`, {
+      const response = await goFetch(`${GO_API_BASE_URL}/resummarize/${pdfId}`, {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
@@ -94,6 +95,20 @@ export default function PdfManager() { //state, fungsi, logika data
         throw new Error(errorText || "Re-summarize failed");
       }
 
+      // ringkasan dibuat worker di background, tunggu job-nya selesai baru refresh daftar
+      const { job_id } = await response.json();
+      const deadline = Date.now() + 10 * 60 * 1000;
+      while (Date.now() < deadline) {
+        const res = await goFetch(`${GO_API_BASE_URL}/jobs/${job_id}`);
+        if (!res.ok) throw new Error("Failed to check re-summarize status");
+        const data = await res.json();
+        if (data.job?.status === "failed") {
+          throw new Error(data.job.error || "Re-summarize failed");
+        }
+        if (data.job?.status === "succeeded") break;
+        await new Promise((resolve) => setTimeout(resolve, 2000));
+      }
+
       await fetchPdfList();
     } catch (err) {
       setError(err.message);
@@ -101,10 +116,10 @@ export default function PdfManager() { //state, fungsi, logika data
   };
 
   const handleDelete = async (pdfId) => {
-    if (!confirm("Are you sure you want to delete this PDF?")) return;
+    if (!confirm("Move this PDF to trash? It can be restored until it is purged.")) return;
 
     try {
-      const response = await fetch(`${GO_API_BASE_URL}/pdf/${pdfId}`, {
+      const response = await goFetch(`${GO_API_BASE_URL}/pdf/${pdfId}`, {
         method: "DELETE",
       });
 
@@ -511,7 +526,14 @@ export default function PdfManager() { //state, fungsi, logika data
                   <p><strong>Original Name:</strong> {pdfDetails.original_filename}</p>
                   <p><strong>File Size:</strong> {formatFileSize(pdfDetails.filesize)}</p>
                   <p><strong>Upload Time:</strong> {formatDate(pdfDetails.upload_time)}</p>
-                  <p><strong>File Path:</strong> {pdfDetails.filepath}</p>
+                  {pdfDetails.file_url && (
+                    <p>
+                      <strong>File:</strong>{' '}
+                      <a href={withAccessToken(`${GO_API_BASE_URL}${pdfDetails.file_url}`)} target="_blank" rel="noreferrer">Buka PDF</a>
+                      {' | '}
+                      <a href={withAccessToken(`${GO_API_BASE_URL}${pdfDetails.file_url}?download=true`)}>Download</a>
+                    </p>
+                  )}
                 </div>
               </div>
 
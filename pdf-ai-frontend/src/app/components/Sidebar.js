@@ -3,7 +3,7 @@
 import { useState } from "react";
 import styles from "./sidebar.module.css";
 
-export default function Sidebar({ activeTab, setActiveTab }) {
+export default function Sidebar({ activeTab, setActiveTab, onLogout }) {
   const [isOpen, setIsOpen] = useState(false);
 
   const handleNavClick = (tab) => {
@@ -60,6 +60,15 @@ export default function Sidebar({ activeTab, setActiveTab }) {
             </span>
             <span className={styles.label}>Manager</span>
           </button>
+
+          {onLogout && (
+            <button onClick={onLogout} className={styles.navItem}>
+              <span className={styles.icon} aria-hidden="true">
+                🚪
+              </span>
+              <span className={styles.label}>Logout</span>
+            </button>
+          )}
           <div className={styles.infoSection}>
             <h3 className={styles.infoTitle}>Info ARAI</h3>
             <div className={styles.infoContent}>
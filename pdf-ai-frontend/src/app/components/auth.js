@@ -0,0 +1,53 @@
+"use client";
+
+const GO_API_BASE_URL = process.env.NEXT_PUBLIC_GO_API_BASE_URL || "http://localhost:8080"; // Go backend
+const TOKEN_KEY = "arai_auth_token";
+
+export const getToken = () => {
+  if (typeof window === "undefined") return "";
+  return window.localStorage.getItem(TOKEN_KEY) || "";
+};
+
+export const setToken = (token) => {
+  window.localStorage.setItem(TOKEN_KEY, token);
+};
+
+export const clearToken = () => {
+  window.localStorage.removeItem(TOKEN_KEY);
+  window.dispatchEvent(new Event("arai:logout")); // page.js balik ke form login
+};
+
+// tambah ?access_token= untuk URL yang dibuka browser langsung (EventSource, link file PDF)
+export const withAccessToken = (url) => {
+  const token = getToken();
+  if (!token) return url;
+  const sep = url.includes("?") ? "&" : "?";
+  return `${url}${sep}access_token=${encodeURIComponent(token)}`;
+};
+
+// fetch ke backend Go + header Authorization. Request ke Python service tidak dikasih token.
+export const goFetch = async (url, options = {}) => {
+  const token = getToken();
+  if (!token || !String(url).startsWith(GO_API_BASE_URL)) {
+    return fetch(url, options);
+  }
+  const headers = new Headers(options.headers || {});
+  headers.set("Authorization", `Bearer ${token}`);
+  const res = await fetch(url, { ...options, headers });
+  if (res.status === 401) clearToken(); // token kedaluwarsa, login ulang
+  return res;
+};
+
+export const login = async (mode, { email, password, name }) => {
+  const res = await fetch(`${GO_API_BASE_URL}/auth/${mode === "register" ? "register" : "login"}`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ email, password, name }),
+  });
+  const data = await res.json().catch(() => ({}));
+  if (!res.ok) throw new Error(data.error || "Login gagal");
+  setToken(data.token);
+  return data.user;
+};
+
+//helper token login, semua request ke backend Go lewat goFetch
@@ -1,6 +1,7 @@
 "use client";
 import { useState, useEffect } from "react";
 import styles from "./pdfupload.module.css";
+import { goFetch, withAccessToken } from "./auth";
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_PY_API_BASE_URL || "http://localhost:8000"; // Python AI service
 const GO_API_BASE_URL = process.env.NEXT_PUBLIC_GO_API_BASE_URL || "http://localhost:8080"; // Go backend
@@ -47,7 +48,7 @@ export default function PdfUploader() {
     const controller = new AbortController();
     const timeout = setTimeout(() => controller.abort(), timeoutMs);
     try {
-      const res = await fetch(url, { ...options, signal: controller.signal });
+      const res = await goFetch(url, { ...options, signal: controller.signal });
       return res;
     } finally {
       clearTimeout(timeout);
@@ -92,6 +93,83 @@ export default function PdfUploader() {
     throw lastErr;
   };
 
+  const JOB_STAGE_TEXT = {
+    chunks_assembled: "File berhasil digabung di server...",
+    pdf_validated: "PDF valid, menunggu antrian ringkasan...",
+    sent_to_summarizer: "Dokumen sedang diringkas oleh AI...",
+    sent_to_python: "Dokumen sedang diringkas oleh AI...", // event job lama
+    chunk_summarized: "Dokumen panjang, meringkas per bagian...",
+    summary_stored: "Ringkasan disimpan...",
+  };
+
+  // fallback kalau EventSource tidak tersedia / stream putus: polling GET /jobs/:id
+  const pollJob = async (jobId, timeoutMs, { intervalMs = 2000, maxWaitMs = 10 * 60 * 1000 } = {}) => {
+    const deadline = Date.now() + maxWaitMs;
+    while (Date.now() < deadline) {
+      const res = await fetchWithTimeout(`${GO_API_BASE_URL}/jobs/${jobId}`, {}, timeoutMs);
+      const errMsg = await fetchJsonOrTextError(res, "Gagal cek status ringkasan");
+      if (errMsg) throw new Error(errMsg);
+      const data = await res.json();
+      if (data.job?.stage && JOB_STAGE_TEXT[data.job.stage]) {
+        setUploadStatusText(JOB_STAGE_TEXT[data.job.stage]);
+      }
+      if (data.job?.status === "succeeded" || data.job?.status === "failed") return data;
+      await sleep(intervalMs);
+    }
+    throw new Error("Ringkasan belum selesai. Cek lagi nanti di daftar PDF.");
+  };
+
+  // dengarkan progress job lewat SSE (/jobs/:id/events) sampai event "done"
+  const waitForJob = (jobId, timeoutMs) => {
+    if (typeof window === "undefined" || typeof window.EventSource === "undefined") {
+      return pollJob(jobId, timeoutMs);
+    }
+    return new Promise((resolve, reject) => {
+      const source = new EventSource(withAccessToken(`${GO_API_BASE_URL}/jobs/${jobId}/events`));
+      let settled = false;
+      const finish = (fn) => {
+        if (settled) return;
+        settled = true;
+        source.close();
+        fn();
+      };
+
+      source.addEventListener("stage", (e) => {
+        try {
+          const event = JSON.parse(e.data);
+          if (event.stage === "chunk_summarized" && event.message) {
+            setUploadStatusText(`Dokumen panjang, meringkas bagian ${event.message}...`);
+          } else if (JOB_STAGE_TEXT[event.stage]) {
+            setUploadStatusText(JOB_STAGE_TEXT[event.stage]);
+          }
+        } catch {
+        }
+      });
+      source.addEventListener("done", (e) => {
+        finish(() => {
+          try {
+            resolve(JSON.parse(e.data));
+          } catch (err) {
+            reject(err);
+          }
+        });
+      });
+      source.onerror = () => {
+        // stream putus (server restart / proxy), lanjut pakai polling
+        finish(() => pollJob(jobId, timeoutMs).then(resolve, reject));
+      };
+    });
+  };
+
+  // SHA-256 (hex) buat checksum chunk & file; null kalau browser tidak punya crypto.subtle (non-HTTPS)
+  const sha256Hex = async (blob) => {
+    if (typeof crypto === "undefined" || !crypto.subtle) return null;
+    const digest = await crypto.subtle.digest("SHA-256", await blob.arrayBuffer());
+    return Array.from(new Uint8Array(digest))
+      .map((b) => b.toString(16).padStart(2, "0"))
+      .join("");
+  };
+
   const isPdfByMagicBytes = async (selectedFile) => {
     try {
       const headerBuffer = await selectedFile.slice(0, 5).arrayBuffer();
@@ -278,6 +356,8 @@ Jalankan: start-all.bat untuk memulai semua service`;
       const REQ_TIMEOUT_MS = 30000;
 
       const fileKey = `${getFileKey(file)}::${summaryStyle}`;
+      setUploadStatusText("Menghitung checksum file...");
+      const fileSha256 = await sha256Hex(file);
       const storageKey = `pdf_upload_session::${fileKey}`;
 
       let session = null;
@@ -298,6 +378,7 @@ Jalankan: start-all.bat untuk memulai semua service`;
             chunk_size: CHUNK_SIZE,
             total_chunks: Math.ceil(file.size / CHUNK_SIZE),
             style: summaryStyle,
+            ...(fileSha256 ? { file_sha256: fileSha256 } : {}),
           }),
         }, REQ_TIMEOUT_MS);
 
@@ -331,6 +412,7 @@ Jalankan: start-all.bat untuk memulai semua service`;
             chunk_size: CHUNK_SIZE,
             total_chunks: Math.ceil(file.size / CHUNK_SIZE),
             style: summaryStyle,
+            ...(fileSha256 ? { file_sha256: fileSha256 } : {}),
           }),
         }, REQ_TIMEOUT_MS);
         const initErr = await fetchJsonOrTextError(initRes, "Gagal init upload");
@@ -350,7 +432,9 @@ Jalankan: start-all.bat untuk memulai semua service`;
       if (statusErr) throw new Error(statusErr);
       const statusData = await statusRes.json();
 
-      const received = new Set((statusData.received || []).map((n) => Number(n)));
+      // kalau pakai checksum, hanya chunk yang lolos verifikasi yang dilewati; sisanya (rusak / belum dicek) dikirim ulang
+      const doneChunks = fileSha256 && Array.isArray(statusData.verified) ? statusData.verified : statusData.received;
+      const received = new Set((doneChunks || []).map((n) => Number(n)));
       const totalChunks = Number(statusData.total_chunks || session.total_chunks || Math.ceil(file.size / CHUNK_SIZE));
       const chunkSize = Number(statusData.chunk_size || session.chunk_size || CHUNK_SIZE);
 
@@ -366,6 +450,8 @@ Jalankan: start-all.bat untuk memulai semua service`;
         const end = Math.min(file.size, start + chunkSize);
         const chunkBlob = file.slice(start, end);
 
+        const chunkSha256 = await sha256Hex(chunkBlob);
+
         await retry(async () => {
           const formData = new FormData();
           formData.append("upload_id", session.upload_id);
@@ -374,6 +460,7 @@ Jalankan: start-all.bat untuk memulai semua service`;
 
           const chunkRes = await fetchWithTimeout(`${GO_API_BASE_URL}/upload/chunk`, {
             method: "POST",
+            headers: chunkSha256 ? { "X-Chunk-SHA256": chunkSha256 } : {},
             body: formData,
           }, REQ_TIMEOUT_MS);
 
@@ -396,14 +483,29 @@ Jalankan: start-all.bat untuk memulai semua service`;
       if (completeErr) throw new Error(completeErr);
 
       const data = await completeRes.json();
-      setSummary(data.summary || "");
-
-      setUploadStatusText("Selesai.");
 
+      // upload selesai, chunk session tidak dibutuhkan lagi walaupun summary masih diproses
       try {
         localStorage.removeItem(storageKey);
       } catch {
       }
+
+      // isi file sudah pernah diupload: server tidak meringkas ulang, pakai ringkasan yang ada
+      if (data.duplicate) {
+        setSummary(data.summary || "");
+        setUploadStatusText("File ini sudah pernah diupload, menampilkan ringkasan yang ada.");
+        return;
+      }
+
+      // summary dikerjakan di background, ikuti progress job-nya
+      setUploadStatusText("Menunggu antrian ringkasan...");
+      const jobResult = await waitForJob(data.job_id, REQ_TIMEOUT_MS);
+      if (jobResult.job?.status === "failed" && !jobResult.summary) {
+        throw new Error(jobResult.job?.error || "Gagal membuat ringkasan");
+      }
+      setSummary(jobResult.summary || "");
+
+      setUploadStatusText("Selesai.");
     } catch (err) {
       // Handle specific PDF validation errors
       if (err.message.includes("File bukan PDF valid") || 
@@ -489,7 +591,7 @@ Jalankan: start-all.bat untuk memulai semua service`;
     if (!summary) return;
 
     try {
-      const res = await fetch(`${GO_API_BASE_URL}/export/csv`, {
+      const res = await goFetch(`${GO_API_BASE_URL}/export/csv`, {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
@@ -522,7 +624,7 @@ Jalankan: start-all.bat untuk memulai semua service`;
     if (!summary) return;
 
     try {
-      const res = await fetch(`${GO_API_BASE_URL}/export/json`, {
+      const res = await goFetch(`${GO_API_BASE_URL}/export/json`, {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
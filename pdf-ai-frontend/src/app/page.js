@@ -1,16 +1,30 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import PdfUpload from "./components/pdfupload";
 import PdfManager from "./components/PdfManager";
 import Sidebar from "./components/Sidebar";
+import LoginForm from "./components/LoginForm";
+import { getToken, clearToken } from "./components/auth";
 
 export default function Home() {
   const [activeTab, setActiveTab] = useState("uploader");
+  const [loggedIn, setLoggedIn] = useState(false);
+
+  useEffect(() => {
+    setLoggedIn(!!getToken()); // localStorage cuma ada di browser
+    const onLogout = () => setLoggedIn(false);
+    window.addEventListener("arai:logout", onLogout);
+    return () => window.removeEventListener("arai:logout", onLogout);
+  }, []);
+
+  if (!loggedIn) {
+    return <LoginForm onLogin={() => setLoggedIn(true)} />;
+  }
 
   return (
     <div style={{ display: "flex", minHeight: "100vh" }}>
-      <Sidebar activeTab={activeTab} setActiveTab={setActiveTab} />
+      <Sidebar activeTab={activeTab} setActiveTab={setActiveTab} onLogout={clearToken} />
       <main style={{ flex: 1, overflow: "auto" }}>
         {activeTab === "uploader" ? <PdfUpload /> : <PdfManager />}
       </main>
@@ -1,6 +1,7 @@
 import os
 import io
 import re
+from typing import Optional
 from html import escape
 from fastapi import FastAPI, UploadFile, File, HTTPException
 from fastapi.middleware.cors import CORSMiddleware   
@@ -100,9 +101,12 @@ def detect_language(text: str):
 # =========================
 # Get Prompt by Language and Style
 # =========================
-def get_summarize_prompt(text: str, language: str, style: str = "standard"):
+def get_summarize_prompt(text: str, language: str, style: str = "standard", max_chars: Optional[int] = 5000):
     """
     Buat prompt yang sesuai dengan bahasa dan gaya ringkasan yang dipilih
+
+    max_chars: batas panjang teks yang dimasukkan ke prompt (None = tanpa batas,
+    dipakai /summarize-text karena backend Go sudah memotong teks per chunk)
     
     Styles:
     - standard: Ringkasan paragraf normal
@@ -143,7 +147,7 @@ Instruksi format:
 PENTING: Gunakan format Markdown bold (**kata**) untuk menyorot (highlight) kata kunci, nama penting, atau poin utama agar pembaca lebih mudah menangkap inti sari.
 
 Dokumen:
-{text[:5000]}
+{text[:max_chars]}
 """
     else:
         prompt = f"""
@@ -155,7 +159,7 @@ Format instructions:
 IMPORTANT: Use Markdown bold (**word**) to highlight key terms, important names, or main points so the reader can easily grasp the essence.
 
 Document:
-{text[:5000]}
+{text[:max_chars]}
 """
     
     return prompt
@@ -163,8 +167,8 @@ Document:
 # =========================
 # Summarize Logic
 # =========================
-def summarize_with_gemini(text: str, language: str, style: str = "standard"):
-    prompt = get_summarize_prompt(text, language, style)
+def summarize_with_gemini(text: str, language: str, style: str = "standard", max_chars: Optional[int] = 5000):
+    prompt = get_summarize_prompt(text, language, style, max_chars)
 
     model = genai.GenerativeModel("gemini-2.5-flash")
     
@@ -245,6 +249,39 @@ async def summarize_pdf(file: UploadFile = File(...), style: str = "standard"):
     except Exception as e:
         raise HTTPException(status_code=500, detail=str(e))
 
+class SummarizeTextRequest(BaseModel):
+    text: str
+    style: str = "standard"
+    language: Optional[str] = None
+
+@app.post("/summarize-text")
+async def summarize_text(req: SummarizeTextRequest):
+    """
+    Ringkas teks yang sudah diekstrak (dipakai map-reduce di backend Go).
+    Teks tidak dipotong 5000 karakter karena backend Go sudah membagi dokumen per chunk.
+    """
+    if not req.text.strip():
+        raise HTTPException(status_code=400, detail="Teks kosong")
+
+    detected_language = req.language or detect_language(req.text)
+    style = req.style if req.style in ["standard", "executive", "bullets", "detailed"] else "standard"
+
+    try:
+        if AI_PROVIDER == "gemini":
+            summary = summarize_with_gemini(req.text, detected_language, style, max_chars=None)
+        else:
+            summary = summarize_mock(req.text, detected_language, style)
+
+        return {
+            "provider": AI_PROVIDER,
+            "detected_language": detected_language,
+            "style": style,
+            "summary": summary
+        }
+
+    except Exception as e:
+        raise HTTPException(status_code=500, detail=str(e))
+
 class ExportRequest(BaseModel):
     content: str
 